@@ -59,6 +59,9 @@ type Antbox interface {
 	ChatWithAgent(agentUUID string, message string, conversationID string, temperature *float64, maxTokens *int, history []map[string]any) (ChatHistory, error)
 	AnswerFromAgent(agentUUID string, query string, temperature *float64, maxTokens *int) (ChatHistory, error)
 	RagChat(message string, options map[string]any) (ChatHistory, error)
+	ChatWithAgentStream(agentUUID string, message string, conversationID string, temperature *float64, maxTokens *int, history []map[string]any, chatContext map[string]any, onPart ChatStreamHandler) (ChatHistory, error)
+	AnswerFromAgentStream(agentUUID string, query string, temperature *float64, maxTokens *int, chatContext map[string]any, onPart ChatStreamHandler) (ChatHistory, error)
+	RagChatStream(message string, options map[string]any, onPart ChatStreamHandler) (ChatHistory, error)
 
 	// API Key operations
 	ListAPIKeys() ([]APIKey, error)
@@ -0,0 +1,397 @@
+package antbox
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// CachedClient is an Antbox client that keeps the nodes and folder listings it fetches for a
+// while, so browsing doesn't ask the server again for what it has just seen. Changes made
+// through it invalidate the nodes and listings they affect. Features, agents and aspects are
+// stored as nodes, and running actions, extensions, AI tools or agents may change any node, so
+// those clear the whole cache.
+type CachedClient struct {
+	Antbox
+
+	mu       sync.Mutex
+	ttl      time.Duration
+	now      func() time.Time
+	nodes    map[string]CacheEntry[Node]
+	listings map[string]CacheEntry[[]Node]
+	hits     int
+	misses   int
+
+	// generation counts invalidations. A fetch made while it changed may have read what was
+	// just invalidated, so its result isn't stored.
+	generation uint64
+}
+
+// CacheEntry is a cached value and the time it expires
+type CacheEntry[T any] struct {
+	Value   T         `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+// CacheSnapshot is the content of a cache, for keeping it between runs
+type CacheSnapshot struct {
+	Nodes    map[string]CacheEntry[Node]   `json:"nodes"`
+	Listings map[string]CacheEntry[[]Node] `json:"listings"`
+}
+
+// CacheStats are the number of cached nodes and listings, and how often the cache was used
+type CacheStats struct {
+	Nodes    int `json:"nodes"`
+	Listings int `json:"listings"`
+	Hits     int `json:"hits"`
+	Misses   int `json:"misses"`
+}
+
+// NewCachedClient returns a client caching nodes and listings of client for ttl. A ttl of
+// zero disables the cache.
+func NewCachedClient(client Antbox, ttl time.Duration) *CachedClient {
+	return &CachedClient{
+		Antbox:   client,
+		ttl:      ttl,
+		now:      time.Now,
+		nodes:    make(map[string]CacheEntry[Node]),
+		listings: make(map[string]CacheEntry[[]Node]),
+	}
+}
+
+// SetTTL changes how long new entries are kept. A ttl of zero disables the cache and clears it.
+func (c *CachedClient) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+	if ttl <= 0 {
+		clear(c.nodes)
+		clear(c.listings)
+		c.generation++
+	}
+}
+
+// Invalidate clears the cache
+func (c *CachedClient) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.nodes)
+	clear(c.listings)
+	c.generation++
+}
+
+// Stats returns the size of the cache and its hits and misses
+func (c *CachedClient) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Nodes: len(c.nodes), Listings: len(c.listings), Hits: c.hits, Misses: c.misses}
+}
+
+// Snapshot returns the entries that haven't expired
+func (c *CachedClient) Snapshot() CacheSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	snapshot := CacheSnapshot{
+		Nodes:    maps.Clone(c.nodes),
+		Listings: maps.Clone(c.listings),
+	}
+	maps.DeleteFunc(snapshot.Nodes, func(_ string, e CacheEntry[Node]) bool { return !now.Before(e.Expires) })
+	maps.DeleteFunc(snapshot.Listings, func(_ string, e CacheEntry[[]Node]) bool { return !now.Before(e.Expires) })
+	return snapshot
+}
+
+// Restore adds the entries of a snapshot that haven't expired to the cache
+func (c *CachedClient) Restore(snapshot CacheSnapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ttl <= 0 {
+		return
+	}
+	now := c.now()
+	for uuid, entry := range snapshot.Nodes {
+		if now.Before(entry.Expires) {
+			c.nodes[uuid] = entry
+		}
+	}
+	for parent, entry := range snapshot.Listings {
+		if now.Before(entry.Expires) {
+			c.listings[parent] = entry
+		}
+	}
+}
+
+func (c *CachedClient) GetNode(uuid string) (*Node, error) {
+	c.mu.Lock()
+	if entry, ok := c.nodes[uuid]; ok && c.now().Before(entry.Expires) {
+		c.hits++
+		c.mu.Unlock()
+		node := entry.Value
+		return &node, nil
+	}
+	c.misses++
+	generation := c.generation
+	c.mu.Unlock()
+
+	node, err := c.Antbox.GetNode(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.storeNode(*node)
+	}
+	return node, nil
+}
+
+func (c *CachedClient) ListNodes(parent string) ([]Node, error) {
+	c.mu.Lock()
+	if entry, ok := c.listings[parent]; ok && c.now().Before(entry.Expires) {
+		c.hits++
+		c.mu.Unlock()
+		return slices.Clone(entry.Value), nil
+	}
+	c.misses++
+	generation := c.generation
+	c.mu.Unlock()
+
+	nodes, err := c.Antbox.ListNodes(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.storeListing(parent, nodes)
+	}
+	return nodes, nil
+}
+
+// Prefetch fetches and caches the listing of a folder unless it's already cached. It isn't
+// counted in the stats, as nobody asked for the listing yet.
+func (c *CachedClient) Prefetch(parent string) error {
+	c.mu.Lock()
+	entry, ok := c.listings[parent]
+	cached := c.ttl <= 0 || (ok && c.now().Before(entry.Expires))
+	generation := c.generation
+	c.mu.Unlock()
+	if cached {
+		return nil
+	}
+
+	nodes, err := c.Antbox.ListNodes(parent)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.storeListing(parent, nodes)
+	}
+	return nil
+}
+
+// CachedListing returns the listing of a folder if it's cached, without fetching it
+func (c *CachedClient) CachedListing(parent string) ([]Node, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.listings[parent]
+	if !ok || !c.now().Before(entry.Expires) {
+		return nil, false
+	}
+	return slices.Clone(entry.Value), true
+}
+
+// storeListing caches the listing of a folder. The nodes listed are cached too, so going
+// into one of them doesn't fetch it again. The caller holds c.mu.
+func (c *CachedClient) storeListing(parent string, nodes []Node) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.listings[parent] = CacheEntry[[]Node]{Value: slices.Clone(nodes), Expires: c.now().Add(c.ttl)}
+	for _, node := range nodes {
+		c.storeNode(node)
+	}
+}
+
+// storeNode caches a node. The caller holds c.mu.
+func (c *CachedClient) storeNode(node Node) {
+	if c.ttl > 0 {
+		c.nodes[node.UUID] = CacheEntry[Node]{Value: node, Expires: c.now().Add(c.ttl)}
+	}
+}
+
+// forgetListings drops the listings of the given folders
+func (c *CachedClient) forgetListings(parents ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, parent := range parents {
+		delete(c.listings, parent)
+	}
+	c.generation++
+}
+
+// forgetNode drops a node, its own listing and the listings it appears in
+func (c *CachedClient) forgetNode(uuid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	if entry, ok := c.nodes[uuid]; ok {
+		delete(c.listings, entry.Value.Parent)
+	}
+	delete(c.nodes, uuid)
+	delete(c.listings, uuid)
+	maps.DeleteFunc(c.listings, func(_ string, entry CacheEntry[[]Node]) bool {
+		return slices.ContainsFunc(entry.Value, func(n Node) bool { return n.UUID == uuid })
+	})
+}
+
+func (c *CachedClient) CreateFolder(parent, name string) (*Node, error) {
+	defer c.forgetListings(parent)
+	return c.Antbox.CreateFolder(parent, name)
+}
+
+func (c *CachedClient) CreateSmartFolder(parent, name string, filters NodeFilters) (*Node, error) {
+	defer c.forgetListings(parent)
+	return c.Antbox.CreateSmartFolder(parent, name, filters)
+}
+
+func (c *CachedClient) RemoveNode(uuid string) error {
+	defer c.forgetNode(uuid)
+	return c.Antbox.RemoveNode(uuid)
+}
+
+func (c *CachedClient) MoveNode(uuid, newParent string) error {
+	defer c.forgetListings(newParent)
+	defer c.forgetNode(uuid)
+	return c.Antbox.MoveNode(uuid, newParent)
+}
+
+func (c *CachedClient) ChangeNodeName(uuid, newName string) error {
+	defer c.forgetNode(uuid)
+	return c.Antbox.ChangeNodeName(uuid, newName)
+}
+
+func (c *CachedClient) CreateFile(filePath string, metadata NodeCreate) (*Node, error) {
+	defer c.forgetListings(metadata.Parent)
+	return c.Antbox.CreateFile(filePath, metadata)
+}
+
+func (c *CachedClient) UpdateFile(uuid, filePath string) (*Node, error) {
+	defer c.forgetNode(uuid)
+	return c.Antbox.UpdateFile(uuid, filePath)
+}
+
+func (c *CachedClient) CreateNode(node NodeCreate) (*Node, error) {
+	defer c.forgetListings(node.Parent)
+	return c.Antbox.CreateNode(node)
+}
+
+func (c *CachedClient) UpdateNode(uuid string, metadata NodeUpdate) (*Node, error) {
+	defer c.forgetListings(metadata.Parent)
+	defer c.forgetNode(uuid)
+	return c.Antbox.UpdateNode(uuid, metadata)
+}
+
+func (c *CachedClient) CopyNode(uuid, parent, title string) (*Node, error) {
+	defer c.forgetListings(parent)
+	return c.Antbox.CopyNode(uuid, parent, title)
+}
+
+func (c *CachedClient) DuplicateNode(uuid string) (*Node, error) {
+	node, err := c.Antbox.DuplicateNode(uuid)
+	if err == nil {
+		c.forgetListings(node.Parent)
+	}
+	return node, err
+}
+
+func (c *CachedClient) RunFeatureAsAction(uuid string, uuids []string) (map[string]any, error) {
+	defer c.Invalidate()
+	return c.Antbox.RunFeatureAsAction(uuid, uuids)
+}
+
+func (c *CachedClient) RunFeatureAsExtension(uuid string, params map[string]any) (string, error) {
+	defer c.Invalidate()
+	return c.Antbox.RunFeatureAsExtension(uuid, params)
+}
+
+func (c *CachedClient) RunAction(uuid string, request ActionRunRequest) (map[string]any, error) {
+	defer c.Invalidate()
+	return c.Antbox.RunAction(uuid, request)
+}
+
+func (c *CachedClient) RunExtension(uuid string, data map[string]any) (any, error) {
+	defer c.Invalidate()
+	return c.Antbox.RunExtension(uuid, data)
+}
+
+func (c *CachedClient) RunAITool(uuid string, params map[string]any) (map[string]any, error) {
+	defer c.Invalidate()
+	return c.Antbox.RunAITool(uuid, params)
+}
+
+func (c *CachedClient) UploadFeature(filePath string) (*Feature, error) {
+	defer c.Invalidate()
+	return c.Antbox.UploadFeature(filePath)
+}
+
+func (c *CachedClient) DeleteFeature(uuid string) error {
+	defer c.Invalidate()
+	return c.Antbox.DeleteFeature(uuid)
+}
+
+func (c *CachedClient) UploadAgent(filePath string) (*Agent, error) {
+	defer c.Invalidate()
+	return c.Antbox.UploadAgent(filePath)
+}
+
+func (c *CachedClient) DeleteAgent(uuid string) error {
+	defer c.Invalidate()
+	return c.Antbox.DeleteAgent(uuid)
+}
+
+func (c *CachedClient) UploadAspect(filePath string) (*Aspect, error) {
+	defer c.Invalidate()
+	return c.Antbox.UploadAspect(filePath)
+}
+
+func (c *CachedClient) DeleteAspect(uuid string) error {
+	defer c.Invalidate()
+	return c.Antbox.DeleteAspect(uuid)
+}
+
+func (c *CachedClient) ChatWithAgent(agentUUID string, message string, conversationID string, temperature *float64, maxTokens *int, history []map[string]any) (ChatHistory, error) {
+	defer c.Invalidate()
+	return c.Antbox.ChatWithAgent(agentUUID, message, conversationID, temperature, maxTokens, history)
+}
+
+func (c *CachedClient) AnswerFromAgent(agentUUID string, query string, temperature *float64, maxTokens *int) (ChatHistory, error) {
+	defer c.Invalidate()
+	return c.Antbox.AnswerFromAgent(agentUUID, query, temperature, maxTokens)
+}
+
+func (c *CachedClient) RagChat(message string, options map[string]any) (ChatHistory, error) {
+	defer c.Invalidate()
+	return c.Antbox.RagChat(message, options)
+}
+
+func (c *CachedClient) ChatWithAgentStream(agentUUID string, message string, conversationID string, temperature *float64, maxTokens *int, history []map[string]any, chatContext map[string]any, onPart ChatStreamHandler) (ChatHistory, error) {
+	defer c.Invalidate()
+	return c.Antbox.ChatWithAgentStream(agentUUID, message, conversationID, temperature, maxTokens, history, chatContext, onPart)
+}
+
+func (c *CachedClient) AnswerFromAgentStream(agentUUID string, query string, temperature *float64, maxTokens *int, chatContext map[string]any, onPart ChatStreamHandler) (ChatHistory, error) {
+	defer c.Invalidate()
+	return c.Antbox.AnswerFromAgentStream(agentUUID, query, temperature, maxTokens, chatContext, onPart)
+}
+
+func (c *CachedClient) RagChatStream(message string, options map[string]any, onPart ChatStreamHandler) (ChatHistory, error) {
+	defer c.Invalidate()
+	return c.Antbox.RagChatStream(message, options, onPart)
+}
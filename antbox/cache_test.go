@@ -0,0 +1,196 @@
+package antbox
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeAntbox serves nodes from memory and counts the requests it gets
+type fakeAntbox struct {
+	Antbox
+	nodes    map[string]Node
+	requests int
+	// during runs while a request is being served, once
+	during func()
+}
+
+func (f *fakeAntbox) serve() {
+	f.requests++
+	if during := f.during; during != nil {
+		f.during = nil
+		during()
+	}
+}
+
+func (f *fakeAntbox) GetNode(uuid string) (*Node, error) {
+	f.serve()
+	node := f.nodes[uuid]
+	return &node, nil
+}
+
+func (f *fakeAntbox) ListNodes(parent string) ([]Node, error) {
+	f.serve()
+	var nodes []Node
+	for _, node := range f.nodes {
+		if node.Parent == parent {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+func (f *fakeAntbox) RemoveNode(uuid string) error {
+	delete(f.nodes, uuid)
+	return nil
+}
+
+func (f *fakeAntbox) CreateFolder(parent, name string) (*Node, error) {
+	node := Node{UUID: name, Title: name, Parent: parent}
+	f.nodes[name] = node
+	return &node, nil
+}
+
+func (f *fakeAntbox) DeleteFeature(uuid string) error {
+	delete(f.nodes, uuid)
+	return nil
+}
+
+func (f *fakeAntbox) RagChat(message string, options map[string]any) (ChatHistory, error) {
+	return nil, nil
+}
+
+func newFakeAntbox() *fakeAntbox {
+	return &fakeAntbox{nodes: map[string]Node{
+		"docs":   {UUID: "docs", Title: "Docs", Parent: "--root--"},
+		"report": {UUID: "report", Title: "Report", Parent: "docs"},
+	}}
+}
+
+func TestCachedClientHitsAndExpiry(t *testing.T) {
+	fake := newFakeAntbox()
+	cache := NewCachedClient(fake, time.Minute)
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	cache.ListNodes("docs")
+	cache.ListNodes("docs")
+	// Listed nodes are cached as nodes too
+	if node, _ := cache.GetNode("report"); node.Title != "Report" {
+		t.Errorf("Unexpected node: %+v", node)
+	}
+	if fake.requests != 1 {
+		t.Errorf("Expected 1 request, got %d", fake.requests)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Nodes != 1 || stats.Listings != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	now = now.Add(2 * time.Minute)
+	cache.ListNodes("docs")
+	if fake.requests != 2 {
+		t.Errorf("Expected expired listings to be fetched again, got %d requests", fake.requests)
+	}
+}
+
+func TestCachedClientInvalidation(t *testing.T) {
+	fake := newFakeAntbox()
+	cache := NewCachedClient(fake, time.Minute)
+
+	cache.ListNodes("--root--")
+	cache.ListNodes("docs")
+
+	cache.CreateFolder("docs", "archive")
+	if nodes, _ := cache.ListNodes("docs"); len(nodes) != 2 {
+		t.Errorf("Expected the new folder to be listed, got %+v", nodes)
+	}
+
+	cache.RemoveNode("report")
+	if nodes, _ := cache.ListNodes("docs"); len(nodes) != 1 {
+		t.Errorf("Expected the removed node to be gone, got %+v", nodes)
+	}
+	if nodes, _ := cache.ListNodes("--root--"); len(nodes) != 1 {
+		t.Errorf("Expected the root listing to stay cached, got %+v", nodes)
+	}
+	if fake.requests != 4 {
+		t.Errorf("Expected 4 requests, got %d", fake.requests)
+	}
+}
+
+func TestCachedClientInvalidatedByFeaturesAndAgents(t *testing.T) {
+	fake := newFakeAntbox()
+	cache := NewCachedClient(fake, time.Minute)
+
+	cache.ListNodes("docs")
+	cache.DeleteFeature("report")
+	if stats := cache.Stats(); stats.Listings != 0 {
+		t.Errorf("Expected deleting a feature to clear the cache, got %+v", stats)
+	}
+
+	cache.ListNodes("docs")
+	cache.RagChat("summarize the report", nil)
+	if stats := cache.Stats(); stats.Listings != 0 {
+		t.Errorf("Expected a RAG chat to clear the cache, got %+v", stats)
+	}
+}
+
+func TestCachedClientSnapshot(t *testing.T) {
+	fake := newFakeAntbox()
+	cache := NewCachedClient(fake, time.Minute)
+	cache.ListNodes("docs")
+
+	restored := NewCachedClient(fake, time.Minute)
+	restored.Restore(cache.Snapshot())
+	restored.ListNodes("docs")
+	if fake.requests != 1 {
+		t.Errorf("Expected the restored listing to be used, got %d requests", fake.requests)
+	}
+
+	disabled := NewCachedClient(fake, 0)
+	disabled.Restore(cache.Snapshot())
+	disabled.ListNodes("docs")
+	disabled.ListNodes("docs")
+	if fake.requests != 3 || disabled.Stats().Listings != 0 {
+		t.Errorf("Expected a disabled cache to keep nothing, got %d requests and %+v", fake.requests, disabled.Stats())
+	}
+}
+
+func TestCachedClientPrefetch(t *testing.T) {
+	fake := newFakeAntbox()
+	cache := NewCachedClient(fake, time.Minute)
+
+	if _, ok := cache.CachedListing("docs"); ok {
+		t.Error("Expected no cached listing before the prefetch")
+	}
+	cache.Prefetch("docs")
+	cache.Prefetch("docs")
+	if nodes, ok := cache.CachedListing("docs"); !ok || len(nodes) != 1 {
+		t.Errorf("Expected the prefetched listing, got %+v", nodes)
+	}
+	if stats := cache.Stats(); fake.requests != 1 || stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("Expected one request and no stats, got %d requests and %+v", fake.requests, stats)
+	}
+}
+
+func TestCachedClientSkipsFetchesOverlappingInvalidation(t *testing.T) {
+	fake := newFakeAntbox()
+	cache := NewCachedClient(fake, time.Minute)
+
+	// The folder is renamed while its stale listing is on the way
+	fake.during = func() { cache.forgetListings("docs") }
+	cache.ListNodes("docs")
+	fake.during = func() { cache.forgetNode("report") }
+	cache.GetNode("report")
+	fake.during = func() { cache.Invalidate() }
+	cache.Prefetch("docs")
+
+	if stats := cache.Stats(); stats.Nodes != 0 || stats.Listings != 0 {
+		t.Errorf("Expected fetches overlapping an invalidation not to be cached, got %+v", stats)
+	}
+
+	cache.ListNodes("docs")
+	if _, ok := cache.CachedListing("docs"); !ok {
+		t.Error("Expected a later fetch to be cached")
+	}
+}
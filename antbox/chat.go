@@ -0,0 +1,273 @@
+package antbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// maxErrorPayload is the number of characters of an unrecognized payload quoted in errors
+const maxErrorPayload = 200
+
+// DecodeChatResponse decodes the body of a chat, answer or RAG response. It accepts every
+// format the server has used:
+//
+//   - a chat history: [{"role": "model", "parts": [...]}, ...]
+//   - an object holding the history: {"history": [...]} or {"messages": [...]}
+//   - a single message: {"role": "model", "parts": [...]}
+//   - a plain answer: {"response": "..."} or {"text": "..."}
+//
+// Messages may carry "content" (a string or a list of parts) instead of "parts", and the
+// "assistant" role is read as "model". Unrecognized payloads are reported as errors.
+func DecodeChatResponse(body []byte) (ChatHistory, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("empty chat response")
+	}
+
+	switch trimmed[0] {
+	case '[':
+		return decodeChatMessages(trimmed)
+	case '{':
+		return decodeChatObject(trimmed)
+	default:
+		return nil, fmt.Errorf("unrecognized chat response: %s", quotePayload(trimmed))
+	}
+}
+
+// decodeChatObject decodes a response that's a JSON object
+func decodeChatObject(data []byte) (ChatHistory, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("invalid chat response: %v", err)
+	}
+
+	for _, key := range []string{"history", "messages"} {
+		if raw, ok := fields[key]; ok {
+			return decodeChatMessages(raw)
+		}
+	}
+
+	if _, ok := fields["role"]; ok {
+		msg, err := decodeChatMessage(data)
+		if err != nil {
+			return nil, err
+		}
+		return ChatHistory{msg}, nil
+	}
+
+	for _, key := range []string{"response", "text", "answer"} {
+		raw, ok := fields[key]
+		if !ok {
+			continue
+		}
+
+		var text string
+		if err := json.Unmarshal(raw, &text); err == nil {
+			return ChatHistory{{Role: ChatMessageRoleModel, Parts: []ChatMessagePart{{Text: &text}}}}, nil
+		}
+
+		// Some endpoints wrap the history itself in "response"
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+			return DecodeChatResponse(trimmed)
+		}
+	}
+
+	return nil, fmt.Errorf("unrecognized chat response with fields %s: %s",
+		strings.Join(slices.Sorted(maps.Keys(fields)), ", "), quotePayload(data))
+}
+
+// decodeChatMessages decodes a list of messages
+func decodeChatMessages(data []byte) (ChatHistory, error) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, fmt.Errorf("chat history must be a list of messages: %v", err)
+	}
+
+	history := make(ChatHistory, 0, len(raws))
+	for i, raw := range raws {
+		msg, err := decodeChatMessage(raw)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %v", i, err)
+		}
+		history = append(history, msg)
+	}
+	return history, nil
+}
+
+// decodeChatMessage decodes a single message
+func decodeChatMessage(data []byte) (ChatMessage, error) {
+	var raw struct {
+		Role    ChatMessageRole   `json:"role"`
+		Parts   []ChatMessagePart `json:"parts"`
+		Content json.RawMessage   `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return ChatMessage{}, fmt.Errorf("invalid message: %v", err)
+	}
+
+	if raw.Role == "" {
+		return ChatMessage{}, fmt.Errorf("message has no role: %s", quotePayload(data))
+	}
+	if raw.Role == "assistant" {
+		raw.Role = ChatMessageRoleModel
+	}
+
+	msg := ChatMessage{Role: raw.Role, Parts: raw.Parts}
+	if msg.Parts == nil && len(raw.Content) > 0 && string(raw.Content) != "null" {
+		var text string
+		if err := json.Unmarshal(raw.Content, &text); err == nil {
+			msg.Parts = []ChatMessagePart{{Text: &text}}
+		} else if err := json.Unmarshal(raw.Content, &msg.Parts); err != nil {
+			return ChatMessage{}, fmt.Errorf("message content must be a string or a list of parts: %v", err)
+		}
+	}
+	return msg, nil
+}
+
+// UnmarshalJSON decodes a message part. Besides the text, reasoning, toolCall and toolResponse
+// fields, it reads the toolRequest/input and output variants, and keeps any other field in
+// Extra so unknown part types survive a round trip.
+func (p *ChatMessagePart) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("message part must be an object: %v", err)
+	}
+
+	*p = ChatMessagePart{}
+	for key, raw := range fields {
+		if string(raw) == "null" {
+			continue
+		}
+
+		var err error
+		switch key {
+		case "text":
+			p.Text, err = decodeStringField(key, raw)
+		case "reasoning":
+			p.Reasoning, err = decodeStringField(key, raw)
+		case "toolCall", "toolRequest":
+			p.ToolCall, err = decodeToolCall(raw)
+		case "toolResponse":
+			p.ToolResponse, err = decodeToolResponse(raw)
+		default:
+			if p.Extra == nil {
+				p.Extra = make(map[string]json.RawMessage)
+			}
+			p.Extra[key] = raw
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes a message part, including the fields kept in Extra
+func (p ChatMessagePart) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]any, len(p.Extra)+4)
+	for key, raw := range p.Extra {
+		fields[key] = raw
+	}
+	if p.Text != nil {
+		fields["text"] = *p.Text
+	}
+	if p.Reasoning != nil {
+		fields["reasoning"] = *p.Reasoning
+	}
+	if p.ToolCall != nil {
+		fields["toolCall"] = p.ToolCall
+	}
+	if p.ToolResponse != nil {
+		fields["toolResponse"] = p.ToolResponse
+	}
+	return json.Marshal(fields)
+}
+
+func decodeStringField(name string, raw json.RawMessage) (*string, error) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("part field %q must be a string", name)
+	}
+	return &value, nil
+}
+
+// decodeToolCall decodes a tool call. The arguments may be in "args" or "input", as an
+// object or as a string holding a JSON object.
+func decodeToolCall(raw json.RawMessage) (*ToolCall, error) {
+	var call struct {
+		Name  string          `json:"name"`
+		Args  json.RawMessage `json:"args"`
+		Input json.RawMessage `json:"input"`
+	}
+	if err := json.Unmarshal(raw, &call); err != nil {
+		return nil, fmt.Errorf("invalid tool call: %v", err)
+	}
+
+	argsRaw := call.Args
+	if len(argsRaw) == 0 {
+		argsRaw = call.Input
+	}
+
+	toolCall := &ToolCall{Name: call.Name}
+	if len(argsRaw) == 0 || string(argsRaw) == "null" {
+		return toolCall, nil
+	}
+
+	if err := json.Unmarshal(argsRaw, &toolCall.Args); err == nil {
+		return toolCall, nil
+	}
+
+	var encoded string
+	if err := json.Unmarshal(argsRaw, &encoded); err == nil {
+		if err := json.Unmarshal([]byte(encoded), &toolCall.Args); err == nil {
+			return toolCall, nil
+		}
+	}
+
+	return nil, fmt.Errorf("arguments of tool call %q must be an object: %s", call.Name, quotePayload(argsRaw))
+}
+
+// decodeToolResponse decodes a tool response. The result may be in "text" or "output";
+// results that aren't strings are kept as their JSON text.
+func decodeToolResponse(raw json.RawMessage) (*ToolResponse, error) {
+	var response struct {
+		Name   string          `json:"name"`
+		Text   json.RawMessage `json:"text"`
+		Output json.RawMessage `json:"output"`
+	}
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return nil, fmt.Errorf("invalid tool response: %v", err)
+	}
+
+	result := response.Text
+	if len(result) == 0 {
+		result = response.Output
+	}
+
+	toolResponse := &ToolResponse{Name: response.Name}
+	if len(result) == 0 || string(result) == "null" {
+		return toolResponse, nil
+	}
+
+	if err := json.Unmarshal(result, &toolResponse.Text); err != nil {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, result); err != nil {
+			return nil, fmt.Errorf("invalid result of tool %q: %v", response.Name, err)
+		}
+		toolResponse.Text = compact.String()
+	}
+	return toolResponse, nil
+}
+
+// quotePayload returns the start of a payload for error messages
+func quotePayload(data []byte) string {
+	text := string(data)
+	if len(text) > maxErrorPayload {
+		text = text[:maxErrorPayload] + "..."
+	}
+	return text
+}
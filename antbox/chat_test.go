@@ -0,0 +1,178 @@
+package antbox
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDecodeChatResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string // roles and parts, summarized by summarizeHistory
+		wantErr string
+	}{
+		{
+			name: "chat history",
+			body: `[{"role":"user","parts":[{"text":"Hi"}]},{"role":"model","parts":[{"text":"Hello!"}]}]`,
+			want: "user: text(Hi) | model: text(Hello!)",
+		},
+		{
+			name: "chat history with tools",
+			body: `[
+				{"role":"model","parts":[{"toolCall":{"name":"find","args":{"q":"x"}}}]},
+				{"role":"tool","parts":[{"toolResponse":{"name":"find","text":"[]"}}]},
+				{"role":"model","parts":[{"text":"Nothing found"}]}
+			]`,
+			want: `model: call(find {"q":"x"}) | tool: response(find []) | model: text(Nothing found)`,
+		},
+		{
+			name: "genkit style tool request and output",
+			body: `[
+				{"role":"model","parts":[{"toolRequest":{"name":"find","input":{"q":"x"},"ref":"1"}}]},
+				{"role":"tool","parts":[{"toolResponse":{"name":"find","output":{"count":0},"ref":"1"}}]}
+			]`,
+			want: `model: call(find {"q":"x"}) | tool: response(find {"count":0})`,
+		},
+		{
+			name: "arguments encoded as a string",
+			body: `[{"role":"model","parts":[{"toolCall":{"name":"find","args":"{\"q\":\"x\"}"}}]}]`,
+			want: `model: call(find {"q":"x"})`,
+		},
+		{
+			name: "reasoning part",
+			body: `[{"role":"model","parts":[{"reasoning":"Thinking"},{"text":"Done"}]}]`,
+			want: "model: reasoning(Thinking) text(Done)",
+		},
+		{
+			name: "unknown part types are kept",
+			body: `[{"role":"model","parts":[{"media":{"url":"https://x/y.png"}},{"text":"See image"}]}]`,
+			want: "model: extra(media) text(See image)",
+		},
+		{
+			name: "legacy content and assistant role",
+			body: `[{"role":"user","content":"Hi"},{"role":"assistant","content":"Hello"}]`,
+			want: "user: text(Hi) | model: text(Hello)",
+		},
+		{
+			name: "history wrapped in an object",
+			body: `{"history":[{"role":"model","parts":[{"text":"Wrapped"}]}]}`,
+			want: "model: text(Wrapped)",
+		},
+		{
+			name: "single message",
+			body: `{"role":"model","parts":[{"text":"Single"}]}`,
+			want: "model: text(Single)",
+		},
+		{
+			name: "plain response",
+			body: `{"response":"The answer is 42"}`,
+			want: "model: text(The answer is 42)",
+		},
+		{
+			name: "empty history",
+			body: `[]`,
+			want: "",
+		},
+		{
+			name:    "empty body",
+			body:    "  ",
+			wantErr: "empty chat response",
+		},
+		{
+			name:    "unknown object",
+			body:    `{"status":"ok","result":1}`,
+			wantErr: "unrecognized chat response with fields result, status",
+		},
+		{
+			name:    "not json",
+			body:    `Internal Server Error`,
+			wantErr: "unrecognized chat response",
+		},
+		{
+			name:    "message without role",
+			body:    `[{"parts":[{"text":"orphan"}]}]`,
+			wantErr: "message 0: message has no role",
+		},
+		{
+			name:    "text that is not a string",
+			body:    `[{"role":"model","parts":[{"text":42}]}]`,
+			wantErr: `part field "text" must be a string`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history, err := DecodeChatResponse([]byte(tt.body))
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := summarizeHistory(history); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChatMessagePartRoundTrip(t *testing.T) {
+	body := `{"media":{"url":"https://x/y.png","contentType":"image/png"},"text":"caption"}`
+
+	var part ChatMessagePart
+	if err := json.Unmarshal([]byte(body), &part); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(part)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var original, roundTrip map[string]any
+	_ = json.Unmarshal([]byte(body), &original)
+	_ = json.Unmarshal(data, &roundTrip)
+
+	if !jsonEqual(original, roundTrip) {
+		t.Errorf("part changed in round trip:\n%s\n%s", body, data)
+	}
+}
+
+// summarizeHistory describes a history compactly for comparisons
+func summarizeHistory(history ChatHistory) string {
+	var messages []string
+	for _, msg := range history {
+		var parts []string
+		for _, part := range msg.Parts {
+			switch {
+			case part.Text != nil:
+				parts = append(parts, "text("+*part.Text+")")
+			case part.Reasoning != nil:
+				parts = append(parts, "reasoning("+*part.Reasoning+")")
+			case part.ToolCall != nil:
+				args, _ := json.Marshal(part.ToolCall.Args)
+				parts = append(parts, "call("+part.ToolCall.Name+" "+string(args)+")")
+			case part.ToolResponse != nil:
+				parts = append(parts, "response("+part.ToolResponse.Name+" "+part.ToolResponse.Text+")")
+			default:
+				for key := range part.Extra {
+					parts = append(parts, "extra("+key+")")
+				}
+			}
+		}
+		messages = append(messages, string(msg.Role)+": "+strings.Join(parts, " "))
+	}
+	return strings.Join(messages, " | ")
+}
+
+func jsonEqual(a, b any) bool {
+	left, _ := json.Marshal(a)
+	right, _ := json.Marshal(b)
+	return string(left) == string(right)
+}
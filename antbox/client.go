@@ -729,6 +729,13 @@ func (c *client) GetBreadcrumbs(uuid string) ([]Node, error) {
 }
 
 func (c *client) ChatWithAgent(agentUUID string, message string, conversationID string, temperature *float64, maxTokens *int, history []map[string]any) (ChatHistory, error) {
+	return c.ChatWithAgentStream(agentUUID, message, conversationID, temperature, maxTokens, history, nil, nil)
+}
+
+// ChatWithAgentStream is ChatWithAgent with a streaming response. onPart is called for each
+// part as it arrives; when the server doesn't stream, the full response is returned as usual.
+// chatContext is sent as the context of the chat options, e.g. the nodes attached to the chat.
+func (c *client) ChatWithAgentStream(agentUUID string, message string, conversationID string, temperature *float64, maxTokens *int, history []map[string]any, chatContext map[string]any, onPart ChatStreamHandler) (ChatHistory, error) {
 	options := make(map[string]any)
 
 	if conversationID != "" && len(history) > 0 {
@@ -741,6 +748,9 @@ func (c *client) ChatWithAgent(agentUUID string, message string, conversationID
 	if maxTokens != nil {
 		options["maxTokens"] = *maxTokens
 	}
+	if len(chatContext) > 0 {
+		options["context"] = chatContext
+	}
 
 	payload := map[string]any{
 		"text": message,
@@ -763,6 +773,9 @@ func (c *client) ChatWithAgent(agentUUID string, message string, conversationID
 
 	c.SetAuthHeader(req)
 	req.Header.Set("Content-Type", "application/json")
+	if onPart != nil {
+		req.Header.Set("Accept", chatStreamAccept)
+	}
 
 	resp, err := c.roundTrip(req)
 	if err != nil {
@@ -774,89 +787,24 @@ func (c *client) ChatWithAgent(agentUUID string, message string, conversationID
 		return nil, NewHttpErrorWithRequestBody(resp, req, string(jsonData))
 	}
 
-	// Try to decode as ChatHistory format
-	var chatHistory ChatHistory
+	if isChatStream(resp) {
+		return readChatStream(resp, onPart)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := json.Unmarshal(body, &chatHistory); err == nil {
-		return chatHistory, nil
-	}
-
-	// Try to decode as array of maps (legacy format)
-	var arrayResult []map[string]any
-	if err := json.Unmarshal(body, &arrayResult); err == nil {
-		// Convert to ChatHistory format
-		var history ChatHistory
-		for _, msg := range arrayResult {
-			chatMsg := ChatMessage{}
-
-			if role, ok := msg["role"].(string); ok {
-				chatMsg.Role = ChatMessageRole(role)
-			}
-
-			if parts, ok := msg["parts"].([]any); ok {
-				for _, partAny := range parts {
-					if partMap, ok := partAny.(map[string]any); ok {
-						part := ChatMessagePart{}
-						if text, ok := partMap["text"].(string); ok {
-							part.Text = &text
-						}
-						if toolCall, ok := partMap["toolCall"].(map[string]any); ok {
-							tc := &ToolCall{}
-							if name, ok := toolCall["name"].(string); ok {
-								tc.Name = name
-							}
-							if args, ok := toolCall["args"].(map[string]interface{}); ok {
-								tc.Args = args
-							}
-							part.ToolCall = tc
-						}
-						if toolResponse, ok := partMap["toolResponse"].(map[string]any); ok {
-							tr := &ToolResponse{}
-							if name, ok := toolResponse["name"].(string); ok {
-								tr.Name = name
-							}
-							if text, ok := toolResponse["text"].(string); ok {
-								tr.Text = text
-							}
-							part.ToolResponse = tr
-						}
-						chatMsg.Parts = append(chatMsg.Parts, part)
-					}
-				}
-			}
-			history = append(history, chatMsg)
-		}
-		return history, nil
-	}
-
-	// Try to decode as object (traditional format)
-	var objectResult map[string]any
-	if err := json.Unmarshal(body, &objectResult); err == nil {
-		// Convert single response to ChatHistory format
-		var history ChatHistory
-		if response, ok := objectResult["response"]; ok {
-			if responseStr, ok := response.(string); ok {
-				chatMsg := ChatMessage{
-					Role: ChatMessageRoleModel,
-					Parts: []ChatMessagePart{
-						{Text: &responseStr},
-					},
-				}
-				history = append(history, chatMsg)
-				return history, nil
-			}
-		}
-	}
-
-	// If all parsing fails, return empty history
-	return ChatHistory{}, nil
+	return DecodeChatResponse(body)
 }
 
 func (c *client) AnswerFromAgent(agentUUID string, query string, temperature *float64, maxTokens *int) (ChatHistory, error) {
+	return c.AnswerFromAgentStream(agentUUID, query, temperature, maxTokens, nil, nil)
+}
+
+// AnswerFromAgentStream is AnswerFromAgent with a streaming response, see ChatWithAgentStream
+func (c *client) AnswerFromAgentStream(agentUUID string, query string, temperature *float64, maxTokens *int, chatContext map[string]any, onPart ChatStreamHandler) (ChatHistory, error) {
 	options := make(map[string]any)
 
 	if temperature != nil {
@@ -865,6 +813,9 @@ func (c *client) AnswerFromAgent(agentUUID string, query string, temperature *fl
 	if maxTokens != nil {
 		options["maxTokens"] = *maxTokens
 	}
+	if len(chatContext) > 0 {
+		options["context"] = chatContext
+	}
 
 	payload := map[string]any{
 		"text": query,
@@ -887,6 +838,9 @@ func (c *client) AnswerFromAgent(agentUUID string, query string, temperature *fl
 
 	c.SetAuthHeader(req)
 	req.Header.Set("Content-Type", "application/json")
+	if onPart != nil {
+		req.Header.Set("Accept", chatStreamAccept)
+	}
 
 	resp, err := c.roundTrip(req)
 	if err != nil {
@@ -898,41 +852,24 @@ func (c *client) AnswerFromAgent(agentUUID string, query string, temperature *fl
 		return nil, NewHttpErrorWithRequestBody(resp, req, string(jsonData))
 	}
 
-	// Try to decode as ChatHistory format
-	var chatHistory ChatHistory
+	if isChatStream(resp) {
+		return readChatStream(resp, onPart)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := json.Unmarshal(body, &chatHistory); err == nil {
-		return chatHistory, nil
-	}
-
-	// Try to decode as object (traditional format)
-	var objectResult map[string]any
-	if err := json.Unmarshal(body, &objectResult); err == nil {
-		// Convert single response to ChatHistory format
-		var history ChatHistory
-		if response, ok := objectResult["response"]; ok {
-			if responseStr, ok := response.(string); ok {
-				chatMsg := ChatMessage{
-					Role: ChatMessageRoleModel,
-					Parts: []ChatMessagePart{
-						{Text: &responseStr},
-					},
-				}
-				history = append(history, chatMsg)
-				return history, nil
-			}
-		}
-	}
-
-	// If all parsing fails, return empty history
-	return ChatHistory{}, nil
+	return DecodeChatResponse(body)
 }
 
 func (c *client) RagChat(message string, options map[string]any) (ChatHistory, error) {
+	return c.RagChatStream(message, options, nil)
+}
+
+// RagChatStream is RagChat with a streaming response, see ChatWithAgentStream
+func (c *client) RagChatStream(message string, options map[string]any, onPart ChatStreamHandler) (ChatHistory, error) {
 
 	payload := map[string]any{
 		"text": message,
@@ -954,6 +891,9 @@ func (c *client) RagChat(message string, options map[string]any) (ChatHistory, e
 
 	c.SetAuthHeader(req)
 	req.Header.Set("Content-Type", "application/json")
+	if onPart != nil {
+		req.Header.Set("Accept", chatStreamAccept)
+	}
 
 	resp, err := c.roundTrip(req)
 	if err != nil {
@@ -965,86 +905,16 @@ func (c *client) RagChat(message string, options map[string]any) (ChatHistory, e
 		return nil, NewHttpErrorWithRequestBody(resp, req, string(jsonData))
 	}
 
-	// Try to decode as ChatHistory format
-	var chatHistory ChatHistory
+	if isChatStream(resp) {
+		return readChatStream(resp, onPart)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := json.Unmarshal(body, &chatHistory); err == nil {
-		return chatHistory, nil
-	}
-
-	// Try to decode as array of maps (legacy format)
-	var arrayResult []map[string]any
-	if err := json.Unmarshal(body, &arrayResult); err == nil {
-		// Convert to ChatHistory format
-		var history ChatHistory
-		for _, msg := range arrayResult {
-			chatMsg := ChatMessage{}
-
-			if role, ok := msg["role"].(string); ok {
-				chatMsg.Role = ChatMessageRole(role)
-			}
-
-			if parts, ok := msg["parts"].([]any); ok {
-				for _, partAny := range parts {
-					if partMap, ok := partAny.(map[string]any); ok {
-						part := ChatMessagePart{}
-						if text, ok := partMap["text"].(string); ok {
-							part.Text = &text
-						}
-						if toolCall, ok := partMap["toolCall"].(map[string]any); ok {
-							tc := &ToolCall{}
-							if name, ok := toolCall["name"].(string); ok {
-								tc.Name = name
-							}
-							if args, ok := toolCall["args"].(map[string]interface{}); ok {
-								tc.Args = args
-							}
-							part.ToolCall = tc
-						}
-						if toolResponse, ok := partMap["toolResponse"].(map[string]any); ok {
-							tr := &ToolResponse{}
-							if name, ok := toolResponse["name"].(string); ok {
-								tr.Name = name
-							}
-							if text, ok := toolResponse["text"].(string); ok {
-								tr.Text = text
-							}
-							part.ToolResponse = tr
-						}
-						chatMsg.Parts = append(chatMsg.Parts, part)
-					}
-				}
-			}
-			history = append(history, chatMsg)
-		}
-		return history, nil
-	}
-
-	// Try to decode as object (traditional format)
-	var objectResult map[string]any
-	if err := json.Unmarshal(body, &objectResult); err == nil {
-		// Convert single response to ChatHistory format
-		var history ChatHistory
-		if response, ok := objectResult["response"]; ok {
-			if responseStr, ok := response.(string); ok {
-				chatMsg := ChatMessage{
-					Role: ChatMessageRoleModel,
-					Parts: []ChatMessagePart{
-						{Text: &responseStr},
-					},
-				}
-				history = append(history, chatMsg)
-				return history, nil
-			}
-		}
-	}
-
-	// If all parsing fails, return empty history
-	return ChatHistory{}, nil
+	return DecodeChatResponse(body)
 }
 
 func (c *client) CopyNode(uuid, parent, title string) (*Node, error) {
@@ -1217,7 +1087,7 @@ func (c *client) DeleteFeature(uuid string) error {
 }
 
 func (c *client) ExportFeature(uuid string, exportType string) (string, error) {
-	url := c.ServerURL + "/features/" + uuid + "/export"
+	url := c.ServerURL + "/features/" + uuid + "/-/export"
 	if exportType != "" {
 		url += "?type=" + exportType
 	}
@@ -1,6 +1,7 @@
 package antbox
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -431,3 +432,50 @@ func TestDownloadNode(t *testing.T) {
 		t.Errorf("Expected downloaded content '%s', got '%s'", testContent, string(content))
 	}
 }
+
+func TestExportFeature(t *testing.T) {
+	source := "export default { uuid: \"test-feature\" };"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/features/test-feature/-/export" {
+			t.Errorf("Expected to request '/features/test-feature/-/export', got %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, source)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "", "test-jwt", false)
+	exported, err := client.ExportFeature("test-feature", "")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if exported != source {
+		t.Errorf("Expected exported source '%s', got '%s'", source, exported)
+	}
+}
+
+func TestAnswerFromAgentSendsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Text    string `json:"text"`
+			Options struct {
+				Context map[string]any `json:"context"`
+			} `json:"options"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("invalid request body: %v", err)
+		}
+		attachments, _ := payload.Options.Context["attachments"].([]any)
+		if len(attachments) != 1 {
+			t.Errorf("Expected one attachment in the context, got %v", payload.Options.Context)
+		}
+		fmt.Fprintln(w, `{"text":"ok"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "", "test-jwt", false)
+	chatContext := map[string]any{"attachments": []map[string]any{{"uuid": "doc-uuid"}}}
+	if _, err := client.AnswerFromAgentStream("agent", "q", nil, nil, chatContext, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
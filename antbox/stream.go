@@ -0,0 +1,220 @@
+package antbox
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ChatStreamHandler receives message parts as they arrive from a streaming chat response.
+// Text and reasoning parts are deltas to be appended to what was received so far.
+type ChatStreamHandler func(part ChatMessagePart)
+
+// chatStreamAccept asks for a streaming response, falling back to plain JSON
+const chatStreamAccept = "text/event-stream, application/x-ndjson;q=0.9, application/json;q=0.8"
+
+// maxStreamLineSize is the largest single event accepted in a stream
+const maxStreamLineSize = 4 * 1024 * 1024
+
+// chatStreamEvent is a single event of a streaming chat response. Besides these fields,
+// an event may carry a part delta (text, reasoning, toolCall...) at its top level.
+type chatStreamEvent struct {
+	Role    ChatMessageRole   `json:"role"`
+	Parts   []ChatMessagePart `json:"parts"`
+	History json.RawMessage   `json:"history"`
+	Error   string            `json:"error"`
+}
+
+// chatStreamEventFields are the event fields that aren't part of a part delta
+var chatStreamEventFields = []string{"role", "parts", "history", "error", "type", "id"}
+
+// isChatStream reports whether the server answered with a streaming response
+func isChatStream(resp *http.Response) bool {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/event-stream", "application/x-ndjson", "application/jsonl":
+		return true
+	}
+	return false
+}
+
+// readChatStream reads a streaming chat response (server-sent events or newline delimited
+// JSON), calling onPart for each part received. It returns the final history sent by the
+// server, or the history built from the parts when the server doesn't send one.
+func readChatStream(resp *http.Response, onPart ChatStreamHandler) (ChatHistory, error) {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	stream := &chatStreamReader{onPart: onPart}
+	var err error
+	if mediaType == "text/event-stream" {
+		err = stream.readEvents(resp.Body)
+	} else {
+		err = stream.readLines(resp.Body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if stream.final != nil {
+		return stream.final, nil
+	}
+	if len(stream.history) == 0 {
+		return nil, errors.New("streaming response ended without any message")
+	}
+	return stream.history, nil
+}
+
+// chatStreamReader accumulates the events of a streaming chat response
+type chatStreamReader struct {
+	onPart  ChatStreamHandler
+	history ChatHistory
+	final   ChatHistory
+}
+
+// readEvents reads server-sent events. Data lines are joined until a blank line
+// ends the event; a "[DONE]" payload ends the stream.
+func (r *chatStreamReader) readEvents(body io.Reader) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLineSize)
+
+	var eventType string
+	var data []string
+
+	dispatch := func() (bool, error) {
+		defer func() {
+			eventType = ""
+			data = nil
+		}()
+
+		payload := strings.Join(data, "\n")
+		switch {
+		case payload == "" && eventType == "":
+			return false, nil
+		case payload == "[DONE]" || eventType == "done" && payload == "":
+			return true, nil
+		case eventType == "error":
+			return true, fmt.Errorf("stream error: %s", payload)
+		}
+		return false, r.handle(payload)
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			done, err := dispatch()
+			if err != nil || done {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment, used by servers as keep-alive
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// The last event may not be followed by a blank line
+	_, err := dispatch()
+	return err
+}
+
+// readLines reads newline delimited JSON events
+func (r *chatStreamReader) readLines(body io.Reader) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLineSize)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := r.handle(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// handle decodes a single event and accumulates its parts
+func (r *chatStreamReader) handle(payload string) error {
+	var event chatStreamEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return fmt.Errorf("failed to decode stream event: %v", err)
+	}
+
+	if event.Error != "" {
+		return fmt.Errorf("stream error: %s", event.Error)
+	}
+
+	if len(event.History) > 0 && string(event.History) != "null" {
+		history, err := decodeChatMessages(event.History)
+		if err != nil {
+			return fmt.Errorf("failed to decode stream history: %v", err)
+		}
+		r.final = history
+	}
+
+	var delta ChatMessagePart
+	if err := json.Unmarshal([]byte(payload), &delta); err != nil {
+		return fmt.Errorf("failed to decode stream event: %v", err)
+	}
+	for _, field := range chatStreamEventFields {
+		delete(delta.Extra, field)
+	}
+
+	parts := event.Parts
+	if delta.Text != nil || delta.Reasoning != nil || delta.ToolCall != nil || delta.ToolResponse != nil || len(delta.Extra) > 0 {
+		parts = append([]ChatMessagePart{delta}, parts...)
+	}
+
+	for _, part := range parts {
+		r.add(event.Role, part)
+		if r.onPart != nil {
+			r.onPart(part)
+		}
+	}
+	return nil
+}
+
+// add appends a part to the history, joining consecutive text and reasoning deltas of the same message
+func (r *chatStreamReader) add(role ChatMessageRole, part ChatMessagePart) {
+	if role == "assistant" {
+		role = ChatMessageRoleModel
+	}
+	if role == "" {
+		role = ChatMessageRoleModel
+		if part.ToolResponse != nil {
+			role = ChatMessageRoleTool
+		}
+	}
+
+	if len(r.history) == 0 || r.history[len(r.history)-1].Role != role {
+		r.history = append(r.history, ChatMessage{Role: role})
+	}
+
+	msg := &r.history[len(r.history)-1]
+	if len(msg.Parts) > 0 {
+		last := &msg.Parts[len(msg.Parts)-1]
+		switch {
+		case part.Text != nil && last.Text != nil:
+			text := *last.Text + *part.Text
+			last.Text = &text
+			return
+		case part.Reasoning != nil && last.Reasoning != nil:
+			reasoning := *last.Reasoning + *part.Reasoning
+			last.Reasoning = &reasoning
+			return
+		}
+	}
+	msg.Parts = append(msg.Parts, part)
+}
@@ -0,0 +1,134 @@
+package antbox
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChatWithAgentStreamSSE(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			t.Errorf("Expected streaming Accept header, got %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "data: {\"toolCall\":{\"name\":\"search\",\"args\":{\"q\":\"x\"}}}\n\n")
+		fmt.Fprint(w, "data: {\"role\":\"tool\",\"toolResponse\":{\"name\":\"search\",\"text\":\"[]\"}}\n\n")
+		fmt.Fprint(w, "data: {\"text\":\"Hel\"}\n\n")
+		fmt.Fprint(w, "data: {\"text\":\"lo\"}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "", "test-jwt", false)
+
+	var deltas []string
+	history, err := client.ChatWithAgentStream("agent", "hi", "", nil, nil, nil, nil, func(part ChatMessagePart) {
+		if part.Text != nil {
+			deltas = append(deltas, *part.Text)
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(deltas, "|") != "Hel|lo" {
+		t.Errorf("Expected deltas Hel|lo, got %v", deltas)
+	}
+	if len(history) != 3 {
+		t.Fatalf("Expected 3 messages, got %d: %+v", len(history), history)
+	}
+	if history[0].Role != ChatMessageRoleModel || history[0].Parts[0].ToolCall == nil {
+		t.Errorf("Expected a model tool call first, got %+v", history[0])
+	}
+	if history[1].Role != ChatMessageRoleTool || history[1].Parts[0].ToolResponse == nil {
+		t.Errorf("Expected a tool response second, got %+v", history[1])
+	}
+	if len(history[2].Parts) != 1 || *history[2].Parts[0].Text != "Hello" {
+		t.Errorf("Expected text deltas to be joined, got %+v", history[2].Parts)
+	}
+}
+
+func TestRagChatStreamNDJSONWithFinalHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"reasoning":"Thin"}`)
+		fmt.Fprintln(w, `{"reasoning":"king"}`)
+		fmt.Fprintln(w, `{"text":"Partial"}`)
+		fmt.Fprintln(w, `{"history":[{"role":"user","parts":[{"text":"q"}]},{"role":"model","parts":[{"text":"Final"}]}]}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "", "test-jwt", false)
+
+	count := 0
+	history, err := client.RagChatStream("q", nil, func(part ChatMessagePart) { count++ })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if count != 3 {
+		t.Errorf("Expected 3 streamed parts, got %d", count)
+	}
+	if len(history) != 2 || *history[1].Parts[0].Text != "Final" {
+		t.Errorf("Expected the final history to be returned, got %+v", history)
+	}
+}
+
+func TestChatStreamErrorEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"text\":\"Hi\"}\n\n")
+		fmt.Fprint(w, "event: error\ndata: model overloaded\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "", "test-jwt", false)
+
+	_, err := client.AnswerFromAgentStream("agent", "q", nil, nil, nil, func(ChatMessagePart) {})
+	if err == nil || !strings.Contains(err.Error(), "model overloaded") {
+		t.Errorf("Expected stream error, got %v", err)
+	}
+}
+
+func TestChatStreamFallsBackToJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `[{"role":"model","parts":[{"text":"Buffered"}]}]`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "", "test-jwt", false)
+
+	called := false
+	history, err := client.ChatWithAgentStream("agent", "hi", "", nil, nil, nil, nil, func(ChatMessagePart) { called = true })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if called {
+		t.Error("Expected no streamed parts for a buffered response")
+	}
+	if len(history) != 1 || *history[0].Parts[0].Text != "Buffered" {
+		t.Errorf("Expected the buffered history, got %+v", history)
+	}
+}
+
+func TestChatStreamJoinsReasoningDeltas(t *testing.T) {
+	stream := &chatStreamReader{}
+	for _, line := range []string{`{"reasoning":"Thin"}`, `{"reasoning":"king"}`, `{"text":"Done"}`} {
+		if err := stream.handle(line); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(stream.history) != 1 || len(stream.history[0].Parts) != 2 {
+		t.Fatalf("Expected one message with two parts, got %+v", stream.history)
+	}
+	if *stream.history[0].Parts[0].Reasoning != "Thinking" || *stream.history[0].Parts[1].Text != "Done" {
+		t.Errorf("Unexpected parts: %+v", stream.history[0].Parts)
+	}
+}
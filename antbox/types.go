@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 )
 
@@ -31,11 +32,14 @@ type ToolResponse struct {
 	Text string `json:"text"`
 }
 
-// ChatMessagePart represents a part of a chat message
+// ChatMessagePart represents a part of a chat message. Fields of part types
+// the client doesn't know are kept in Extra.
 type ChatMessagePart struct {
-	Text         *string       `json:"text,omitempty"`
-	ToolCall     *ToolCall     `json:"toolCall,omitempty"`
-	ToolResponse *ToolResponse `json:"toolResponse,omitempty"`
+	Text         *string                    `json:"text,omitempty"`
+	Reasoning    *string                    `json:"reasoning,omitempty"`
+	ToolCall     *ToolCall                  `json:"toolCall,omitempty"`
+	ToolResponse *ToolResponse              `json:"toolResponse,omitempty"`
+	Extra        map[string]json.RawMessage `json:"-"`
 }
 
 // ChatMessage represents a message in a chat history
@@ -262,6 +266,7 @@ type Agent struct {
 
 // AgentCreate represents the request to create an agent
 type AgentCreate struct {
+	UUID               string  `json:"uuid,omitempty"`
 	SystemInstructions string  `json:"systemInstructions"`
 	Title              string  `json:"title"`
 	Description        string  `json:"description,omitempty"`
@@ -355,22 +360,86 @@ type Template struct {
 
 // Aspect represents an aspect
 type Aspect struct {
-	UUID        string       `json:"uuid,omitempty"`
-	Title       string       `json:"title,omitempty"`
-	Name        string       `json:"name,omitempty"`
-	Description string       `json:"description,omitempty"`
-	Mimetype    string       `json:"mimetype,omitempty"`
-	Owner       string       `json:"owner,omitempty"`
-	Permissions *Permissions `json:"permissions,omitempty"`
+	UUID        string           `json:"uuid,omitempty"`
+	Title       string           `json:"title,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Mimetype    string           `json:"mimetype,omitempty"`
+	Owner       string           `json:"owner,omitempty"`
+	Permissions *Permissions     `json:"permissions,omitempty"`
+	Filters     NodeFilters      `json:"filters,omitempty"`
+	Properties  AspectProperties `json:"properties,omitempty"`
 }
 
 // AspectCreate represents the request to create an aspect
 type AspectCreate struct {
-	Title       string       `json:"title"`
-	Name        string       `json:"name"`
-	Description string       `json:"description,omitempty"`
-	Mimetype    string       `json:"mimetype"`
-	Permissions *Permissions `json:"permissions,omitempty"`
+	UUID        string           `json:"uuid,omitempty"`
+	Title       string           `json:"title"`
+	Name        string           `json:"name,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Mimetype    string           `json:"mimetype,omitempty"`
+	Permissions *Permissions     `json:"permissions,omitempty"`
+	Filters     NodeFilters      `json:"filters,omitempty"`
+	Properties  AspectProperties `json:"properties,omitempty"`
+}
+
+// AspectPropertyTypes lists the property types supported by aspects
+var AspectPropertyTypes = []string{"uuid", "string", "number", "boolean", "object", "array", "file"}
+
+// AspectPropertyArrayTypes lists the item types supported by array properties
+var AspectPropertyArrayTypes = []string{"string", "number", "uuid", "object"}
+
+// AspectProperty represents a property declared by an aspect
+type AspectProperty struct {
+	Name              string   `json:"name"`
+	Title             string   `json:"title"`
+	Type              string   `json:"type"`
+	Description       string   `json:"description,omitempty"`
+	ArrayType         string   `json:"arrayType,omitempty"`
+	StringMimetype    string   `json:"stringMimetype,omitempty"`
+	Readonly          bool     `json:"readonly,omitempty"`
+	ValidationRegex   string   `json:"validationRegex,omitempty"`
+	ValidationList    []string `json:"validationList,omitempty"`
+	ValidationFilters []any    `json:"validationFilters,omitempty"`
+	Required          bool     `json:"required,omitempty"`
+	Searchable        bool     `json:"searchable,omitempty"`
+	Default           any      `json:"default,omitempty"`
+}
+
+// AspectProperties is the list of properties of an aspect
+type AspectProperties []AspectProperty
+
+// UnmarshalJSON accepts both the array form and the object form keyed by
+// property name, since the server has used both representations
+func (p *AspectProperties) UnmarshalJSON(data []byte) error {
+	var list []AspectProperty
+	if err := json.Unmarshal(data, &list); err == nil {
+		*p = list
+		return nil
+	}
+
+	var byName map[string]AspectProperty
+	if err := json.Unmarshal(data, &byName); err != nil {
+		return fmt.Errorf("properties must be an array or an object: %w", err)
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list = make([]AspectProperty, 0, len(byName))
+	for _, name := range names {
+		property := byName[name]
+		if property.Name == "" {
+			property.Name = name
+		}
+		list = append(list, property)
+	}
+
+	*p = list
+	return nil
 }
 
 // Breadcrumb represents a breadcrumb item
@@ -153,3 +153,34 @@ func TestPermissionsOmitEmpty(t *testing.T) {
 		t.Errorf("Empty advanced field should be omitted from JSON: %s", jsonStr)
 	}
 }
+
+func TestAspectPropertiesUnmarshal(t *testing.T) {
+	// Array form
+	var aspect Aspect
+	data := `{"uuid":"invoice","title":"Invoice","properties":[{"name":"amount","title":"Amount","type":"number","required":true}]}`
+	if err := json.Unmarshal([]byte(data), &aspect); err != nil {
+		t.Fatalf("Failed to unmarshal aspect: %v", err)
+	}
+	if len(aspect.Properties) != 1 || aspect.Properties[0].Name != "amount" || !aspect.Properties[0].Required {
+		t.Errorf("Unexpected properties: %+v", aspect.Properties)
+	}
+
+	// Object form keyed by property name
+	aspect = Aspect{}
+	data = `{"uuid":"invoice","properties":{"number":{"title":"Number","type":"string"},"amount":{"title":"Amount","type":"number"}}}`
+	if err := json.Unmarshal([]byte(data), &aspect); err != nil {
+		t.Fatalf("Failed to unmarshal aspect: %v", err)
+	}
+	if len(aspect.Properties) != 2 {
+		t.Fatalf("Expected 2 properties, got %d", len(aspect.Properties))
+	}
+	if aspect.Properties[0].Name != "amount" || aspect.Properties[1].Name != "number" {
+		t.Errorf("Expected properties sorted by name with names filled in, got %+v", aspect.Properties)
+	}
+
+	// Invalid form
+	aspect = Aspect{}
+	if err := json.Unmarshal([]byte(`{"properties":"oops"}`), &aspect); err == nil {
+		t.Error("Expected error for invalid properties")
+	}
+}
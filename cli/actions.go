@@ -1,10 +1,12 @@
 package cli
 
 import (
-	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
 )
 
 type ActionsCommand struct{}
@@ -17,15 +19,21 @@ func (c *ActionsCommand) GetDescription() string {
 	return "List all available actions"
 }
 
-func (c *ActionsCommand) Execute(args []string) {
-	actions, err := client.ListActions()
+func (c *ActionsCommand) Execute(sh *Shell, args []string) {
+	opts, _, err := parseListFlags(sh, args)
 	if err != nil {
-		fmt.Println("Error listing actions:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
 
-	if len(actions) == 0 {
-		fmt.Println("No actions available.")
+	actions, err := sh.client.ListActions()
+	if err != nil {
+		sh.eprintln("Error listing actions:", err)
+		return
+	}
+
+	if len(actions) == 0 && opts.forPeople() && !sh.piping {
+		sh.println("No actions available.")
 		return
 	}
 
@@ -34,45 +42,46 @@ func (c *ActionsCommand) Execute(args []string) {
 		return actions[i].Name < actions[j].Name
 	})
 
-	fmt.Printf("Available actions (%d):\n", len(actions))
-	fmt.Println()
+	columns := featureColumns(
+		listColumn[antbox.Feature]{Name: "runManually", Header: "MANUAL", Wide: true, Value: func(f antbox.Feature) string { return strconv.FormatBool(f.RunManually) }},
+		listColumn[antbox.Feature]{Name: "runOnCreates", Header: "ON CREATE", Wide: true, Value: func(f antbox.Feature) string { return strconv.FormatBool(f.RunOnCreates) }},
+		listColumn[antbox.Feature]{Name: "runOnUpdates", Header: "ON UPDATE", Wide: true, Value: func(f antbox.Feature) string { return strconv.FormatBool(f.RunOnUpdates) }},
+	)
+	if err := renderList(sh, opts, actions, columns); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
 
-	for _, action := range actions {
-		fmt.Printf("UUID: %s\n", action.UUID)
-		fmt.Printf("  Name: %s\n", action.Name)
-		if action.Description != "" {
-			fmt.Printf("  Description: %s\n", action.Description)
-		}
-		fmt.Printf("  Run Manually: %v\n", action.RunManually)
-		fmt.Printf("  Run on Creates: %v\n", action.RunOnCreates)
-		fmt.Printf("  Run on Updates: %v\n", action.RunOnUpdates)
-		if action.RunAs != "" {
-			fmt.Printf("  Run As: %s\n", action.RunAs)
-		}
-		if action.Filters != nil {
-			fmt.Printf("  Node Filtering: enabled\n")
-		}
-		if len(action.GroupsAllowed) > 0 {
-			fmt.Printf("  Groups Allowed: %v\n", action.GroupsAllowed)
-		}
-		if len(action.Parameters) > 0 {
-			fmt.Printf("  Parameters:\n")
-			for _, param := range action.Parameters {
-				required := ""
-				if param.Required {
-					required = " (required)"
-				}
-				fmt.Printf("    - %s (%s)%s: %s\n", param.Name, param.Type, required, param.Description)
-				if param.DefaultValue != nil {
-					fmt.Printf("      Default: %v\n", param.DefaultValue)
-				}
-			}
+// featureColumns returns the columns of action and extension listings, with the given
+// columns before the description
+func featureColumns(extra ...listColumn[antbox.Feature]) []listColumn[antbox.Feature] {
+	columns := []listColumn[antbox.Feature]{
+		{Name: "uuid", Header: "UUID", Value: func(f antbox.Feature) string { return f.UUID }},
+		{Name: "name", Header: "NAME", Value: func(f antbox.Feature) string { return f.Name }},
+		{Name: "parameters", Header: "PARAMETERS", Value: func(f antbox.Feature) string { return featureParameters(f) }},
+		{Name: "runAs", Header: "RUN AS", Wide: true, Value: func(f antbox.Feature) string { return f.RunAs }},
+		{Name: "groupsAllowed", Header: "GROUPS", Wide: true, Value: func(f antbox.Feature) string { return strings.Join(f.GroupsAllowed, ",") }},
+		{Name: "returnType", Header: "RETURNS", Wide: true, Value: func(f antbox.Feature) string { return f.ReturnType }},
+	}
+	columns = append(columns, extra...)
+	return append(columns, listColumn[antbox.Feature]{
+		Name: "description", Header: "DESCRIPTION", Value: func(f antbox.Feature) string { return f.Description },
+	})
+}
+
+// featureParameters lists the parameters of a feature as name:type, marking required ones with *
+func featureParameters(feature antbox.Feature) string {
+	params := make([]string, len(feature.Parameters))
+	for i, param := range feature.Parameters {
+		params[i] = param.Name + ":" + param.Type
+		if param.Required {
+			params[i] += "*"
 		}
-		fmt.Println()
 	}
+	return strings.Join(params, ",")
 }
 
-func (c *ActionsCommand) Suggest(d prompt.Document) []prompt.Suggest {
+func (c *ActionsCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
 	// This command doesn't take arguments, so no suggestions needed
 	return []prompt.Suggest{}
 }
@@ -1,10 +1,16 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
 )
 
 type AgentsCommand struct{}
@@ -14,18 +20,99 @@ func (c *AgentsCommand) GetName() string {
 }
 
 func (c *AgentsCommand) GetDescription() string {
-	return "List all available agents"
+	return "List and manage agents"
 }
 
-func (c *AgentsCommand) Execute(args []string) {
-	agents, err := client.ListAgents()
+func (c *AgentsCommand) Execute(sh *Shell, args []string) {
+	opts, args, err := parseListFlags(sh, args)
 	if err != nil {
-		fmt.Println("Error listing agents:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
 
-	if len(agents) == 0 {
-		fmt.Println("No agents available.")
+	if len(args) == 0 {
+		c.listAgents(sh, opts)
+		return
+	}
+
+	subcommand := args[0]
+	switch subcommand {
+	case "list":
+		c.listAgents(sh, opts)
+	case "show":
+		if len(args) < 2 {
+			sh.println("Usage: agents show <uuid>")
+			return
+		}
+		c.showAgent(sh, args[1], opts.Format)
+	case "rm":
+		if len(args) < 2 {
+			sh.println("Usage: agents rm <uuid>")
+			return
+		}
+		c.removeAgent(sh, args[1])
+	case "export":
+		if len(args) < 2 {
+			sh.println("Usage: agents export <uuid> [file]")
+			return
+		}
+		outputPath := ""
+		if len(args) > 2 {
+			outputPath = strings.Join(args[2:], " ")
+		}
+		c.exportAgent(sh, args[1], outputPath)
+	case "diff":
+		if len(args) < 3 {
+			sh.println("Usage: agents diff <uuid> <file>")
+			return
+		}
+		c.diffAgent(sh, args[1], strings.Join(args[2:], " "))
+	case "new":
+		outputPath := ""
+		if len(args) > 1 {
+			outputPath = strings.Join(args[1:], " ")
+		}
+		c.newAgent(sh, outputPath)
+	case "help", "-h":
+		c.showUsage(sh)
+	default:
+		sh.printf("Unknown subcommand: %s\n", subcommand)
+		c.showUsage(sh)
+	}
+}
+
+func (c *AgentsCommand) showUsage(sh *Shell) {
+	sh.println("Usage: agents <subcommand> [args] [-o table|wide|json|yaml|csv]")
+	sh.println()
+	sh.println("Subcommands:")
+	sh.println("  list                  List all agents (default)")
+	sh.println("  show <uuid>           Show the full agent definition")
+	sh.println("  rm <uuid>             Delete an agent")
+	sh.println("  export <uuid> [file]  Export the agent definition as JSON")
+	sh.println("  diff <uuid> <file>    Compare the server agent with a local JSON file")
+	sh.println("  new [file]            Scaffold a new agent definition file")
+	sh.println()
+	sh.println("Exported files can be edited and uploaded again with 'upload -i <file>'.")
+	sh.println()
+	sh.println("Examples:")
+	sh.println("  agents show support-agent")
+	sh.println("  agents export support-agent support-agent.json")
+	sh.println("  agents diff support-agent support-agent.json")
+	sh.println("  agents new")
+}
+
+func (c *AgentsCommand) listAgents(sh *Shell, opts listOptions) {
+	agents, err := sh.client.ListAgents()
+	if err != nil {
+		sh.eprintln("Error listing agents:", err)
+		return
+	}
+
+	// Keep the completion cache in sync with what we just fetched
+	sh.updateResources(func() { sh.agents = agents })
+
+	if len(agents) == 0 && opts.forPeople() && !sh.piping {
+		sh.println("No agents available.")
 		return
 	}
 
@@ -34,27 +121,362 @@ func (c *AgentsCommand) Execute(args []string) {
 		return agents[i].Title < agents[j].Title
 	})
 
-	fmt.Printf("Available agents (%d):\n", len(agents))
-	fmt.Println()
+	if err := renderList(sh, opts, agents, agentColumns); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
+
+// agentColumns are the columns of agent listings
+var agentColumns = []listColumn[antbox.Agent]{
+	{Name: "uuid", Header: "UUID", Value: func(a antbox.Agent) string { return a.UUID }},
+	{Name: "title", Header: "TITLE", Value: func(a antbox.Agent) string { return a.Title }},
+	{Name: "model", Header: "MODEL", Wide: true, Value: func(a antbox.Agent) string { return a.Model }},
+	{Name: "temperature", Header: "TEMPERATURE", Wide: true, Value: func(a antbox.Agent) string {
+		return strconv.FormatFloat(a.Temperature, 'f', -1, 64)
+	}},
+	{Name: "maxTokens", Header: "MAX TOKENS", Wide: true, Value: func(a antbox.Agent) string { return strconv.Itoa(a.MaxTokens) }},
+	{Name: "reasoning", Header: "REASONING", Wide: true, Value: func(a antbox.Agent) string { return strconv.FormatBool(a.Reasoning) }},
+	{Name: "useTools", Header: "TOOLS", Wide: true, Value: func(a antbox.Agent) string { return strconv.FormatBool(a.UseTools) }},
+	{Name: "description", Header: "DESCRIPTION", Value: func(a antbox.Agent) string { return a.Description }},
+}
+
+func (c *AgentsCommand) showAgent(sh *Shell, uuid string, format string) {
+	agent, err := sh.client.GetAgent(uuid)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	if err := renderValue(sh, format, agent, func(bool) { printAgent(sh, agent) }); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
+
+// printAgent prints the full definition of an agent
+func printAgent(sh *Shell, agent *antbox.Agent) {
+	template := "%-18s: %v\n"
+	sh.printf(template, "UUID", agent.UUID)
+	sh.printf(template, "Title", agent.Title)
+	if agent.Description != "" {
+		sh.printf(template, "Description", agent.Description)
+	}
+	if agent.Owner != "" {
+		sh.printf(template, "Owner", agent.Owner)
+	}
+	if agent.CreatedAt != "" {
+		sh.printf(template, "Created", formatModifiedDate(agent.CreatedAt))
+	}
+	if agent.ModifiedAt != "" {
+		sh.printf(template, "Modified", formatModifiedDate(agent.ModifiedAt))
+	}
+	sh.printf(template, "Model", agent.Model)
+	sh.printf(template, "Temperature", agent.Temperature)
+	sh.printf(template, "Max Tokens", agent.MaxTokens)
+	sh.printf(template, "Reasoning", agent.Reasoning)
+	sh.printf(template, "Use Tools", agent.UseTools)
+
+	if agent.StructuredAnswer != "" {
+		sh.println()
+		sh.println("Structured Answer:")
+		sh.println(indentText(prettyJSONString(agent.StructuredAnswer), "  "))
+	}
+
+	sh.println()
+	sh.println("System Instructions:")
+	sh.println(indentText(agent.SystemInstructions, "  "))
+}
+
+func (c *AgentsCommand) removeAgent(sh *Shell, uuid string) {
+	if err := sh.client.DeleteAgent(uuid); err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	sh.updateResources(func() {
+		sh.agents = slices.DeleteFunc(sh.agents, func(a antbox.Agent) bool {
+			return a.UUID == uuid
+		})
+	})
+
+	sh.printf("Agent %s removed successfully\n", uuid)
+}
+
+func (c *AgentsCommand) exportAgent(sh *Shell, uuid string, outputPath string) {
+	agent, err := sh.client.GetAgent(uuid)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	data, err := json.MarshalIndent(agentDefinition(agent), "", "  ")
+	if err != nil {
+		sh.eprintln("Error formatting agent:", err)
+		return
+	}
+
+	if outputPath == "" {
+		sh.println(string(data))
+		return
+	}
+
+	if err := os.WriteFile(outputPath, append(data, '\n'), 0644); err != nil {
+		sh.eprintln("Error writing file:", err)
+		return
+	}
 
-	for _, agent := range agents {
-		fmt.Printf("UUID: %s\n", agent.UUID)
-		fmt.Printf("  Title: %s\n", agent.Title)
-		if agent.Description != "" {
-			fmt.Printf("  Description: %s\n", agent.Description)
+	sh.printf("Agent %s exported to %s\n", uuid, outputPath)
+}
+
+func (c *AgentsCommand) diffAgent(sh *Shell, uuid string, filePath string) {
+	agent, err := sh.client.GetAgent(uuid)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		sh.eprintln("Error reading file:", err)
+		return
+	}
+
+	var local antbox.AgentCreate
+	if err := json.Unmarshal(data, &local); err != nil {
+		sh.eprintf("Error parsing %s: %v\n", filePath, err)
+		return
+	}
+
+	differences := diffAgentDefinitions(agentDefinition(agent), local)
+	if len(differences) == 0 {
+		sh.printf("Agent %s matches %s\n", uuid, filePath)
+		return
+	}
+
+	sh.printf("--- server: %s\n", uuid)
+	sh.printf("+++ local:  %s\n", filePath)
+	for _, line := range differences {
+		sh.println(line)
+	}
+}
+
+func (c *AgentsCommand) newAgent(sh *Shell, outputPath string) {
+	sh.println("Scaffold a new agent definition (press Enter to accept defaults)")
+	sh.println()
+
+	definition := defaultAgentDefinition()
+	definition.Title = sh.readInput("Title", definition.Title)
+	definition.UUID = sh.readInput("UUID", slugify(definition.Title))
+	definition.Description = sh.readInput("Description", definition.Description)
+	definition.Model = sh.readInput("Model", definition.Model)
+
+	if outputPath == "" {
+		outputPath = sh.readInput("File", definition.UUID+".json")
+	}
+
+	if _, err := os.Stat(outputPath); err == nil && !sh.confirm(fmt.Sprintf("%s exists. Overwrite?", outputPath)) {
+		sh.println("Agent scaffold cancelled.")
+		return
+	}
+
+	data, err := json.MarshalIndent(definition, "", "  ")
+	if err != nil {
+		sh.eprintln("Error formatting agent:", err)
+		return
+	}
+
+	if err := os.WriteFile(outputPath, append(data, '\n'), 0644); err != nil {
+		sh.eprintln("Error writing file:", err)
+		return
+	}
+
+	sh.printf("Agent definition written to %s\n", outputPath)
+	sh.printf("Edit it and run 'upload -i %s' to create the agent.\n", outputPath)
+}
+
+// defaultAgentDefinition returns the starting point for new agents
+func defaultAgentDefinition() antbox.AgentCreate {
+	return antbox.AgentCreate{
+		Title:              "New Agent",
+		Description:        "",
+		Model:              "default",
+		Temperature:        0.7,
+		MaxTokens:          8192,
+		Reasoning:          false,
+		UseTools:           true,
+		SystemInstructions: "You are a helpful assistant for the Antbox ECM. Answer concisely and use the available tools to look up nodes when needed.",
+	}
+}
+
+// agentDefinition keeps the fields of an agent that can be uploaded, dropping server managed ones
+func agentDefinition(agent *antbox.Agent) antbox.AgentCreate {
+	return antbox.AgentCreate{
+		UUID:               agent.UUID,
+		SystemInstructions: agent.SystemInstructions,
+		Title:              agent.Title,
+		Description:        agent.Description,
+		Model:              agent.Model,
+		Temperature:        agent.Temperature,
+		MaxTokens:          agent.MaxTokens,
+		Reasoning:          agent.Reasoning,
+		UseTools:           agent.UseTools,
+		StructuredAnswer:   agent.StructuredAnswer,
+	}
+}
+
+// diffAgentDefinitions returns the differences between two agent definitions as
+// "-" (remote) and "+" (local) lines, with multi-line fields diffed line by line
+func diffAgentDefinitions(remote, local antbox.AgentCreate) []string {
+	var result []string
+
+	field := func(name, a, b string) {
+		if a == b {
+			return
 		}
-		if agent.Temperature > 0 {
-			fmt.Printf("  Temperature: %.2f\n", agent.Temperature)
+		if !strings.Contains(a, "\n") && !strings.Contains(b, "\n") {
+			result = append(result, fmt.Sprintf("%s:", name))
+			result = append(result, fmt.Sprintf("- %s", a))
+			result = append(result, fmt.Sprintf("+ %s", b))
+			return
 		}
-		if agent.MaxTokens > 0 {
-			fmt.Printf("  Max Tokens: %d\n", agent.MaxTokens)
+		result = append(result, fmt.Sprintf("%s:", name))
+		result = append(result, diffLines(strings.Split(a, "\n"), strings.Split(b, "\n"))...)
+	}
+
+	field("uuid", remote.UUID, local.UUID)
+	field("title", remote.Title, local.Title)
+	field("description", remote.Description, local.Description)
+	field("model", remote.Model, local.Model)
+	field("temperature", strconv.FormatFloat(remote.Temperature, 'f', -1, 64), strconv.FormatFloat(local.Temperature, 'f', -1, 64))
+	field("maxTokens", strconv.Itoa(remote.MaxTokens), strconv.Itoa(local.MaxTokens))
+	field("reasoning", strconv.FormatBool(remote.Reasoning), strconv.FormatBool(local.Reasoning))
+	field("useTools", strconv.FormatBool(remote.UseTools), strconv.FormatBool(local.UseTools))
+	field("structuredAnswer", prettyJSONString(remote.StructuredAnswer), prettyJSONString(local.StructuredAnswer))
+	field("systemInstructions", remote.SystemInstructions, local.SystemInstructions)
+
+	return result
+}
+
+// diffLines returns a line diff of a and b, prefixing lines with "-", "+" or " "
+func diffLines(a, b []string) []string {
+	// Longest common subsequence table, filled from the end
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var result []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, "- "+a[i])
+			i++
+		default:
+			result = append(result, "+ "+b[j])
+			j++
 		}
-		fmt.Println()
 	}
+	for ; i < len(a); i++ {
+		result = append(result, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		result = append(result, "+ "+b[j])
+	}
+
+	return result
+}
+
+// prettyJSONString indents a JSON document, returning the input unchanged if it isn't valid JSON
+func prettyJSONString(text string) string {
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return text
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return text
+	}
+	return string(data)
 }
 
-func (c *AgentsCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	// This command doesn't take arguments, so no suggestions needed
+// indentText prefixes every line of text with the given indentation
+func indentText(text, indent string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = indent + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *AgentsCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := fieldsBeforeCursor(d)
+
+	if len(args) == 0 {
+		return []prompt.Suggest{}
+	}
+
+	// Count actual arguments (excluding the command name)
+	argCount := len(args) - 1
+	if !cursorAfterBlank(d) && len(args) > 1 {
+		argCount = len(args) - 2 // We're still typing the current argument
+	}
+
+	currentWord := wordBeforeCursor(d)
+
+	switch argCount {
+	case 0:
+		subcommands := []prompt.Suggest{
+			{Text: "list", Description: "List all agents"},
+			{Text: "show", Description: "Show the full agent definition"},
+			{Text: "rm", Description: "Delete an agent"},
+			{Text: "export", Description: "Export the agent definition as JSON"},
+			{Text: "diff", Description: "Compare an agent with a local file"},
+			{Text: "new", Description: "Scaffold a new agent definition"},
+		}
+
+		var filtered []prompt.Suggest
+		for _, cmd := range subcommands {
+			if strings.HasPrefix(strings.ToLower(cmd.Text), strings.ToLower(currentWord)) {
+				filtered = append(filtered, cmd)
+			}
+		}
+		return filtered
+	case 1:
+		switch args[1] {
+		case "show", "rm", "export", "diff":
+			var suggests []prompt.Suggest
+			for _, agent := range sh.GetCachedAgents() {
+				if strings.HasPrefix(strings.ToLower(agent.UUID), strings.ToLower(currentWord)) ||
+					strings.HasPrefix(strings.ToLower(agent.Title), strings.ToLower(currentWord)) {
+					suggests = append(suggests, prompt.Suggest{
+						Text:        agent.UUID,
+						Description: agent.Title,
+					})
+				}
+			}
+			return suggests
+		case "new":
+			return getFileSystemSuggestions(currentWord)
+		}
+	case 2:
+		if args[1] == "export" || args[1] == "diff" {
+			return getFileSystemSuggestions(currentWord)
+		}
+	}
+
 	return []prompt.Suggest{}
 }
 
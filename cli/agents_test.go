@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+func TestDiffLines(t *testing.T) {
+	a := []string{"one", "two", "three"}
+	b := []string{"one", "2", "three", "four"}
+
+	expected := []string{"  one", "- two", "+ 2", "  three", "+ four"}
+	if result := diffLines(a, b); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestDiffAgentDefinitions(t *testing.T) {
+	remote := antbox.AgentCreate{
+		UUID:               "support",
+		Title:              "Support",
+		Model:              "default",
+		Temperature:        0.7,
+		SystemInstructions: "Be nice.\nBe brief.",
+	}
+
+	if differences := diffAgentDefinitions(remote, remote); len(differences) != 0 {
+		t.Errorf("Expected no differences, got %v", differences)
+	}
+
+	local := remote
+	local.Temperature = 0.2
+	local.SystemInstructions = "Be nice.\nBe thorough."
+
+	differences := diffAgentDefinitions(remote, local)
+	for _, expected := range []string{"temperature:", "- 0.7", "+ 0.2", "systemInstructions:", "  Be nice.", "- Be brief.", "+ Be thorough."} {
+		if !slices.Contains(differences, expected) {
+			t.Errorf("Expected %q in differences %v", expected, differences)
+		}
+	}
+	if slices.Contains(differences, "title:") {
+		t.Errorf("Unchanged title should not be reported: %v", differences)
+	}
+}
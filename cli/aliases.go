@@ -1,8 +1,6 @@
 package cli
 
 import (
-	"fmt"
-
 	"github.com/c-bata/go-prompt"
 )
 
@@ -16,59 +14,60 @@ func (c *AliasesCommand) GetDescription() string {
 	return "Show current alias values"
 }
 
-func (c *AliasesCommand) Execute(args []string) {
+func (c *AliasesCommand) Execute(sh *Shell, args []string) {
 	if len(args) > 0 {
-		fmt.Println("Usage: aliases")
-		fmt.Println()
-		fmt.Println("Description:")
-		fmt.Println("  Display the current values of special aliases used in navigation.")
-		fmt.Println("  These aliases can be used in any command that accepts UUIDs.")
-		fmt.Println()
-		fmt.Println("Available aliases:")
-		fmt.Println("  .   Current node UUID")
-		fmt.Println("  ..  Parent node UUID")
-		fmt.Println()
-		fmt.Println("Examples:")
-		fmt.Println("  stat .           # Show info about current node")
-		fmt.Println("  run action-uuid .. # Run action on parent node")
-		fmt.Println("  cd .             # Stay in current folder")
+		sh.println("Usage: aliases")
+		sh.println()
+		sh.println("Description:")
+		sh.println("  Display the current values of special aliases used in navigation.")
+		sh.println("  These aliases can be used in any command that accepts UUIDs.")
+		sh.println()
+		sh.println("Available aliases:")
+		sh.println("  .   Current node UUID")
+		sh.println("  ..  Parent node UUID")
+		sh.println()
+		sh.println("Examples:")
+		sh.println("  stat .           # Show info about current node")
+		sh.println("  run action-uuid .. # Run action on parent node")
+		sh.println("  cd .             # Stay in current folder")
 		return
 	}
 
-	fmt.Println("Current Alias Values:")
-	fmt.Println("=====================")
-	fmt.Printf("  .  (current) = %s", currentNode.UUID)
-	if currentNode.UUID == "--root--" {
-		fmt.Printf(" (root)")
+	sh.println("Current Alias Values:")
+	sh.println("=====================")
+	current := sh.getCurrentNode()
+	sh.printf("  .  (current) = %s", current.UUID)
+	if current.UUID == "--root--" {
+		sh.printf(" (root)")
 	} else {
-		fmt.Printf(" (%s)", currentNode.Title)
+		sh.printf(" (%s)", current.Title)
 	}
-	fmt.Println()
+	sh.println()
 
-	parentUUID := currentNode.Parent
+	parentUUID := current.Parent
 	if parentUUID == "" {
 		parentUUID = "--root--"
 	}
-	fmt.Printf("  .. (parent)  = %s", parentUUID)
+	sh.printf("  .. (parent)  = %s", parentUUID)
 
 	if parentUUID == "--root--" {
-		fmt.Printf(" (root)")
+		sh.printf(" (root)")
 	} else {
 		// Try to get parent node title for display
-		if parentNode, err := client.GetNode(parentUUID); err == nil {
-			fmt.Printf(" (%s)", parentNode.Title)
+		if parentNode, err := sh.client.GetNode(parentUUID); err == nil {
+			sh.printf(" (%s)", parentNode.Title)
 		}
 	}
-	fmt.Println()
+	sh.println()
 
-	fmt.Println()
-	fmt.Println("Usage:")
-	fmt.Println("  These aliases are automatically resolved in all commands.")
-	fmt.Println("  Example: 'stat .' shows info about the current node.")
-	fmt.Println("  Example: 'cd ..' navigates to the parent folder.")
+	sh.println()
+	sh.println("Usage:")
+	sh.println("  These aliases are automatically resolved in all commands.")
+	sh.println("  Example: 'stat .' shows info about the current node.")
+	sh.println("  Example: 'cd ..' navigates to the parent folder.")
 }
 
-func (c *AliasesCommand) Suggest(d prompt.Document) []prompt.Suggest {
+func (c *AliasesCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
 	return []prompt.Suggest{}
 }
 
@@ -2,10 +2,14 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
 )
 
 type AnswerCommand struct{}
@@ -18,16 +22,33 @@ func (c *AnswerCommand) GetDescription() string {
 	return "Send question to specific agent for answering"
 }
 
-func (c *AnswerCommand) Execute(args []string) {
+func (c *AnswerCommand) Execute(sh *Shell, args []string) {
 	if len(args) < 2 {
-		fmt.Println("Usage: answer [options] <agent_uuid> <question>")
-		fmt.Println("Options:")
-		fmt.Println("  -t <temperature>  Temperature for response generation (0.0-1.0)")
-		fmt.Println("  -m <max_tokens>   Maximum tokens in the response")
-		fmt.Println()
-		fmt.Println("Arguments:")
-		fmt.Println("  agent_uuid: UUID of the agent to ask")
-		fmt.Println("  question: Question to ask the agent")
+		sh.println("Usage: answer [options] <agent_uuid> <question>")
+		sh.println("       answer --batch <questions_file> [batch options] <agent_uuid>")
+		sh.println("Options:")
+		sh.println("  -t <temperature>  Temperature for response generation (0.0-1.0)")
+		sh.println("  -m <max_tokens>   Maximum tokens in the response")
+		sh.println("  --trace           Show tool calls, tool responses and reasoning")
+		sh.println("  -o <json|table>   Print a structured answer as JSON (default) or a table")
+		sh.println("  --field <path>    Print only a field of a structured answer, e.g. items.0.name")
+		sh.println("  --node <uuid>     Attach a node as context (can be repeated)")
+		sh.println("  --file <path>     Upload a local file as a temporary node and attach it (can be repeated)")
+		sh.println()
+		sh.println("Batch options:")
+		sh.println("  --batch <file>      Ask each line of a file (blank lines and # comments are skipped)")
+		sh.println("  --concurrency <n>   Questions asked at the same time (default 4)")
+		sh.println("  --repeat <n>        Ask each question n times")
+		sh.println("  --out <file>        Write the results to a file instead of stdout")
+		sh.println("  --format <jsonl|csv> Results format (default: csv for .csv files, jsonl otherwise)")
+		sh.println("  --compare <file>    Show the answers that changed since a previous results file")
+		sh.println()
+		sh.println("Arguments:")
+		sh.println("  agent_uuid: UUID of the agent to ask")
+		sh.println("  question: Question to ask the agent")
+		sh.println()
+		sh.println("Agents with a structured answer schema reply with JSON, which is validated")
+		sh.println("against the schema. -o and --field also parse replies of other agents as JSON.")
 		return
 	}
 
@@ -35,31 +56,100 @@ func (c *AnswerCommand) Execute(args []string) {
 	var maxTokens *int
 	var agentUUID string
 	var questionArgs []string
+	var output string
+	var field string
+	var nodes []string
+	var files []string
+	batch := batchOptions{concurrency: defaultBatchConcurrency, repeat: 1}
+	trace := false
 
 	// Parse flags and arguments
 	i := 0
 	for i < len(args) {
 		switch args[i] {
+		case "--trace":
+			trace = true
+			i++
+		case "-o":
+			if i+1 >= len(args) {
+				sh.eprintln("Error: -o requires json or table")
+				return
+			}
+			output = args[i+1]
+			i += 2
+		case "--node":
+			if i+1 >= len(args) {
+				sh.eprintln("Error: --node requires a node UUID")
+				return
+			}
+			nodes = append(nodes, args[i+1])
+			i += 2
+		case "--file":
+			if i+1 >= len(args) {
+				sh.eprintln("Error: --file requires a file path")
+				return
+			}
+			files = append(files, args[i+1])
+			i += 2
+		case "--batch", "--out", "--format", "--compare":
+			if i+1 >= len(args) {
+				sh.eprintf("Error: %s requires a value\n", args[i])
+				return
+			}
+			switch args[i] {
+			case "--batch":
+				batch.file = args[i+1]
+			case "--out":
+				batch.out = args[i+1]
+			case "--format":
+				batch.format = args[i+1]
+			case "--compare":
+				batch.compare = args[i+1]
+			}
+			i += 2
+		case "--concurrency", "--repeat":
+			if i+1 >= len(args) {
+				sh.eprintf("Error: %s requires a number\n", args[i])
+				return
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n <= 0 {
+				sh.eprintf("Error: %s must be a positive integer\n", args[i])
+				return
+			}
+			if args[i] == "--concurrency" {
+				batch.concurrency = n
+			} else {
+				batch.repeat = n
+			}
+			i += 2
+		case "--field":
+			if i+1 >= len(args) {
+				sh.eprintln("Error: --field requires a path")
+				return
+			}
+			field = args[i+1]
+			i += 2
 		case "-t":
 			if i+1 >= len(args) {
-				fmt.Println("Error: -t requires a temperature value")
+				sh.eprintln("Error: -t requires a temperature value")
 				return
 			}
 			temp, err := strconv.ParseFloat(args[i+1], 64)
 			if err != nil || temp < 0 || temp > 1 {
-				fmt.Println("Error: Temperature must be a number between 0.0 and 1.0")
+				sh.eprintln("Error: Temperature must be a number between 0.0 and 1.0")
 				return
 			}
 			temperature = &temp
 			i += 2
 		case "-m":
 			if i+1 >= len(args) {
-				fmt.Println("Error: -m requires a max tokens value")
+				sh.eprintln("Error: -m requires a max tokens value")
 				return
 			}
 			tokens, err := strconv.Atoi(args[i+1])
 			if err != nil || tokens <= 0 {
-				fmt.Println("Error: Max tokens must be a positive integer")
+				sh.eprintln("Error: Max tokens must be a positive integer")
 				return
 			}
 			maxTokens = &tokens
@@ -78,58 +168,226 @@ func (c *AnswerCommand) Execute(args []string) {
 parseComplete:
 
 	if agentUUID == "" {
-		fmt.Println("Error: Agent UUID is required")
+		sh.eprintln("Error: Agent UUID is required")
 		return
 	}
 
-	if len(questionArgs) == 0 {
-		fmt.Println("Error: Question is required")
+	if len(questionArgs) == 0 && batch.file == "" {
+		sh.eprintln("Error: Question is required")
 		return
 	}
 
 	question := strings.Join(questionArgs, " ")
 
-	// Find agent name for display
+	agent := findAgent(sh, agentUUID)
 	agentName := agentUUID
-	for _, agent := range GetCachedAgents() {
-		if agent.UUID == agentUUID {
-			agentName = agent.Title
-			break
-		}
+	if agent != nil {
+		agentName = agent.Title
+	}
+
+	attachments, err := resolveAttachments(sh, nodes, files)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+	defer removeTemporaryAttachments(sh, attachments)
+	chatContext := attachmentContext(attachments)
+
+	if batch.file != "" {
+		c.batchAnswer(sh, agentUUID, agentName, temperature, maxTokens, chatContext, batch)
+		return
+	}
+
+	if (agent != nil && agent.StructuredAnswer != "") || output != "" || field != "" {
+		c.structuredAnswer(sh, agentUUID, agent, agentName, question, temperature, maxTokens, chatContext, output, field)
+		return
 	}
 
-	// Show loading animation while waiting for response
-	animation := StartLoadingAnimationWithStyle(fmt.Sprintf("Asking %s", agentName), SpinnerStyle)
-	chatHistory, err := client.AnswerFromAgent(agentUUID, question, temperature, maxTokens)
+	// Show loading animation until the response starts streaming
+	animation := sh.startLoadingAnimation(fmt.Sprintf("Asking %s", agentName), SpinnerStyle)
+	printer := newStreamPrinter(sh.out, animation, fmt.Sprintf("✓ Response from %s:", agentName), "", trace)
+	chatHistory, err := sh.client.AnswerFromAgentStream(agentUUID, question, temperature, maxTokens, chatContext, printer.onPart)
 
+	if err != nil {
+		printer.fail(fmt.Sprintf("✗ Error asking %s", agentName))
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	response := responseText(chatHistory)
+	if response == "" {
+		response = "(no response)"
+	}
+	printer.finish(response, chatHistory)
+}
+
+// structuredAnswer asks for a JSON answer, validates it against the agent's structured answer
+// schema and prints it. The reply isn't streamed, partial JSON is of no use.
+func (c *AnswerCommand) structuredAnswer(sh *Shell, agentUUID string, agent *antbox.Agent, agentName, question string, temperature *float64, maxTokens *int, chatContext map[string]any, output, field string) {
+	var schema map[string]any
+	if agent != nil && agent.StructuredAnswer != "" {
+		var err error
+		if schema, err = parseStructuredSchema(agent.StructuredAnswer); err != nil {
+			sh.eprintln("Warning:", err)
+		}
+	}
+
+	animation := sh.startLoadingAnimation(fmt.Sprintf("Asking %s", agentName), SpinnerStyle)
+	chatHistory, err := sh.client.AnswerFromAgentStream(agentUUID, question, temperature, maxTokens, chatContext, nil)
 	if err != nil {
 		animation.StopWithMessage(fmt.Sprintf("✗ Error asking %s", agentName))
-		fmt.Println("Error:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
+	animation.Stop()
 
-	animation.StopWithMessage(fmt.Sprintf("✓ Response from %s:", agentName))
+	response := responseText(chatHistory)
+	value, err := parseStructuredReply(response)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		sh.println(response)
+		return
+	}
 
-	// Find the last model response from the chat history
-	for i := len(chatHistory) - 1; i >= 0; i-- {
-		msg := chatHistory[i]
-		if msg.Role == "model" {
-			for _, part := range msg.Parts {
-				if part.Text != nil {
-					fmt.Println(*part.Text)
-					return
-				}
+	if schema != nil {
+		if violations := validateSchema(schema, value); len(violations) > 0 {
+			sh.eprintln("Warning: answer doesn't match the structured answer schema:")
+			for _, violation := range violations {
+				sh.eprintf("  %s\n", violation)
 			}
 		}
 	}
 
-	// If no model response found, show that no response was received
-	fmt.Println("(no response)")
+	if field != "" {
+		if value, err = extractField(value, field); err != nil {
+			sh.eprintln("Error:", err)
+			return
+		}
+	}
+
+	if err := printStructured(sh, value, output); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
+
+// batchAnswer asks every question of a file and writes the results as JSONL or CSV. Progress
+// is shown only when the results don't go to stdout.
+func (c *AnswerCommand) batchAnswer(sh *Shell, agentUUID, agentName string, temperature *float64, maxTokens *int, chatContext map[string]any, opts batchOptions) {
+	questions, err := readBatchQuestions(opts.file)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	format, err := batchFormat(opts.format, opts.out)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	var previous []batchResult
+	if opts.compare != "" {
+		if previous, err = readBatchResults(opts.compare); err != nil {
+			sh.eprintln("Error reading previous results:", err)
+			return
+		}
+	}
+
+	// With --compare and no --out, only the comparison is printed
+	var out io.Writer
+	switch {
+	case opts.out != "":
+		file, err := os.Create(opts.out)
+		if err != nil {
+			sh.eprintln("Error:", err)
+			return
+		}
+		defer file.Close()
+		out = file
+	case opts.compare == "":
+		out = sh.out
+	}
+
+	var progress func(done, total int, result batchResult)
+	if out != sh.out {
+		sh.printf("Asking %s %d question(s)", agentName, len(questions))
+		if opts.repeat > 1 {
+			sh.printf(" %d times each", opts.repeat)
+		}
+		sh.println("...")
+		progress = func(done, total int, result batchResult) {
+			printBatchProgress(sh, done, total, result)
+		}
+	}
+
+	ask := func(question string) (string, error) {
+		chatHistory, err := sh.client.AnswerFromAgentStream(agentUUID, question, temperature, maxTokens, chatContext, nil)
+		if err != nil {
+			return "", err
+		}
+		return responseText(chatHistory), nil
+	}
+	results := runBatch(questions, opts.repeat, opts.concurrency, ask, progress)
+
+	if out != nil {
+		if err := writeBatchResults(out, results, format); err != nil {
+			sh.eprintln("Error writing results:", err)
+			return
+		}
+	}
+
+	if out != sh.out {
+		failed := 0
+		for _, result := range results {
+			if result.Error != "" {
+				failed++
+			}
+		}
+		sh.printf("✓ %d answers, %d failed", len(results), failed)
+		if opts.out != "" {
+			sh.printf(", written to %s", opts.out)
+		}
+		sh.println()
+	}
+
+	if opts.compare != "" {
+		sh.println()
+		changes, added, removed := compareBatchResults(previous, results)
+		printBatchComparison(sh, changes, added, removed, len(results))
+	}
+}
+
+// printBatchProgress prints a line for each question of a batch as it completes
+func printBatchProgress(sh *Shell, done, total int, result batchResult) {
+	question := result.Question
+	if utf8.RuneCountInString(question) > 60 {
+		question = string([]rune(question)[:57]) + "..."
+	}
+
+	if result.Error != "" {
+		sh.printf("  [%d/%d] ✗ %s: %s\n", done, total, question, result.Error)
+		return
+	}
+	sh.printf("  [%d/%d] ✓ %s (%.1fs)\n", done, total, question, float64(result.LatencyMs)/1000)
 }
 
-func (c *AnswerCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	text := d.TextBeforeCursor()
-	args := strings.Fields(text)
+// findAgent returns an agent from the cache, or fetches it from the server
+func findAgent(sh *Shell, uuid string) *antbox.Agent {
+	for _, agent := range sh.GetCachedAgents() {
+		if agent.UUID == uuid {
+			return &agent
+		}
+	}
+
+	agent, err := sh.client.GetAgent(uuid)
+	if err != nil {
+		return nil
+	}
+	return agent
+}
+
+func (c *AnswerCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := fieldsBeforeCursor(d)
 
 	if len(args) == 0 {
 		return []prompt.Suggest{}
@@ -153,7 +411,7 @@ func (c *AnswerCommand) Suggest(d prompt.Document) []prompt.Suggest {
 	}
 
 	// Adjust for current typing
-	if !strings.HasSuffix(text, " ") && len(args) > 1 {
+	if !cursorAfterBlank(d) && len(args) > 1 {
 		if strings.HasPrefix(lastArg, "-") {
 			// Currently typing a flag
 		} else if argCount > 0 {
@@ -161,20 +419,35 @@ func (c *AnswerCommand) Suggest(d prompt.Document) []prompt.Suggest {
 		}
 	}
 
+	if suggests, ok := suggestAttachmentFlag(sh, d); ok {
+		return suggests
+	}
+
 	// Suggest flags if we're typing a flag
 	if strings.HasPrefix(lastArg, "-") {
 		return []prompt.Suggest{
 			{Text: "-t", Description: "Set temperature (0.0-1.0)"},
 			{Text: "-m", Description: "Set max tokens"},
+			{Text: "--trace", Description: "Show tool calls and reasoning"},
+			{Text: "-o", Description: "Output format of structured answers (json, table)"},
+			{Text: "--field", Description: "Print a field of a structured answer"},
+			{Text: "--node", Description: "Attach a node as context"},
+			{Text: "--file", Description: "Attach a local file as context"},
+			{Text: "--batch", Description: "Ask each question of a file"},
+			{Text: "--concurrency", Description: "Questions of a batch asked at the same time"},
+			{Text: "--repeat", Description: "Ask each question of a batch n times"},
+			{Text: "--out", Description: "Write batch results to a file"},
+			{Text: "--format", Description: "Batch results format (jsonl, csv)"},
+			{Text: "--compare", Description: "Compare batch results with a previous run"},
 		}
 	}
 
 	// Suggest agent UUID if we haven't specified one yet
 	if argCount == 0 {
 		// Use cached agents
-		agents := GetCachedAgents()
+		agents := sh.GetCachedAgents()
 		var suggests []prompt.Suggest
-		currentWord := d.GetWordBeforeCursor()
+		currentWord := wordBeforeCursor(d)
 		for _, agent := range agents {
 			if strings.HasPrefix(strings.ToLower(agent.UUID), strings.ToLower(currentWord)) ||
 				strings.HasPrefix(strings.ToLower(agent.Title), strings.ToLower(currentWord)) {
@@ -0,0 +1,528 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
+)
+
+type AspectsCommand struct{}
+
+func (c *AspectsCommand) GetName() string {
+	return "aspects"
+}
+
+func (c *AspectsCommand) GetDescription() string {
+	return "List, inspect, export, delete and create aspects"
+}
+
+func (c *AspectsCommand) Execute(sh *Shell, args []string) {
+	if len(args) == 0 {
+		c.listAspects(sh)
+		return
+	}
+
+	subcommand := args[0]
+	switch subcommand {
+	case "list":
+		c.listAspects(sh)
+	case "show":
+		if len(args) < 2 {
+			sh.println("Usage: aspects show <uuid>")
+			return
+		}
+		c.showAspect(sh, args[1])
+	case "export":
+		if len(args) < 2 {
+			sh.println("Usage: aspects export <uuid> [file]")
+			return
+		}
+		outputPath := ""
+		if len(args) > 2 {
+			outputPath = strings.Join(args[2:], " ")
+		}
+		c.exportAspect(sh, args[1], outputPath)
+	case "rm":
+		if len(args) < 2 {
+			sh.println("Usage: aspects rm <uuid>")
+			return
+		}
+		c.removeAspect(sh, args[1])
+	case "new":
+		c.newAspect(sh)
+	case "help", "-h":
+		c.showUsage(sh)
+	default:
+		sh.printf("Unknown subcommand: %s\n", subcommand)
+		c.showUsage(sh)
+	}
+}
+
+func (c *AspectsCommand) showUsage(sh *Shell) {
+	sh.println("Usage: aspects <subcommand> [args]")
+	sh.println()
+	sh.println("Subcommands:")
+	sh.println("  list                  List all aspects (default)")
+	sh.println("  show <uuid>           Show aspect details, filters and property schema")
+	sh.println("  export <uuid> [file]  Export the aspect definition as JSON")
+	sh.println("  rm <uuid>             Delete an aspect")
+	sh.println("  new                   Create an aspect with an interactive wizard")
+	sh.println()
+	sh.println("Examples:")
+	sh.println("  aspects")
+	sh.println("  aspects show invoice")
+	sh.println("  aspects export invoice ~/invoice.json")
+	sh.println("  aspects new")
+}
+
+func (c *AspectsCommand) listAspects(sh *Shell) {
+	aspects, err := sh.client.ListAspects()
+	if err != nil {
+		sh.eprintln("Error listing aspects:", err)
+		return
+	}
+
+	// Keep the completion cache in sync with what we just fetched
+	sh.updateResources(func() { sh.aspects = aspects })
+
+	if len(aspects) == 0 {
+		sh.println("No aspects available.")
+		return
+	}
+
+	sort.Slice(aspects, func(i, j int) bool {
+		return strings.ToLower(aspects[i].Title) < strings.ToLower(aspects[j].Title)
+	})
+
+	sh.printf("Available aspects (%d):\n", len(aspects))
+	sh.println()
+
+	for _, aspect := range aspects {
+		sh.printf("UUID: %s\n", aspect.UUID)
+		sh.printf("  Title: %s\n", aspect.Title)
+		if aspect.Description != "" {
+			sh.printf("  Description: %s\n", aspect.Description)
+		}
+		sh.printf("  Properties: %d\n", len(aspect.Properties))
+		if aspect.Filters != nil {
+			sh.printf("  Node Filtering: enabled\n")
+		}
+		sh.println()
+	}
+}
+
+func (c *AspectsCommand) showAspect(sh *Shell, uuid string) {
+	aspect, err := sh.client.GetAspect(uuid)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	template := "%-12s: %s\n"
+	sh.printf(template, "UUID", aspect.UUID)
+	sh.printf(template, "Title", aspect.Title)
+	if aspect.Description != "" {
+		sh.printf(template, "Description", aspect.Description)
+	}
+	if aspect.Owner != "" {
+		sh.printf(template, "Owner", aspect.Owner)
+	}
+
+	if aspect.Filters != nil {
+		filters, err := json.Marshal(aspect.Filters)
+		if err == nil {
+			sh.printf(template, "Filters", string(filters))
+		}
+	}
+
+	if len(aspect.Properties) == 0 {
+		sh.printf(template, "Properties", "none")
+		return
+	}
+
+	sh.printf(template, "Properties", fmt.Sprintf("%d", len(aspect.Properties)))
+	for _, property := range aspect.Properties {
+		printAspectProperty(sh, property)
+	}
+}
+
+// printAspectProperty prints a single aspect property with its type and validation rules
+func printAspectProperty(sh *Shell, property antbox.AspectProperty) {
+	propertyType := property.Type
+	if property.Type == "array" && property.ArrayType != "" {
+		propertyType = fmt.Sprintf("array<%s>", property.ArrayType)
+	}
+	if property.Type == "string" && property.StringMimetype != "" {
+		propertyType = fmt.Sprintf("string (%s)", property.StringMimetype)
+	}
+
+	var flags []string
+	if property.Required {
+		flags = append(flags, "required")
+	}
+	if property.Readonly {
+		flags = append(flags, "readonly")
+	}
+	if property.Searchable {
+		flags = append(flags, "searchable")
+	}
+
+	sh.println()
+	sh.printf("  - %s (%s)", property.Name, propertyType)
+	if len(flags) > 0 {
+		sh.printf(" [%s]", strings.Join(flags, ", "))
+	}
+	sh.println()
+
+	if property.Title != "" {
+		sh.printf("      Title: %s\n", property.Title)
+	}
+	if property.Description != "" {
+		sh.printf("      Description: %s\n", property.Description)
+	}
+	if property.ValidationRegex != "" {
+		sh.printf("      Validation regex: %s\n", property.ValidationRegex)
+	}
+	if len(property.ValidationList) > 0 {
+		sh.printf("      Allowed values: %s\n", strings.Join(property.ValidationList, ", "))
+	}
+	if len(property.ValidationFilters) > 0 {
+		if filters, err := json.Marshal(property.ValidationFilters); err == nil {
+			sh.printf("      Validation filters: %s\n", string(filters))
+		}
+	}
+	if property.Default != nil {
+		sh.printf("      Default: %v\n", property.Default)
+	}
+}
+
+func (c *AspectsCommand) exportAspect(sh *Shell, uuid string, outputPath string) {
+	exported, err := sh.client.ExportAspect(uuid, "")
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	data, err := json.MarshalIndent(exported, "", "  ")
+	if err != nil {
+		sh.eprintln("Error formatting aspect:", err)
+		return
+	}
+
+	if outputPath == "" {
+		sh.println(string(data))
+		return
+	}
+
+	if err := os.WriteFile(outputPath, append(data, '\n'), 0644); err != nil {
+		sh.eprintln("Error writing file:", err)
+		return
+	}
+
+	sh.printf("Aspect %s exported to %s\n", uuid, outputPath)
+}
+
+func (c *AspectsCommand) removeAspect(sh *Shell, uuid string) {
+	if err := sh.client.DeleteAspect(uuid); err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	// Drop the aspect from the completion cache
+	sh.updateResources(func() {
+		sh.aspects = slices.DeleteFunc(sh.aspects, func(a antbox.Aspect) bool {
+			return a.UUID == uuid
+		})
+	})
+
+	sh.printf("Aspect %s removed successfully\n", uuid)
+}
+
+// newAspect walks the user through building an aspect definition and uploads it
+func (c *AspectsCommand) newAspect(sh *Shell) {
+	sh.println("Create a new aspect (press Enter to accept defaults)")
+	sh.println()
+
+	var definition antbox.AspectCreate
+
+	definition.Title = sh.readInput("Title", "")
+	if definition.Title == "" {
+		sh.eprintln("Error: title is required")
+		return
+	}
+
+	definition.UUID = sh.readInput("UUID", slugify(definition.Title))
+	definition.Description = sh.readInput("Description", "")
+
+	for {
+		filterText := sh.readInput("Node filters (e.g. mimetype == application/pdf, leave empty for none)", "")
+		if filterText == "" {
+			break
+		}
+		filters, err := parseFilterConditions(filterText)
+		if err != nil {
+			sh.eprintln("Error:", err)
+			continue
+		}
+		definition.Filters = filters
+		break
+	}
+
+	sh.println()
+	sh.println("Define the properties (leave the name empty to finish)")
+	for {
+		sh.println()
+		property, ok := readAspectProperty(sh, definition.Properties)
+		if !ok {
+			break
+		}
+		definition.Properties = append(definition.Properties, property)
+	}
+
+	data, err := json.MarshalIndent(definition, "", "  ")
+	if err != nil {
+		sh.eprintln("Error formatting aspect:", err)
+		return
+	}
+
+	sh.println()
+	sh.println(string(data))
+	sh.println()
+
+	if !sh.confirm("Upload this aspect?") {
+		sh.println("Aspect creation cancelled.")
+		return
+	}
+
+	tmpFile, err := os.CreateTemp("", "antx-aspect-*.json")
+	if err != nil {
+		sh.eprintln("Error creating temporary file:", err)
+		return
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		sh.eprintln("Error writing temporary file:", err)
+		return
+	}
+	tmpFile.Close()
+
+	aspect, err := sh.client.UploadAspect(tmpFile.Name())
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	sh.updateResources(func() { sh.aspects = append(sh.aspects, *aspect) })
+	sh.printf("Aspect '%s' created successfully with UUID %s\n", aspect.Title, aspect.UUID)
+}
+
+var propertyNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// readAspectProperty reads a property definition from stdin. It returns false when the user is done.
+func readAspectProperty(sh *Shell, existing antbox.AspectProperties) (antbox.AspectProperty, bool) {
+	var property antbox.AspectProperty
+
+	for {
+		property.Name = sh.readInput("Property name", "")
+		if property.Name == "" {
+			return property, false
+		}
+		if !propertyNamePattern.MatchString(property.Name) {
+			sh.eprintln("Error: names must start with a letter or '_' and contain only letters, digits and '_'")
+			continue
+		}
+		if slices.ContainsFunc(existing, func(p antbox.AspectProperty) bool { return p.Name == property.Name }) {
+			sh.eprintf("Error: property '%s' is already defined\n", property.Name)
+			continue
+		}
+		break
+	}
+
+	property.Title = sh.readInput("  Title", property.Name)
+
+	for {
+		property.Type = sh.readInput(fmt.Sprintf("  Type (%s)", strings.Join(antbox.AspectPropertyTypes, "|")), "string")
+		if slices.Contains(antbox.AspectPropertyTypes, property.Type) {
+			break
+		}
+		sh.eprintf("Error: unknown type '%s'\n", property.Type)
+	}
+
+	if property.Type == "array" {
+		for {
+			property.ArrayType = sh.readInput(fmt.Sprintf("  Array item type (%s)", strings.Join(antbox.AspectPropertyArrayTypes, "|")), "string")
+			if slices.Contains(antbox.AspectPropertyArrayTypes, property.ArrayType) {
+				break
+			}
+			sh.eprintf("Error: unknown array type '%s'\n", property.ArrayType)
+		}
+	}
+
+	property.Description = sh.readInput("  Description", "")
+	property.Required = sh.confirm("  Required?")
+	property.Searchable = sh.confirm("  Searchable?")
+	property.Readonly = sh.confirm("  Readonly?")
+
+	if property.Type == "string" {
+		for {
+			property.ValidationRegex = sh.readInput("  Validation regex (optional)", "")
+			if property.ValidationRegex == "" {
+				break
+			}
+			if _, err := regexp.Compile(property.ValidationRegex); err != nil {
+				sh.eprintln("Error: invalid regex:", err)
+				continue
+			}
+			break
+		}
+	}
+
+	if property.Type == "string" || property.Type == "number" || property.Type == "array" {
+		if list := sh.readInput("  Allowed values, comma separated (optional)", ""); list != "" {
+			for _, value := range strings.Split(list, ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					property.ValidationList = append(property.ValidationList, value)
+				}
+			}
+		}
+	}
+
+	for {
+		defaultValue := sh.readInput("  Default value (optional)", "")
+		if defaultValue == "" {
+			break
+		}
+		value, err := convertPropertyDefault(property, defaultValue)
+		if err != nil {
+			sh.eprintln("Error:", err)
+			continue
+		}
+		property.Default = value
+		break
+	}
+
+	return property, true
+}
+
+// convertPropertyDefault converts a default value to the type declared by the property
+func convertPropertyDefault(property antbox.AspectProperty, raw string) (any, error) {
+	switch property.Type {
+	case "uuid", "file":
+		// Defaults refer to existing nodes, not to local files
+		if !nodeUUIDPattern.MatchString(raw) {
+			return nil, fmt.Errorf("'%s' is not a node UUID", raw)
+		}
+		return raw, nil
+	default:
+		return coerceParameterValue(antbox.Parameter{Name: property.Name, Type: property.Type}, raw)
+	}
+}
+
+// parseFilterConditions parses comma separated "field operator value" conditions
+func parseFilterConditions(text string) (antbox.NodeFilters1D, error) {
+	var filters antbox.NodeFilters1D
+
+	for _, condition := range strings.Split(text, ",") {
+		condition = strings.TrimSpace(condition)
+		if condition == "" {
+			continue
+		}
+
+		filter := extractSingleFilter(normalizeOperators(condition))[0]
+		if field, _ := filter[0].(string); field == ":content" {
+			return nil, fmt.Errorf("invalid condition '%s' (expected: field operator value)", condition)
+		}
+		filter[2] = convertValue(fmt.Sprintf("%v", filter[2]))
+		filters = append(filters, filter)
+	}
+
+	if len(filters) == 0 {
+		return nil, fmt.Errorf("no filter conditions given")
+	}
+
+	return filters, nil
+}
+
+// slugify turns a title into a lowercase identifier suitable for a UUID
+func slugify(title string) string {
+	var result strings.Builder
+	lastDash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(title)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			result.WriteRune(r)
+			lastDash = false
+		case !lastDash && result.Len() > 0:
+			result.WriteRune('-')
+			lastDash = true
+		}
+	}
+	return strings.TrimSuffix(result.String(), "-")
+}
+
+func (c *AspectsCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := fieldsBeforeCursor(d)
+
+	if len(args) == 0 {
+		return []prompt.Suggest{}
+	}
+
+	// Count actual arguments (excluding the command name)
+	argCount := len(args) - 1
+	if !cursorAfterBlank(d) && len(args) > 1 {
+		argCount = len(args) - 2 // We're still typing the current argument
+	}
+
+	currentWord := wordBeforeCursor(d)
+
+	switch argCount {
+	case 0:
+		subcommands := []prompt.Suggest{
+			{Text: "list", Description: "List all aspects"},
+			{Text: "show", Description: "Show aspect details and properties"},
+			{Text: "export", Description: "Export the aspect definition as JSON"},
+			{Text: "rm", Description: "Delete an aspect"},
+			{Text: "new", Description: "Create an aspect interactively"},
+		}
+
+		var filtered []prompt.Suggest
+		for _, cmd := range subcommands {
+			if strings.HasPrefix(strings.ToLower(cmd.Text), strings.ToLower(currentWord)) {
+				filtered = append(filtered, cmd)
+			}
+		}
+		return filtered
+	case 1:
+		subcommand := args[1]
+		if subcommand != "show" && subcommand != "export" && subcommand != "rm" {
+			return []prompt.Suggest{}
+		}
+
+		var suggests []prompt.Suggest
+		for _, aspect := range sh.GetCachedAspects() {
+			if strings.HasPrefix(strings.ToLower(aspect.UUID), strings.ToLower(currentWord)) ||
+				strings.HasPrefix(strings.ToLower(aspect.Title), strings.ToLower(currentWord)) {
+				suggests = append(suggests, prompt.Suggest{
+					Text:        aspect.UUID,
+					Description: aspect.Title,
+				})
+			}
+		}
+		return suggests
+	}
+
+	return []prompt.Suggest{}
+}
+
+func init() {
+	RegisterCommand(&AspectsCommand{})
+}
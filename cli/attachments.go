@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
+)
+
+// chatAttachment is a node given to an agent as context of a chat or an answer
+type chatAttachment struct {
+	UUID     string
+	Title    string
+	Mimetype string
+	// Temporary attachments were uploaded from local files and are removed when no longer needed
+	Temporary bool
+}
+
+// attachNode attaches an existing node
+func attachNode(sh *Shell, uuid string) (chatAttachment, error) {
+	node, err := sh.client.GetNode(uuid)
+	if err != nil {
+		return chatAttachment{}, fmt.Errorf("node %s: %w", uuid, err)
+	}
+	return chatAttachment{UUID: node.UUID, Title: node.Title, Mimetype: node.Mimetype}, nil
+}
+
+// attachFile uploads a local file to the current folder as a temporary node and attaches it
+func attachFile(sh *Shell, path string) (chatAttachment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return chatAttachment{}, err
+	}
+	if info.IsDir() {
+		return chatAttachment{}, fmt.Errorf("%s is a directory", path)
+	}
+
+	// The client detects the real mimetype of the uploaded file
+	metadata := antbox.NodeCreate{
+		Title:    filepath.Base(path),
+		Mimetype: "application/octet-stream",
+		Parent:   sh.getCurrentNode().UUID,
+	}
+	node, err := sh.client.CreateFile(path, metadata)
+	if err != nil {
+		return chatAttachment{}, fmt.Errorf("uploading %s: %w", path, err)
+	}
+
+	sh.printf("Uploaded %s as temporary node %s\n", path, node.UUID)
+	return chatAttachment{UUID: node.UUID, Title: node.Title, Mimetype: node.Mimetype, Temporary: true}, nil
+}
+
+// attachReference attaches a local file if ref is one, otherwise the node with UUID ref
+func attachReference(sh *Shell, ref string) (chatAttachment, error) {
+	if info, err := os.Stat(ref); err == nil && !info.IsDir() {
+		return attachFile(sh, ref)
+	}
+	return attachNode(sh, ref)
+}
+
+// resolveAttachments attaches the given nodes and files. If one of them fails, the files
+// already uploaded are removed.
+func resolveAttachments(sh *Shell, nodes, files []string) ([]chatAttachment, error) {
+	var attachments []chatAttachment
+	for _, uuid := range nodes {
+		attachment, err := attachNode(sh, uuid)
+		if err != nil {
+			return nil, err
+		}
+		attachments = addAttachment(attachments, attachment)
+	}
+	for _, path := range files {
+		attachment, err := attachFile(sh, path)
+		if err != nil {
+			removeTemporaryAttachments(sh, attachments)
+			return nil, err
+		}
+		attachments = addAttachment(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// addAttachment adds an attachment unless a node with the same UUID is already attached
+func addAttachment(attachments []chatAttachment, attachment chatAttachment) []chatAttachment {
+	if slices.ContainsFunc(attachments, func(a chatAttachment) bool { return a.UUID == attachment.UUID }) {
+		return attachments
+	}
+	return append(attachments, attachment)
+}
+
+// removeTemporaryAttachments removes the nodes uploaded for temporary attachments
+func removeTemporaryAttachments(sh *Shell, attachments []chatAttachment) {
+	for _, attachment := range attachments {
+		if !attachment.Temporary {
+			continue
+		}
+		if err := sh.client.RemoveNode(attachment.UUID); err != nil {
+			sh.eprintf("Warning: could not remove temporary node %s: %v\n", attachment.UUID, err)
+		}
+	}
+}
+
+// attachmentContext returns the chat context referencing the attached nodes, or nil when
+// nothing is attached
+func attachmentContext(attachments []chatAttachment) map[string]any {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	nodes := make([]map[string]any, len(attachments))
+	for i, attachment := range attachments {
+		nodes[i] = map[string]any{
+			"uuid":     attachment.UUID,
+			"title":    attachment.Title,
+			"mimetype": attachment.Mimetype,
+		}
+	}
+	return map[string]any{"attachments": nodes}
+}
+
+// attachmentsToOptions returns the attachments kept with a saved session. Temporary
+// attachments are removed when the session ends, so they aren't kept.
+func attachmentsToOptions(attachments []chatAttachment) []any {
+	var saved []any
+	for _, attachment := range attachments {
+		if attachment.Temporary {
+			continue
+		}
+		saved = append(saved, map[string]any{
+			"uuid":     attachment.UUID,
+			"title":    attachment.Title,
+			"mimetype": attachment.Mimetype,
+		})
+	}
+	return saved
+}
+
+// attachmentsFromOptions returns the attachments saved with a session
+func attachmentsFromOptions(options map[string]any) []chatAttachment {
+	saved, _ := options["attachments"].([]any)
+
+	var attachments []chatAttachment
+	for _, item := range saved {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		uuid, _ := fields["uuid"].(string)
+		if uuid == "" {
+			continue
+		}
+		title, _ := fields["title"].(string)
+		mimetype, _ := fields["mimetype"].(string)
+		attachments = append(attachments, chatAttachment{UUID: uuid, Title: title, Mimetype: mimetype})
+	}
+	return attachments
+}
+
+// printAttachments prints the active attachments
+func printAttachments(sh *Shell, attachments []chatAttachment) {
+	if len(attachments) == 0 {
+		sh.println("No attachments.")
+		return
+	}
+
+	sh.println("Attachments:")
+	for _, attachment := range attachments {
+		temporary := ""
+		if attachment.Temporary {
+			temporary = " (temporary)"
+		}
+		sh.printf("  %s  %s [%s]%s\n", attachment.UUID, attachment.Title, attachment.Mimetype, temporary)
+	}
+}
+
+// suggestAttachmentFlag suggests nodes after --node and local files after --file. The second
+// result reports whether the cursor is on the value of one of those flags.
+func suggestAttachmentFlag(sh *Shell, d prompt.Document) ([]prompt.Suggest, bool) {
+	args := fieldsBeforeCursor(d)
+
+	flag, word := "", ""
+	switch {
+	case cursorAfterBlank(d) && len(args) > 0:
+		flag = args[len(args)-1]
+	case !cursorAfterBlank(d) && len(args) > 1:
+		flag, word = args[len(args)-2], args[len(args)-1]
+	}
+
+	switch flag {
+	case "--node":
+		return getNodeSuggestions(sh, word, func(node antbox.Node) bool { return !folderFilter(node) }), true
+	case "--file":
+		return getFileSystemSuggestions(word), true
+	}
+	return nil, false
+}
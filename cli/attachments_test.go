@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveAttachments(t *testing.T) {
+	sh := newTestShell(&mockClient{})
+
+	path := filepath.Join(t.TempDir(), "notes.json")
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	attachments, err := resolveAttachments(sh, []string{"test-uuid", "test-uuid"}, []string{path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(attachments) != 2 {
+		t.Fatalf("Expected the repeated node to be attached once, got %+v", attachments)
+	}
+	if attachments[0].UUID != "test-uuid" || attachments[0].Temporary {
+		t.Errorf("Expected the node attachment first, got %+v", attachments[0])
+	}
+	if attachments[1].UUID != "uploaded-uuid" || !attachments[1].Temporary {
+		t.Errorf("Expected a temporary attachment, got %+v", attachments[1])
+	}
+
+	if _, err := resolveAttachments(sh, nil, []string{t.TempDir()}); err == nil {
+		t.Error("Expected an error when attaching a directory")
+	}
+}
+
+func TestAttachmentContext(t *testing.T) {
+	if attachmentContext(nil) != nil {
+		t.Error("Expected no context without attachments")
+	}
+
+	context := attachmentContext([]chatAttachment{{UUID: "a", Title: "A", Mimetype: "application/pdf"}})
+	nodes, ok := context["attachments"].([]map[string]any)
+	if !ok || len(nodes) != 1 || nodes[0]["uuid"] != "a" || nodes[0]["mimetype"] != "application/pdf" {
+		t.Errorf("Unexpected context: %v", context)
+	}
+}
+
+func TestAttachmentsSavedWithSession(t *testing.T) {
+	attachments := []chatAttachment{
+		{UUID: "a", Title: "A", Mimetype: "text/plain"},
+		{UUID: "b", Title: "B", Mimetype: "text/plain", Temporary: true},
+	}
+
+	// Options are read back from disk as decoded JSON
+	data, err := json.Marshal(chatSessionOptions(nil, nil, attachments))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var options map[string]any
+	if err := json.Unmarshal(data, &options); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := attachmentsFromOptions(options)
+	if len(restored) != 1 || restored[0] != attachments[0] {
+		t.Errorf("Expected only the node attachment to be kept, got %+v", restored)
+	}
+}
@@ -0,0 +1,381 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// defaultBatchConcurrency is the number of questions of a batch asked at the same time
+const defaultBatchConcurrency = 4
+
+// batchResult is the result of asking one question of a batch, as written to JSONL and CSV files
+type batchResult struct {
+	Question   string `json:"question"`
+	Repetition int    `json:"repetition"`
+	Answer     string `json:"answer"`
+	LatencyMs  int64  `json:"latencyMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// outcome returns the answer, or the error when the question failed
+func (r batchResult) outcome() string {
+	if r.Error != "" {
+		return "Error: " + r.Error
+	}
+	return r.Answer
+}
+
+// batchOptions are the options of answer --batch
+type batchOptions struct {
+	file        string
+	out         string
+	format      string
+	compare     string
+	concurrency int
+	repeat      int
+}
+
+// readBatchQuestions reads one question per line, skipping blank lines and # comments
+func readBatchQuestions(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var questions []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		questions = append(questions, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("no questions in %s", path)
+	}
+	return questions, nil
+}
+
+// runBatch asks every question repeat times, with at most concurrency questions at a time.
+// Results are returned in question order; progress is called as each one completes.
+func runBatch(questions []string, repeat, concurrency int, ask func(question string) (string, error), progress func(done, total int, result batchResult)) []batchResult {
+	repeat = max(repeat, 1)
+	concurrency = max(concurrency, 1)
+
+	results := make([]batchResult, 0, len(questions)*repeat)
+	for _, question := range questions {
+		for repetition := 1; repetition <= repeat; repetition++ {
+			results = append(results, batchResult{Question: question, Repetition: repetition})
+		}
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+	slots := make(chan struct{}, concurrency)
+
+	for i := range results {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(result *batchResult) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			start := time.Now()
+			answer, err := ask(result.Question)
+			result.LatencyMs = time.Since(start).Milliseconds()
+			result.Answer = answer
+			if err != nil {
+				result.Error = err.Error()
+			}
+
+			if progress != nil {
+				mu.Lock()
+				done++
+				progress(done, len(results), *result)
+				mu.Unlock()
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+
+	return results
+}
+
+// batchFormat returns the format of a results file: the given one, or csv for .csv files
+// and jsonl for any other
+func batchFormat(format, path string) (string, error) {
+	switch format {
+	case "jsonl", "csv":
+		return format, nil
+	case "":
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			return "csv", nil
+		}
+		return "jsonl", nil
+	default:
+		return "", fmt.Errorf("unknown batch format: %s (use jsonl or csv)", format)
+	}
+}
+
+var batchCSVHeader = []string{"question", "repetition", "answer", "latency_ms", "error"}
+
+// writeBatchResults writes results as JSONL, one result per line, or as CSV with a header
+func writeBatchResults(w io.Writer, results []batchResult, format string) error {
+	if format == "csv" {
+		writer := csv.NewWriter(w)
+		if err := writer.Write(batchCSVHeader); err != nil {
+			return err
+		}
+		for _, r := range results {
+			record := []string{r.Question, strconv.Itoa(r.Repetition), r.Answer, strconv.FormatInt(r.LatencyMs, 10), r.Error}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	encoder := json.NewEncoder(w)
+	for _, r := range results {
+		if err := encoder.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readBatchResults reads the results of a previous batch from a JSONL or CSV file
+func readBatchResults(path string) ([]batchResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	format, _ := batchFormat("", path)
+	if format == "csv" {
+		return readBatchCSV(file)
+	}
+
+	var results []batchResult
+	decoder := json.NewDecoder(file)
+	for {
+		var result batchResult
+		if err := decoder.Decode(&result); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: result %d: %v", path, len(results)+1, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func readBatchCSV(r io.Reader) ([]batchResult, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var results []batchResult
+	for _, record := range records[1:] {
+		repetition, _ := strconv.Atoi(field(record, "repetition"))
+		latency, _ := strconv.ParseInt(field(record, "latency_ms"), 10, 64)
+		results = append(results, batchResult{
+			Question:   field(record, "question"),
+			Repetition: max(repetition, 1),
+			Answer:     field(record, "answer"),
+			LatencyMs:  latency,
+			Error:      field(record, "error"),
+		})
+	}
+	return results, nil
+}
+
+// batchChange is a question whose answer changed between two batches
+type batchChange struct {
+	Question   string
+	Repetition int
+	Previous   string
+	Current    string
+}
+
+// compareBatchResults returns the answers that changed from previous to current, matching
+// results by question and repetition, and counts the questions only one of them has
+func compareBatchResults(previous, current []batchResult) (changes []batchChange, added, removed int) {
+	type key struct {
+		question   string
+		repetition int
+	}
+
+	before := make(map[key]batchResult, len(previous))
+	for _, r := range previous {
+		before[key{r.Question, max(r.Repetition, 1)}] = r
+	}
+
+	seen := make(map[key]bool, len(current))
+	for _, r := range current {
+		k := key{r.Question, max(r.Repetition, 1)}
+		seen[k] = true
+
+		old, ok := before[k]
+		if !ok {
+			added++
+			continue
+		}
+		if strings.TrimSpace(old.outcome()) != strings.TrimSpace(r.outcome()) {
+			changes = append(changes, batchChange{Question: r.Question, Repetition: k.repetition, Previous: old.outcome(), Current: r.outcome()})
+		}
+	}
+
+	for k := range before {
+		if !seen[k] {
+			removed++
+		}
+	}
+	return changes, added, removed
+}
+
+// printBatchComparison prints the changed answers side by side, previous on the left
+func printBatchComparison(sh *Shell, changes []batchChange, added, removed, total int) {
+	width := terminalWidth()
+	if width <= 0 {
+		width = 120
+	}
+
+	for _, change := range changes {
+		title := change.Question
+		if change.Repetition > 1 {
+			title = fmt.Sprintf("%s (#%d)", title, change.Repetition)
+		}
+		sh.printf("● %s\n", title)
+		for _, line := range sideBySide(change.Previous, change.Current, width) {
+			sh.println(line)
+		}
+		sh.println()
+	}
+
+	sh.printf("%d of %d answers changed", len(changes), total)
+	if added > 0 {
+		sh.printf(", %d new", added)
+	}
+	if removed > 0 {
+		sh.printf(", %d no longer asked", removed)
+	}
+	sh.println()
+}
+
+// sideBySide lays out a line diff of two texts in two columns that fit in width, marking
+// changed rows with "|", removed lines with "<" and added lines with ">"
+func sideBySide(previous, current string, width int) []string {
+	column := max((width-3)/2, 10)
+
+	type row struct {
+		left, right string
+		marker      string
+	}
+	var rows []row
+	var removed, added []string
+	flush := func() {
+		for i := 0; i < max(len(removed), len(added)); i++ {
+			r := row{marker: "|"}
+			switch {
+			case i >= len(added):
+				r.left, r.marker = removed[i], "<"
+			case i >= len(removed):
+				r.right, r.marker = added[i], ">"
+			default:
+				r.left, r.right = removed[i], added[i]
+			}
+			rows = append(rows, r)
+		}
+		removed, added = nil, nil
+	}
+
+	for _, line := range diffLines(strings.Split(previous, "\n"), strings.Split(current, "\n")) {
+		switch line[0] {
+		case '-':
+			removed = append(removed, line[2:])
+		case '+':
+			added = append(added, line[2:])
+		default:
+			flush()
+			rows = append(rows, row{left: line[2:], right: line[2:], marker: " "})
+		}
+	}
+	flush()
+
+	var lines []string
+	for _, r := range rows {
+		left, right := wrapText(r.left, column), wrapText(r.right, column)
+		for i := 0; i < max(len(left), len(right)); i++ {
+			var l, rt string
+			if i < len(left) {
+				l = left[i]
+			}
+			if i < len(right) {
+				rt = right[i]
+			}
+			pad := column - utf8.RuneCountInString(l)
+			lines = append(lines, strings.TrimRight(l+strings.Repeat(" ", pad)+" "+r.marker+" "+rt, " "))
+		}
+	}
+	return lines
+}
+
+// wrapText splits a line into pieces of at most width characters, breaking at spaces when possible
+func wrapText(text string, width int) []string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return []string{text}
+	}
+
+	var lines []string
+	for len(runes) > width {
+		cut := width
+		for i := width; i > width/2; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		lines = append(lines, strings.TrimRight(string(runes[:cut]), " "))
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " "))
+	}
+	if len(runes) > 0 {
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
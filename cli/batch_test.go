@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunBatch(t *testing.T) {
+	var running, peak atomic.Int32
+	ask := func(question string) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		if question == "fail" {
+			return "", fmt.Errorf("agent unavailable")
+		}
+		return "answer to " + question, nil
+	}
+
+	progressCalls := 0
+	results := runBatch([]string{"a", "fail", "b"}, 2, 2, ask, func(done, total int, result batchResult) {
+		progressCalls++
+		if total != 6 {
+			t.Errorf("Expected a total of 6, got %d", total)
+		}
+	})
+
+	if len(results) != 6 || progressCalls != 6 {
+		t.Fatalf("Expected 6 results and progress calls, got %d and %d", len(results), progressCalls)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("Expected at most 2 questions at a time, got %d", peak.Load())
+	}
+	if results[0].Question != "a" || results[1].Repetition != 2 || results[1].Answer != "answer to a" {
+		t.Errorf("Expected results in question order, got %+v", results[:2])
+	}
+	if results[2].Error != "agent unavailable" || results[2].Answer != "" {
+		t.Errorf("Expected the error to be recorded, got %+v", results[2])
+	}
+}
+
+func TestBatchResultsRoundTrip(t *testing.T) {
+	results := []batchResult{
+		{Question: "What, \"exactly\"?", Repetition: 1, Answer: "Line one\nLine two", LatencyMs: 1200},
+		{Question: "Broken", Repetition: 1, LatencyMs: 30, Error: "timeout"},
+	}
+
+	for _, name := range []string{"results.jsonl", "results.csv"} {
+		path := filepath.Join(t.TempDir(), name)
+		format, err := batchFormat("", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := writeBatchResults(&buf, results, format); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		read, err := readBatchResults(path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if len(read) != 2 || read[0] != results[0] || read[1] != results[1] {
+			t.Errorf("%s: results changed in a round trip: %+v", name, read)
+		}
+	}
+
+	if _, err := batchFormat("xml", "out.xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestCompareBatchResults(t *testing.T) {
+	previous := []batchResult{
+		{Question: "same", Repetition: 1, Answer: "yes"},
+		{Question: "changed", Repetition: 1, Answer: "old"},
+		{Question: "dropped", Repetition: 1, Answer: "x"},
+	}
+	current := []batchResult{
+		{Question: "same", Repetition: 1, Answer: "yes\n"},
+		{Question: "changed", Repetition: 1, Error: "timeout"},
+		{Question: "new", Repetition: 1, Answer: "y"},
+	}
+
+	changes, added, removed := compareBatchResults(previous, current)
+	if len(changes) != 1 || changes[0].Question != "changed" || changes[0].Current != "Error: timeout" {
+		t.Errorf("Expected one changed answer, got %+v", changes)
+	}
+	if added != 1 || removed != 1 {
+		t.Errorf("Expected 1 new and 1 removed question, got %d and %d", added, removed)
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	lines := sideBySide("same\nold line", "same\nnew line\nextra", 33)
+
+	want := []string{
+		"same              same",
+		"old line        | new line",
+		"                > extra",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+
+	for _, line := range sideBySide(strings.Repeat("word ", 20), "short", 43) {
+		if len([]rune(line)) > 43 {
+			t.Errorf("Expected long lines to wrap to the width, got %q", line)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+const (
+	// defaultCacheTTL is how long nodes and listings are cached unless cache-ttl is set
+	defaultCacheTTL = time.Minute
+	// cacheFileName is the file keeping the cache between runs when cache-persist is true
+	cacheFileName = ".antx_cache"
+)
+
+// cacheTTL returns the cache-ttl setting
+func (sh *Shell) cacheTTL() time.Duration {
+	if ttl, err := time.ParseDuration(sh.getSetting("cache-ttl")); err == nil {
+		return ttl
+	}
+	return defaultCacheTTL
+}
+
+// validateCacheTTL checks the value of the cache-ttl setting
+func validateCacheTTL(value string) error {
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s is not a duration such as 30s or 5m", value)
+	}
+	if ttl < 0 {
+		return fmt.Errorf("the ttl can't be negative")
+	}
+	return nil
+}
+
+// configureCache applies the cache settings, loading the saved cache if cache-persist is true
+func (sh *Shell) configureCache() {
+	if sh.cache == nil {
+		return
+	}
+	sh.cache.SetTTL(sh.cacheTTL())
+	if sh.getSetting("cache-persist") == "true" {
+		sh.loadPersistedCache()
+	}
+}
+
+// persistedCache is the content of the cache file
+type persistedCache struct {
+	Server   string               `json:"server"`
+	Identity string               `json:"identity"`
+	Cache    antbox.CacheSnapshot `json:"cache"`
+}
+
+// cacheIdentity identifies the credentials the client uses, so nodes fetched by one user
+// aren't shown to another. Only a hash is kept, never the credentials.
+func cacheIdentity(apiKey, root, jwt string) string {
+	credentials := "anonymous"
+	switch {
+	case root != "":
+		credentials = "root:" + root
+	case jwt != "":
+		credentials = "jwt:" + jwt
+	case apiKey != "":
+		credentials = "apikey:" + apiKey
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(credentials)))
+}
+
+func getCacheFilePath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, cacheFileName), nil
+}
+
+// loadPersistedCache restores the cache saved by a previous run against the same server with
+// the same credentials
+func (sh *Shell) loadPersistedCache() {
+	path, err := getCacheFilePath()
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	var saved persistedCache
+	if err := json.Unmarshal(data, &saved); err != nil || saved.Server != sh.server || saved.Identity != sh.identity {
+		return
+	}
+	sh.cache.Restore(saved.Cache)
+}
+
+// savePersistedCache saves the cache for the next run if cache-persist is true
+func (sh *Shell) savePersistedCache() {
+	if sh.cache == nil || !sh.persistent || sh.getSetting("cache-persist") != "true" {
+		return
+	}
+	path, err := getCacheFilePath()
+	if err != nil {
+		return
+	}
+
+	// Folders being prefetched are saved with the rest, the ones not yet requested aren't
+	sh.prefetcher.stop()
+	sh.prefetcher.wait()
+
+	data, err := json.Marshal(persistedCache{Server: sh.server, Identity: sh.identity, Cache: sh.cache.Snapshot()})
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		sh.eprintln("Warning: could not save the cache:", err)
+	}
+}
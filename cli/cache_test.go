@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+func TestPersistedCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// newShell returns a shell that keeps its state, like the one of Start
+	newShell := func(server, apiKey string) *Shell {
+		sh := newTestShell(antbox.NewCachedClient(&mockClient{}, defaultCacheTTL))
+		sh.server = server
+		sh.identity = cacheIdentity(apiKey, "", "")
+		sh.persistent = true
+		sh.restoreSettings(map[string]string{"cache-persist": "true", "cache-ttl": "5m"})
+		return sh
+	}
+
+	sh := newShell("http://localhost:7180", "key-1")
+	sh.client.ListNodes("--root--")
+	sh.savePersistedCache()
+
+	sh = newShell("http://localhost:7180", "key-1")
+	sh.configureCache()
+	if stats := sh.cache.Stats(); stats.Listings != 1 {
+		t.Errorf("Expected the saved listing to be restored, got %+v", stats)
+	}
+
+	// A cache saved for another server or another user isn't used
+	sh = newShell("http://example.com", "key-1")
+	sh.configureCache()
+	if stats := sh.cache.Stats(); stats.Listings != 0 {
+		t.Errorf("Expected the cache of another server to be ignored, got %+v", stats)
+	}
+	sh = newShell("http://localhost:7180", "key-2")
+	sh.configureCache()
+	if stats := sh.cache.Stats(); stats.Listings != 0 {
+		t.Errorf("Expected the cache of another API key to be ignored, got %+v", stats)
+	}
+
+	if cacheIdentity("", "secret-1", "") == cacheIdentity("", "secret-2", "") {
+		t.Error("Expected root logins with different passwords to have different identities")
+	}
+
+	if sh.cacheTTL() != 5*time.Minute {
+		t.Errorf("Expected a ttl of 5m, got %s", sh.cacheTTL())
+	}
+	if err := validateSetting("cache-ttl", "-1s"); err == nil {
+		t.Error("Expected an error for a negative ttl")
+	}
+	if err := validateSetting("cache-ttl", "soon"); err == nil {
+		t.Error("Expected an error for an invalid ttl")
+	}
+}
@@ -1,8 +1,6 @@
 package cli
 
 import (
-	"fmt"
-
 	prompt "github.com/c-bata/go-prompt"
 	"github.com/kindalus/antx/antbox"
 )
@@ -17,36 +15,37 @@ func (c *CdCommand) GetDescription() string {
 	return "Change directory"
 }
 
-func (c *CdCommand) Execute(args []string) {
+func (c *CdCommand) Execute(sh *Shell, args []string) {
 	if len(args) == 0 {
 		// Go to root
-		currentNode = antbox.Node{
+		sh.setCurrentNode(antbox.Node{
 			UUID:     "--root--",
 			Title:    "root",
 			Mimetype: "application/vnd.antbox.folder",
-		}
+		})
 	} else {
 		var targetUUID string
 
 		// Handle special case: ".." means navigate to parent (original behavior)
 		if args[0] == ".." {
-			if currentNode.UUID == "--root--" {
+			current := sh.getCurrentNode()
+			if current.UUID == "--root--" {
 				return // Already at root
 			}
-			if currentNode.Parent == "" || currentNode.Parent == "--root--" {
+			if current.Parent == "" || current.Parent == "--root--" {
 				// Parent is root
-				currentNode = antbox.Node{
+				sh.setCurrentNode(antbox.Node{
 					UUID:     "--root--",
 					Title:    "root",
 					Mimetype: "application/vnd.antbox.folder",
-				}
+				})
 				// List contents of new current folder
 				if cmd, ok := commands["ls"]; ok {
-					cmd.Execute([]string{})
+					cmd.Execute(sh, []string{})
 				}
 				return
 			} else {
-				targetUUID = currentNode.Parent
+				targetUUID = current.Parent
 			}
 		} else {
 			// For any other argument (including resolved aliases), treat as UUID to navigate to
@@ -54,22 +53,22 @@ func (c *CdCommand) Execute(args []string) {
 		}
 
 		// Get target node and navigate to it
-		node, err := client.GetNode(targetUUID)
+		node, err := sh.client.GetNode(targetUUID)
 		if err != nil {
-			fmt.Println("Error:", err)
+			sh.eprintln("Error:", err)
 			return
 		}
-		currentNode = *node
+		sh.setCurrentNode(*node)
 	}
 
 	// List contents of new current folder
 	if cmd, ok := commands["ls"]; ok {
-		cmd.Execute([]string{})
+		cmd.Execute(sh, []string{})
 	}
 }
 
-func (c *CdCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	return getNodeSuggestions(d.GetWordBeforeCursor(), folderFilter)
+func (c *CdCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	return getNodeSuggestions(sh, wordBeforeCursor(d), folderFilter)
 }
 
 func init() {
@@ -1,11 +1,18 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
 )
 
 type ChatCommand struct{}
@@ -18,56 +25,91 @@ func (c *ChatCommand) GetDescription() string {
 	return "Send message to specific agent"
 }
 
-func (c *ChatCommand) Execute(args []string) {
+func (c *ChatCommand) Execute(sh *Shell, args []string) {
 	if len(args) == 0 {
-		fmt.Println("Usage: chat [options] <agent_uuid> [message]")
-		fmt.Println("Options:")
-		fmt.Println("  -t <temperature>  Temperature for response generation (0.0-1.0)")
-		fmt.Println("  -m <max_tokens>   Maximum tokens in the response")
-		fmt.Println()
-		fmt.Println("Arguments:")
-		fmt.Println("  agent_uuid: UUID of the agent to chat with")
-		fmt.Println("  message: Initial message to send (optional)")
-		fmt.Println()
-		fmt.Println("Interactive mode:")
-		fmt.Println("  Chat is always interactive. If a message is provided, it's sent first.")
-		fmt.Println("  Type 'exit' or press Ctrl+D to exit the session.")
+		sh.println("Usage: chat [options] <agent_uuid> [message]")
+		sh.println("Options:")
+		sh.println("  -t <temperature>  Temperature for response generation (0.0-1.0)")
+		sh.println("  -m <max_tokens>   Maximum tokens in the response")
+		sh.println("  -c <session_id>   Name the session, or resume it if it already exists")
+		sh.println("  --trace           Show tool calls, tool responses and reasoning for each turn")
+		sh.println("  --node <uuid>     Attach a node as context (can be repeated)")
+		sh.println("  --file <path>     Upload a local file as a temporary node and attach it (can be repeated)")
+		sh.println()
+		sh.println("Arguments:")
+		sh.println("  agent_uuid: UUID of the agent to chat with (optional when resuming a session)")
+		sh.println("  message: Initial message to send (optional)")
+		sh.println()
+		sh.println("Interactive mode:")
+		sh.println("  Chat is always interactive. If a message is provided, it's sent first.")
+		sh.println("  The conversation history is sent with every message.")
+		sh.println("  Type 'exit' or press Ctrl+D to exit the session.")
+		sh.println()
+		printChatSessionCommands(sh)
 		return
 	}
 
 	var temperature *float64
 	var maxTokens *int
 	var agentUUID string
+	var sessionID string
 	var messageArgs []string
+	var nodes []string
+	var files []string
+	trace := false
 
 	// Parse flags and arguments
 	i := 0
 	for i < len(args) {
 		switch args[i] {
+		case "--trace":
+			trace = true
+			i++
+		case "--node":
+			if i+1 >= len(args) {
+				sh.eprintln("Error: --node requires a node UUID")
+				return
+			}
+			nodes = append(nodes, args[i+1])
+			i += 2
+		case "--file":
+			if i+1 >= len(args) {
+				sh.eprintln("Error: --file requires a file path")
+				return
+			}
+			files = append(files, args[i+1])
+			i += 2
 		case "-t":
 			if i+1 >= len(args) {
-				fmt.Println("Error: -t requires a temperature value")
+				sh.eprintln("Error: -t requires a temperature value")
 				return
 			}
 			temp, err := strconv.ParseFloat(args[i+1], 64)
 			if err != nil || temp < 0 || temp > 1 {
-				fmt.Println("Error: Temperature must be a number between 0.0 and 1.0")
+				sh.eprintln("Error: Temperature must be a number between 0.0 and 1.0")
 				return
 			}
 			temperature = &temp
 			i += 2
 		case "-m":
 			if i+1 >= len(args) {
-				fmt.Println("Error: -m requires a max tokens value")
+				sh.eprintln("Error: -m requires a max tokens value")
 				return
 			}
 			tokens, err := strconv.Atoi(args[i+1])
 			if err != nil || tokens <= 0 {
-				fmt.Println("Error: Max tokens must be a positive integer")
+				sh.eprintln("Error: Max tokens must be a positive integer")
 				return
 			}
 			maxTokens = &tokens
 			i += 2
+		case "-c":
+			if i+1 >= len(args) {
+				sh.eprintln("Error: -c requires a session ID")
+				return
+			}
+			sessionID = args[i+1]
+			i += 2
 
 		default:
 			// First non-option argument is agent UUID
@@ -82,23 +124,47 @@ func (c *ChatCommand) Execute(args []string) {
 
 parseComplete:
 
+	// A resumed session remembers the agent and options it was started with
+	if sessionID != "" {
+		session := sh.sessions.GetSession(sessionID)
+		if agentUUID == "" {
+			agentUUID = session.AgentUUID
+		}
+		savedTemperature, savedMaxTokens := chatOptionsFromSession(session.Options)
+		if temperature == nil {
+			temperature = savedTemperature
+		}
+		if maxTokens == nil {
+			maxTokens = savedMaxTokens
+		}
+	}
+
 	if agentUUID == "" {
-		fmt.Println("Error: Agent UUID is required")
+		sh.eprintln("Error: Agent UUID is required")
 		return
 	}
 
+	if sessionID == "" {
+		sessionID = "chat-" + time.Now().Format("20060102-150405")
+	}
+
 	// Always enter interactive mode, optionally with initial message
 	var initialMessage string
 	if len(messageArgs) > 0 {
 		initialMessage = strings.Join(messageArgs, " ")
 	}
 
-	c.startInteractiveSession(agentUUID, initialMessage, temperature, maxTokens)
+	attachments, err := resolveAttachments(sh, nodes, files)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	c.startInteractiveSession(sh, agentUUID, sessionID, initialMessage, temperature, maxTokens, attachments, trace)
 }
 
-func (c *ChatCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	text := d.TextBeforeCursor()
-	args := strings.Fields(text)
+func (c *ChatCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := fieldsBeforeCursor(d)
 
 	if len(args) == 0 {
 		return []prompt.Suggest{}
@@ -122,7 +188,7 @@ func (c *ChatCommand) Suggest(d prompt.Document) []prompt.Suggest {
 	}
 
 	// Adjust for current typing
-	if !strings.HasSuffix(text, " ") && len(args) > 1 {
+	if !cursorAfterBlank(d) && len(args) > 1 {
 		if strings.HasPrefix(lastArg, "-") {
 			// Currently typing a flag
 		} else if argCount > 0 {
@@ -130,20 +196,44 @@ func (c *ChatCommand) Suggest(d prompt.Document) []prompt.Suggest {
 		}
 	}
 
+	if suggests, ok := suggestAttachmentFlag(sh, d); ok {
+		return suggests
+	}
+
 	// Suggest flags if we're typing a flag
 	if strings.HasPrefix(lastArg, "-") {
 		return []prompt.Suggest{
 			{Text: "-t", Description: "Set temperature (0.0-1.0)"},
 			{Text: "-m", Description: "Set max tokens"},
+			{Text: "-c", Description: "Name or resume a session"},
+			{Text: "--trace", Description: "Show tool calls and reasoning"},
+			{Text: "--node", Description: "Attach a node as context"},
+			{Text: "--file", Description: "Attach a local file as context"},
+		}
+	}
+
+	// Suggest existing sessions after -c
+	if (len(args) >= 2 && args[len(args)-2] == "-c" && !cursorAfterBlank(d)) ||
+		(lastArg == "-c" && cursorAfterBlank(d)) {
+		currentWord := wordBeforeCursor(d)
+		var suggests []prompt.Suggest
+		for _, id := range sh.sessions.ListSessions() {
+			if strings.HasPrefix(strings.ToLower(id), strings.ToLower(currentWord)) {
+				suggests = append(suggests, prompt.Suggest{
+					Text:        id,
+					Description: fmt.Sprintf("%d messages", len(sh.sessions.GetSession(id).GetHistory())),
+				})
+			}
 		}
+		return suggests
 	}
 
 	// Suggest agent UUID if we haven't specified one yet
 	if argCount == 0 {
 		// Use cached agents
-		agents := GetCachedAgents()
+		agents := sh.GetCachedAgents()
 		var suggests []prompt.Suggest
-		currentWord := d.GetWordBeforeCursor()
+		currentWord := wordBeforeCursor(d)
 		for _, agent := range agents {
 			if strings.HasPrefix(strings.ToLower(agent.UUID), strings.ToLower(currentWord)) ||
 				strings.HasPrefix(strings.ToLower(agent.Title), strings.ToLower(currentWord)) {
@@ -159,52 +249,130 @@ func (c *ChatCommand) Suggest(d prompt.Document) []prompt.Suggest {
 	return []prompt.Suggest{}
 }
 
-// startInteractiveSession starts an interactive chat session with the specified agent
-func (c *ChatCommand) startInteractiveSession(agentUUID string, initialMessage string, temperature *float64, maxTokens *int) {
+// chatSessionOptions returns the chat options stored with a session
+func chatSessionOptions(temperature *float64, maxTokens *int, attachments []chatAttachment) map[string]any {
+	options := make(map[string]any)
+	if temperature != nil {
+		options["temperature"] = *temperature
+	}
+	if maxTokens != nil {
+		options["maxTokens"] = *maxTokens
+	}
+	if saved := attachmentsToOptions(attachments); len(saved) > 0 {
+		options["attachments"] = saved
+	}
+	return options
+}
+
+// chatOptionsFromSession returns the temperature and max tokens saved with a session.
+// Numbers read back from disk are float64, so both are converted from it.
+func chatOptionsFromSession(options map[string]any) (*float64, *int) {
+	var temperature *float64
+	var maxTokens *int
+	if value, ok := options["temperature"].(float64); ok {
+		temperature = &value
+	}
+	switch value := options["maxTokens"].(type) {
+	case float64:
+		tokens := int(value)
+		maxTokens = &tokens
+	case int:
+		maxTokens = &value
+	}
+	return temperature, maxTokens
+}
+
+// startInteractiveSession starts an interactive chat session with the specified agent.
+// The attachments are added to those saved with the session.
+func (c *ChatCommand) startInteractiveSession(sh *Shell, agentUUID string, sessionID string, initialMessage string, temperature *float64, maxTokens *int, attachments []chatAttachment, trace bool) {
 	// Find agent name for display
 	agentName := agentUUID
-	for _, agent := range GetCachedAgents() {
+	for _, agent := range sh.GetCachedAgents() {
 		if agent.UUID == agentUUID {
 			agentName = agent.Title
 			break
 		}
 	}
 
-	fmt.Printf("Starting interactive chat with %s\n", agentName)
-	fmt.Println("Type 'exit' or press Ctrl+D to exit the session.")
-	fmt.Println()
+	session := sh.sessions.GetSession(sessionID)
+	if !session.IsEmpty() && session.AgentUUID != "" && session.AgentUUID != agentUUID {
+		sh.eprintf("Warning: session '%s' was started with agent %s\n", sessionID, session.AgentUUID)
+	}
+	saved := attachmentsFromOptions(session.Options)
+	for _, attachment := range attachments {
+		saved = addAttachment(saved, attachment)
+	}
+	attachments = saved
 
-	// Send initial message if provided
-	if initialMessage != "" {
-		fmt.Printf("You: %s\n", initialMessage)
-		c.sendMessage(agentUUID, initialMessage, temperature, maxTokens)
-		fmt.Println()
+	session.Kind = SessionKindChat
+	session.AgentUUID = agentUUID
+	session.Options = chatSessionOptions(temperature, maxTokens, attachments)
+
+	sh.printf("Starting interactive chat with %s\n", agentName)
+	if session.IsEmpty() {
+		sh.printf("Session: %s\n", sessionID)
+	} else {
+		sh.printf("Session: %s (resumed, %d messages)\n", sessionID, len(session.GetHistory()))
+	}
+	if len(attachments) > 0 {
+		printAttachments(sh, attachments)
 	}
+	sh.println("Type 'exit' or press Ctrl+D to exit the session, '/help' for chat commands.")
+	sh.println()
 
 	// Create interactive session context
 	sessionContext := &ChatSessionContext{
+		sh:          sh,
 		agentUUID:   agentUUID,
+		agentName:   agentName,
+		sessionID:   sessionID,
 		temperature: temperature,
 		maxTokens:   maxTokens,
-		command:     c,
+		attachments: attachments,
+		trace:       trace,
+	}
+
+	// Send initial message if provided
+	if initialMessage != "" {
+		sh.printf("You: %s\n", initialMessage)
+		sessionContext.sendMessage(initialMessage)
+		sh.println()
 	}
 
 	// Create a new prompt for the chat session
 	p := prompt.New(
 		sessionContext.executeMessage,
-		func(d prompt.Document) []prompt.Suggest { return []prompt.Suggest{} },
+		sessionContext.suggestCommands,
 		prompt.OptionTitle(fmt.Sprintf("Chat with %s", agentName)),
 		prompt.OptionPrefix("You: "),
+		prompt.OptionLivePrefix(sessionContext.livePrefix),
+		prompt.OptionSetExitCheckerOnInput(func(in string, breakline bool) bool {
+			return breakline && strings.TrimSpace(in) == "exit"
+		}),
 	)
 	p.Run()
+
+	removeTemporaryAttachments(sh, sessionContext.attachments)
 }
 
 // ChatSessionContext holds the context for an interactive chat session
 type ChatSessionContext struct {
+	sh          *Shell
 	agentUUID   string
+	agentName   string
+	sessionID   string
 	temperature *float64
 	maxTokens   *int
-	command     *ChatCommand
+	attachments []chatAttachment
+	trace       bool
+}
+
+// livePrefix shows how many attachments are active in the prompt
+func (ctx *ChatSessionContext) livePrefix() (string, bool) {
+	if len(ctx.attachments) == 0 {
+		return "You: ", true
+	}
+	return fmt.Sprintf("You (%d attached): ", len(ctx.attachments)), true
 }
 
 func (ctx *ChatSessionContext) executeMessage(input string) {
@@ -212,7 +380,7 @@ func (ctx *ChatSessionContext) executeMessage(input string) {
 
 	// Check for exit command
 	if input == "exit" {
-		fmt.Println("Exiting chat session...")
+		ctx.sh.println("Exiting chat session...")
 		return
 	}
 
@@ -221,48 +389,195 @@ func (ctx *ChatSessionContext) executeMessage(input string) {
 		return
 	}
 
+	if strings.HasPrefix(input, "/") {
+		ctx.executeCommand(input)
+		return
+	}
+
 	// Send message and display response
-	ctx.command.sendMessage(ctx.agentUUID, input, ctx.temperature, ctx.maxTokens)
+	ctx.sendMessage(input)
 }
 
-// sendMessage sends a single message to the agent and displays the response
-func (c *ChatCommand) sendMessage(agentUUID string, message string, temperature *float64, maxTokens *int) {
-	// Find agent name for display
-	agentName := agentUUID
-	for _, agent := range GetCachedAgents() {
-		if agent.UUID == agentUUID {
-			agentName = agent.Title
-			break
+// chatSessionCommands are the commands available inside a chat session
+var chatSessionCommands = []prompt.Suggest{
+	{Text: "/clear", Description: "Forget the conversation so far"},
+	{Text: "/history", Description: "Show the conversation so far"},
+	{Text: "/save", Description: "Save the conversation as JSON: /save [file]"},
+	{Text: "/trace", Description: "Toggle showing tool calls, tool responses and reasoning"},
+	{Text: "/attach", Description: "Attach a node or local file, or list attachments: /attach [uuid|file]"},
+	{Text: "/detach", Description: "Remove an attachment: /detach <uuid|all>"},
+	{Text: "/help", Description: "Show the chat commands"},
+}
+
+func printChatSessionCommands(sh *Shell) {
+	sh.println("Chat commands:")
+	for _, cmd := range chatSessionCommands {
+		sh.printf("  %-10s %s\n", cmd.Text, cmd.Description)
+	}
+}
+
+func (ctx *ChatSessionContext) suggestCommands(d prompt.Document) []prompt.Suggest {
+	text := d.TextBeforeCursor()
+	if !strings.HasPrefix(text, "/") || strings.Contains(text, " ") {
+		return []prompt.Suggest{}
+	}
+	return prompt.FilterHasPrefix(chatSessionCommands, text, true)
+}
+
+// executeCommand runs an in-chat slash command
+func (ctx *ChatSessionContext) executeCommand(input string) {
+	fields := strings.Fields(input)
+	session := ctx.sh.sessions.GetSession(ctx.sessionID)
+
+	switch fields[0] {
+	case "/clear":
+		ctx.sh.sessions.ClearSession(ctx.sessionID)
+		ctx.sh.println("Conversation cleared.")
+	case "/history":
+		history := session.GetChatHistory()
+		if len(history) == 0 {
+			ctx.sh.println("No messages yet.")
+			return
+		}
+		printChatHistory(ctx.sh, history)
+	case "/save":
+		path := ctx.sessionID + ".json"
+		if len(fields) > 1 {
+			path = strings.Join(fields[1:], " ")
+		}
+		data, err := json.MarshalIndent(session.GetChatHistory(), "", "  ")
+		if err != nil {
+			ctx.sh.eprintln("Error:", err)
+			return
 		}
+		if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			ctx.sh.eprintln("Error writing file:", err)
+			return
+		}
+		ctx.sh.printf("Conversation saved to %s\n", path)
+	case "/trace":
+		ctx.trace = !ctx.trace
+		if ctx.trace {
+			ctx.sh.println("Trace on.")
+		} else {
+			ctx.sh.println("Trace off.")
+		}
+	case "/attach":
+		if len(fields) == 1 {
+			printAttachments(ctx.sh, ctx.attachments)
+			return
+		}
+		attachment, err := attachReference(ctx.sh, strings.Join(fields[1:], " "))
+		if err != nil {
+			ctx.sh.eprintln("Error:", err)
+			return
+		}
+		ctx.attachments = addAttachment(ctx.attachments, attachment)
+		ctx.saveAttachments(session)
+		ctx.sh.printf("Attached %s (%s).\n", attachment.Title, attachment.UUID)
+	case "/detach":
+		if len(fields) != 2 {
+			ctx.sh.println("Usage: /detach <uuid|all>")
+			return
+		}
+		ctx.detach(session, fields[1])
+	case "/help":
+		printChatSessionCommands(ctx.sh)
+	default:
+		ctx.sh.printf("Unknown chat command: %s (type /help for the list)\n", fields[0])
+	}
+}
+
+// detach removes an attachment, or all of them, removing the nodes of temporary ones
+func (ctx *ChatSessionContext) detach(session *Session, uuid string) {
+	var removed, kept []chatAttachment
+	for _, attachment := range ctx.attachments {
+		if uuid == "all" || attachment.UUID == uuid {
+			removed = append(removed, attachment)
+		} else {
+			kept = append(kept, attachment)
+		}
+	}
+	if len(removed) == 0 {
+		ctx.sh.printf("No attachment %s.\n", uuid)
+		return
+	}
+
+	removeTemporaryAttachments(ctx.sh, removed)
+	ctx.attachments = kept
+	ctx.saveAttachments(session)
+	ctx.sh.printf("Detached %d attachment(s).\n", len(removed))
+}
+
+// saveAttachments keeps the attachments with the saved session
+func (ctx *ChatSessionContext) saveAttachments(session *Session) {
+	session.Options = chatSessionOptions(ctx.temperature, ctx.maxTokens, ctx.attachments)
+	if err := ctx.sh.sessions.SaveSession(ctx.sessionID); err != nil {
+		ctx.sh.eprintln("Warning: session not saved:", err)
 	}
+}
+
+// sendMessage sends a message with the conversation so far and displays the response
+func (ctx *ChatSessionContext) sendMessage(message string) {
+	session := ctx.sh.sessions.GetSession(ctx.sessionID)
+	previous := session.GetChatHistory()
 
-	// Show loading animation while waiting for response (dots style is less distracting in chat)
-	animation := StartLoadingAnimationWithStyle(fmt.Sprintf("Chatting with %s", agentName), DotsStyle)
-	chatHistory, err := client.ChatWithAgent(agentUUID, message, "", temperature, maxTokens, nil)
+	// Show loading animation until the response starts streaming (dots style is less distracting in chat)
+	animation := ctx.sh.startLoadingAnimation(fmt.Sprintf("Chatting with %s", ctx.agentName), DotsStyle)
+	printer := newStreamPrinter(ctx.sh.out, animation, fmt.Sprintf("✓ %s:", ctx.agentName), "Assistant: ", ctx.trace)
+	chatHistory, err := ctx.sh.client.ChatWithAgentStream(ctx.agentUUID, message, ctx.sessionID, ctx.temperature, ctx.maxTokens, chatHistoryToMaps(previous), attachmentContext(ctx.attachments), printer.onPart)
 
 	if err != nil {
-		animation.StopWithMessage(fmt.Sprintf("✗ Error chatting with %s", agentName))
-		fmt.Println("Error:", err)
+		printer.fail(fmt.Sprintf("✗ Error chatting with %s", ctx.agentName))
+		ctx.sh.eprintln("Error:", err)
 		return
 	}
 
-	animation.StopWithMessage(fmt.Sprintf("✓ %s:", agentName))
+	merged := mergeChatHistory(previous, chatHistory, message)
 
-	// Find the last model response from the chat history
-	for i := len(chatHistory) - 1; i >= 0; i-- {
-		msg := chatHistory[i]
-		if msg.Role == "model" {
-			for _, part := range msg.Parts {
-				if part.Text != nil {
-					fmt.Printf("Assistant: %s\n", *part.Text)
-					return
-				}
-			}
+	response := responseText(chatHistory)
+	if response == "" {
+		response = "(no response)"
+	}
+	printer.finish("Assistant: "+response, merged[len(previous):])
+
+	session.SetChatHistory(merged)
+	if err := ctx.sh.sessions.SaveSession(ctx.sessionID); err != nil {
+		ctx.sh.eprintln("Warning: session not saved:", err)
+	}
+}
+
+// printChatHistory prints every message of a conversation, including tool calls
+func printChatHistory(sh *Shell, history antbox.ChatHistory) {
+	for i, msg := range history {
+		sh.printf("[%d] %s:\n", i+1, strings.ToUpper(string(msg.Role)))
+		for _, part := range msg.Parts {
+			sh.printf("  %s\n", formatChatPart(part))
 		}
 	}
+}
 
-	// If no model response found, show that no response was received
-	fmt.Println("Assistant: (no response)")
+// formatChatPart returns a one-line description of a message part
+func formatChatPart(part antbox.ChatMessagePart) string {
+	switch {
+	case part.Text != nil:
+		return *part.Text
+	case part.ToolCall != nil:
+		args, _ := json.Marshal(part.ToolCall.Args)
+		return fmt.Sprintf("→ %s(%s)", part.ToolCall.Name, string(args))
+	case part.Reasoning != nil:
+		return "Reasoning: " + *part.Reasoning
+	case part.ToolResponse != nil:
+		text := part.ToolResponse.Text
+		if utf8.RuneCountInString(text) > 200 {
+			text = string([]rune(text)[:200]) + "..."
+		}
+		return fmt.Sprintf("← %s: %s", part.ToolResponse.Name, text)
+	case len(part.Extra) > 0:
+		return fmt.Sprintf("(%s part)", strings.Join(slices.Sorted(maps.Keys(part.Extra)), ", "))
+	default:
+		return "(empty)"
+	}
 }
 
 func init() {
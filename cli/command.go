@@ -6,8 +6,8 @@ import (
 
 // Command defines the interface for a CLI command.
 type Command interface {
-	Execute(args []string)
-	Suggest(d prompt.Document) []prompt.Suggest
+	Execute(sh *Shell, args []string)
+	Suggest(sh *Shell, d prompt.Document) []prompt.Suggest
 	GetName() string
 	GetDescription() string
 }
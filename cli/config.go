@@ -3,8 +3,10 @@ package cli
 import (
 	"bufio"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/kindalus/antx/antbox"
@@ -18,6 +20,7 @@ const (
 // CLIConfig holds the persistent CLI state
 type CLIConfig struct {
 	CurrentNodeUUID string
+	Settings        map[string]string
 	History         []string
 }
 
@@ -57,6 +60,13 @@ func saveConfig(config *CLIConfig) error {
 		return fmt.Errorf("failed to write current node UUID: %v", err)
 	}
 
+	// Write settings as key=value lines, sorted so the file is stable
+	for _, key := range slices.Sorted(maps.Keys(config.Settings)) {
+		if _, err := fmt.Fprintf(file, "%s=%s\n", key, config.Settings[key]); err != nil {
+			return fmt.Errorf("failed to write setting %s: %v", key, err)
+		}
+	}
+
 	// Write blank line
 	if _, err := fmt.Fprintf(file, "\n"); err != nil {
 		return fmt.Errorf("failed to write blank line: %v", err)
@@ -89,6 +99,7 @@ func loadConfig() (*CLIConfig, error) {
 		// Return default config if file doesn't exist
 		return &CLIConfig{
 			CurrentNodeUUID: "--root--",
+			Settings:        map[string]string{},
 			History:         []string{},
 		}, nil
 	}
@@ -101,11 +112,13 @@ func loadConfig() (*CLIConfig, error) {
 
 	config := &CLIConfig{
 		CurrentNodeUUID: "--root--",
+		Settings:        map[string]string{},
 		History:         []string{},
 	}
 
 	scanner := bufio.NewScanner(file)
 	lineNum := 0
+	inSettings := true
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -116,9 +129,15 @@ func loadConfig() (*CLIConfig, error) {
 			if line != "" {
 				config.CurrentNodeUUID = line
 			}
-		} else if lineNum == 2 {
-			// Second line: blank line (skip)
-			continue
+		} else if inSettings {
+			// Settings lines up to the blank line
+			if line == "" {
+				inSettings = false
+				continue
+			}
+			if key, value, ok := strings.Cut(line, "="); ok {
+				config.Settings[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
 		} else {
 			// Remaining lines: command history
 			if line != "" {
@@ -153,75 +172,85 @@ func (c *CLIConfig) addToHistory(command string) {
 }
 
 // loadCurrentNodeFromConfig loads the current node from saved UUID
-func loadCurrentNodeFromConfig(uuid string) (antbox.Node, error) {
+func (sh *Shell) loadCurrentNodeFromConfig(uuid string) (antbox.Node, error) {
 	if uuid == "" || uuid == "--root--" {
-		return antbox.Node{
-			UUID:     "--root--",
-			Title:    "root",
-			Mimetype: "application/vnd.antbox.folder",
-		}, nil
+		return rootNode(), nil
 	}
 
 	// Try to load the saved node
-	node, err := client.GetNode(uuid)
+	node, err := sh.client.GetNode(uuid)
 	if err != nil {
 		// If we can't load the saved node, fall back to root
-		fmt.Printf("Warning: Could not restore previous location (%s), starting at root\n", uuid)
-		return antbox.Node{
-			UUID:     "--root--",
-			Title:    "root",
-			Mimetype: "application/vnd.antbox.folder",
-		}, nil
+		sh.eprintf("Warning: Could not restore previous location (%s), starting at root\n", uuid)
+		return rootNode(), nil
 	}
 
 	return *node, nil
 }
 
-// saveCurrentState saves the current CLI state to disk
-func saveCurrentState() {
-	if client == nil {
-		return // Not initialized yet
+// currentConfig returns a copy of the CLI state to save
+func (sh *Shell) currentConfig() *CLIConfig {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return &CLIConfig{
+		CurrentNodeUUID: sh.currentNode.UUID,
+		Settings:        sh.settingsSnapshot(),
+		History:         slices.Clone(sh.history),
 	}
+}
 
-	config := &CLIConfig{
-		CurrentNodeUUID: currentNode.UUID,
-		History:         cliHistory,
+// saveCurrentState saves the current CLI state to disk
+func (sh *Shell) saveCurrentState() {
+	if !sh.persistent {
+		return
 	}
 
-	if err := saveConfig(config); err != nil {
+	if err := saveConfig(sh.currentConfig()); err != nil {
 		// Silently ignore save errors to avoid disrupting CLI flow
 		// Could add debug logging here if needed
 	}
 }
 
 // restoreFromConfig restores CLI state from saved configuration
-func restoreFromConfig() error {
+func (sh *Shell) restoreFromConfig() error {
 	config, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %v", err)
 	}
 
 	// Restore current node
-	restoredNode, err := loadCurrentNodeFromConfig(config.CurrentNodeUUID)
+	restoredNode, err := sh.loadCurrentNodeFromConfig(config.CurrentNodeUUID)
 	if err != nil {
 		return fmt.Errorf("failed to restore current node: %v", err)
 	}
 
-	currentNode = restoredNode
+	sh.setCurrentNode(restoredNode)
 
-	// Restore command history
-	cliHistory = config.History
+	// Restore settings and command history
+	for _, err := range sh.restoreSettings(config.Settings) {
+		sh.eprintln("Warning: ignoring setting in config file:", err)
+	}
+	sh.mu.Lock()
+	sh.history = config.History
+	sh.mu.Unlock()
 
 	// Load current folder contents
-	if nodes, err := client.ListNodes(currentNode.UUID); err == nil {
-		currentNodes = nodes
+	if nodes, err := sh.client.ListNodes(restoredNode.UUID); err == nil {
+		sh.setCurrentNodes(nodes)
 	}
 
 	return nil
 }
 
+// getHistory returns a copy of the command history
+func (sh *Shell) getHistory() []string {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return slices.Clone(sh.history)
+}
+
 // addCommandToHistory adds a command to the CLI history and saves state
-func addCommandToHistory(command string) {
+func (sh *Shell) addCommandToHistory(command string) {
 	// Skip certain commands from history
 	skipCommands := map[string]bool{
 		"help":    true,
@@ -234,15 +263,20 @@ func addCommandToHistory(command string) {
 	if len(commandParts) > 0 {
 		cmdName := commandParts[0]
 		if !skipCommands[cmdName] {
-			cliHistory = append(cliHistory, command)
+			sh.mu.Lock()
+			sh.history = append(sh.history, command)
 
 			// Keep only the last maxHistorySize entries
-			if len(cliHistory) > maxHistorySize {
-				cliHistory = cliHistory[len(cliHistory)-maxHistorySize:]
+			if len(sh.history) > maxHistorySize {
+				sh.history = sh.history[len(sh.history)-maxHistorySize:]
 			}
+			sh.mu.Unlock()
 
 			// Save state after each command
-			go saveCurrentState() // Save asynchronously to avoid blocking
+			// Save asynchronously to avoid blocking, from a copy of the state taken now
+			if sh.persistent {
+				go saveConfig(sh.currentConfig())
+			}
 		}
 	}
 }
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"github.com/c-bata/go-prompt"
+)
+
+type CountCommand struct{}
+
+func (c *CountCommand) GetName() string {
+	return "count"
+}
+
+func (c *CountCommand) GetDescription() string {
+	return "Count the piped records"
+}
+
+func (c *CountCommand) Execute(sh *Shell, args []string) {
+	if len(args) > 0 || sh.input == nil {
+		sh.println("Usage: <command> | count")
+		sh.println("  Example: find mimetype == application/pdf | count")
+		return
+	}
+
+	sh.println(len(sh.input.items))
+}
+
+func (c *CountCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	return []prompt.Suggest{}
+}
+
+func init() {
+	RegisterCommand(&CountCommand{})
+}
@@ -1,10 +1,10 @@
 package cli
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
 	markdown "go.xrstf.de/go-term-markdown"
 )
 
@@ -18,26 +18,28 @@ func (c *DocsCommand) GetDescription() string {
 	return "List all available documents or display a specific document"
 }
 
-func (c *DocsCommand) Execute(args []string) {
+func (c *DocsCommand) Execute(sh *Shell, args []string) {
+	opts, args, err := parseListFlags(sh, args)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
 	if len(args) == 0 {
 		// List all documents
-		docs, err := client.ListDocs()
+		docs, err := sh.client.ListDocs()
 		if err != nil {
-			fmt.Println("Error listing documents:", err)
+			sh.eprintln("Error listing documents:", err)
 			return
 		}
 
-		if len(docs) == 0 {
-			fmt.Println("No documents available.")
+		if len(docs) == 0 && opts.forPeople() && !sh.piping {
+			sh.println("No documents available.")
 			return
 		}
 
-		fmt.Println("Available documents:")
-		fmt.Println()
-		for _, doc := range docs {
-			fmt.Printf("UUID: %s\n", doc.UUID)
-			fmt.Printf("  Description: %s\n", doc.Description)
-			fmt.Println()
+		if err := renderList(sh, opts, docs, docColumns); err != nil {
+			sh.eprintln("Error:", err)
 		}
 		return
 	}
@@ -46,26 +48,32 @@ func (c *DocsCommand) Execute(args []string) {
 	docUUID := args[0]
 
 	// Get document content
-	docContent, err := client.GetDoc(docUUID)
+	docContent, err := sh.client.GetDoc(docUUID)
 	if err != nil {
-		fmt.Println("Error getting document:", err)
+		sh.eprintln("Error getting document:", err)
 		return
 	}
 
 	// Render markdown content
 	result := markdown.Render(docContent, 100, 11)
-	fmt.Print(string(result))
+	sh.print(string(result))
+}
+
+// docColumns are the columns of document listings
+var docColumns = []listColumn[antbox.DocInfo]{
+	{Name: "uuid", Header: "UUID", Value: func(d antbox.DocInfo) string { return d.UUID }},
+	{Name: "description", Header: "DESCRIPTION", Value: func(d antbox.DocInfo) string { return d.Description }},
 }
 
-func (c *DocsCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	args := strings.Split(d.TextBeforeCursor(), " ")
+func (c *DocsCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := argsBeforeCursor(d)
 
 	if len(args) <= 2 {
 		// First argument - suggest document UUIDs
-		word := d.GetWordBeforeCursor()
+		word := wordBeforeCursor(d)
 
 		// Get cached docs or fetch them
-		docs, err := client.ListDocs()
+		docs, err := sh.client.ListDocs()
 		if err != nil {
 			return []prompt.Suggest{}
 		}
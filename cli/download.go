@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 
@@ -18,39 +17,39 @@ func (c *DownloadCommand) GetDescription() string {
 	return "Download a node to Downloads folder"
 }
 
-func (c *DownloadCommand) Execute(args []string) {
+func (c *DownloadCommand) Execute(sh *Shell, args []string) {
 	if len(args) == 0 {
-		fmt.Println("Usage: download <uuid>")
+		sh.println("Usage: download <uuid>")
 		return
 	}
 
 	// Get node details to get the title for filename
-	node, err := client.GetNode(args[0])
+	node, err := sh.client.GetNode(args[0])
 	if err != nil {
-		fmt.Println("Error getting node details:", err)
+		sh.eprintln("Error getting node details:", err)
 		return
 	}
 
 	// Get user's Downloads directory
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		fmt.Println("Error getting home directory:", err)
+		sh.eprintln("Error getting home directory:", err)
 		return
 	}
 
 	downloadPath := filepath.Join(homeDir, "Downloads", node.Title)
 
-	err = client.DownloadNode(args[0], downloadPath)
+	err = sh.client.DownloadNode(args[0], downloadPath)
 	if err != nil {
-		fmt.Println("Error:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
 
-	fmt.Printf("Node '%s' downloaded to %s\n", node.Title, downloadPath)
+	sh.printf("Node '%s' downloaded to %s\n", node.Title, downloadPath)
 }
 
-func (c *DownloadCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	return getNodeSuggestions(d.GetWordBeforeCursor(), nil)
+func (c *DownloadCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	return getNodeSuggestions(sh, wordBeforeCursor(d), nil)
 }
 
 func init() {
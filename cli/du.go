@@ -0,0 +1,294 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
+)
+
+// duConcurrency is the number of folders du lists at the same time
+const duConcurrency = 8
+
+type DuCommand struct{}
+
+func (c *DuCommand) GetName() string {
+	return "du"
+}
+
+func (c *DuCommand) GetDescription() string {
+	return "Show the storage used by a folder"
+}
+
+func (c *DuCommand) Execute(sh *Shell, args []string) {
+	format, args, err := parseOutputFlag(sh, args)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	depth, top := 1, 10
+	root := sh.getCurrentNode()
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "-d", "--depth", "--top":
+			if i+1 >= len(args) {
+				sh.eprintf("Error: %s requires a number\n", arg)
+				return
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 || (arg == "--top" && n < 1) {
+				sh.eprintf("Error: invalid value for %s: %s\n", arg, args[i+1])
+				return
+			}
+			if arg == "--top" {
+				top = n
+			} else {
+				depth = n
+			}
+			i++
+		case "--help":
+			sh.println("Usage: du [-d depth] [--top N] [-o table|json|yaml] [folder_uuid]")
+			return
+		case "--root--":
+			root = antbox.Node{UUID: "--root--", Title: "root", Mimetype: "application/vnd.antbox.folder"}
+		default:
+			node, err := sh.client.GetNode(arg)
+			if err != nil {
+				sh.eprintln("Error:", err)
+				return
+			}
+			if node.Mimetype != "application/vnd.antbox.folder" {
+				sh.eprintf("Error: %s is not a folder\n", node.Title)
+				return
+			}
+			root = *node
+		}
+	}
+
+	report := collectDu(sh, root, depth, top)
+	if err := renderValue(sh, format, report, func(bool) { printDuReport(sh, report) }); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
+
+// duReport is the storage used under a folder
+type duReport struct {
+	Size      int        `json:"size"`
+	Files     int        `json:"files"`
+	Folders   []duFolder `json:"folders"`
+	Mimetypes []duGroup  `json:"mimetypes"`
+	Owners    []duGroup  `json:"owners"`
+	Largest   []duFile   `json:"largest"`
+	Errors    []string   `json:"errors,omitempty"`
+}
+
+// duFolder is a folder with the size and number of files of everything under it
+type duFolder struct {
+	UUID    string `json:"uuid"`
+	Path    string `json:"path"`
+	Depth   int    `json:"depth"`
+	Size    int    `json:"size"`
+	Files   int    `json:"files"`
+	Folders int    `json:"folders"`
+
+	parent string
+}
+
+// duGroup is the storage used by the files of a mimetype or an owner
+type duGroup struct {
+	Name  string `json:"name"`
+	Size  int    `json:"size"`
+	Files int    `json:"files"`
+}
+
+// duFile is a file found by du
+type duFile struct {
+	UUID     string `json:"uuid"`
+	Path     string `json:"path"`
+	Mimetype string `json:"mimetype"`
+	Owner    string `json:"owner"`
+	Size     int    `json:"size"`
+}
+
+// collectDu walks the whole tree under root, listing up to duConcurrency folders at a time.
+// The report shows the folders down to depth levels and the top largest files, mimetypes
+// and owners. Smart folders are skipped, as their content lives elsewhere.
+func collectDu(sh *Shell, root antbox.Node, depth, top int) duReport {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		slots    = make(chan struct{}, duConcurrency)
+		folders  = []*duFolder{{UUID: root.UUID, Path: root.Title}}
+		files    []duFile
+		failures []string
+	)
+
+	var walk func(folder *duFolder)
+	walk = func(folder *duFolder) {
+		defer wg.Done()
+
+		slots <- struct{}{}
+		nodes, err := sh.client.ListNodes(folder.UUID)
+		<-slots
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", folder.Path, err))
+			return
+		}
+
+		for _, node := range nodes {
+			path := folder.Path + "/" + node.Title
+			switch node.Mimetype {
+			case "application/vnd.antbox.folder":
+				child := &duFolder{UUID: node.UUID, Path: path, Depth: folder.Depth + 1, parent: folder.UUID}
+				folders = append(folders, child)
+				wg.Add(1)
+				go walk(child)
+			case "application/vnd.antbox.smartfolder":
+			default:
+				folder.Size += node.Size
+				folder.Files++
+				files = append(files, duFile{UUID: node.UUID, Path: path, Mimetype: node.Mimetype, Owner: node.Owner, Size: node.Size})
+			}
+		}
+	}
+
+	wg.Add(1)
+	walk(folders[0])
+	wg.Wait()
+
+	// Add the totals of each folder to its parent, the deepest folders first
+	byUUID := make(map[string]*duFolder, len(folders))
+	for _, folder := range folders {
+		byUUID[folder.UUID] = folder
+	}
+	slices.SortStableFunc(folders, func(a, b *duFolder) int { return cmp.Compare(b.Depth, a.Depth) })
+	for _, folder := range folders {
+		if parent, ok := byUUID[folder.parent]; ok && folder.Depth > 0 {
+			parent.Size += folder.Size
+			parent.Files += folder.Files
+			parent.Folders += folder.Folders + 1
+		}
+	}
+
+	report := duReport{
+		Size:      byUUID[root.UUID].Size,
+		Files:     byUUID[root.UUID].Files,
+		Folders:   []duFolder{},
+		Mimetypes: duGroups(files, top, func(f duFile) string { return f.Mimetype }),
+		Owners:    duGroups(files, top, func(f duFile) string { return f.Owner }),
+		Errors:    failures,
+	}
+	for _, folder := range folders {
+		if folder.Depth <= depth {
+			report.Folders = append(report.Folders, *folder)
+		}
+	}
+	slices.SortStableFunc(report.Folders, func(a, b duFolder) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), cmp.Compare(a.Path, b.Path))
+	})
+
+	slices.SortStableFunc(files, func(a, b duFile) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), cmp.Compare(a.Path, b.Path))
+	})
+	report.Largest = files[:min(top, len(files))]
+	if report.Largest == nil {
+		report.Largest = []duFile{}
+	}
+	slices.Sort(report.Errors)
+	return report
+}
+
+// duGroups sums the files by the key returned by group, largest first, keeping the top ones
+func duGroups(files []duFile, top int, group func(f duFile) string) []duGroup {
+	totals := make(map[string]*duGroup)
+	var groups []*duGroup
+	for _, file := range files {
+		name := group(file)
+		if name == "" {
+			name = "(none)"
+		}
+		g, ok := totals[name]
+		if !ok {
+			g = &duGroup{Name: name}
+			totals[name] = g
+			groups = append(groups, g)
+		}
+		g.Size += file.Size
+		g.Files++
+	}
+
+	slices.SortStableFunc(groups, func(a, b *duGroup) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), cmp.Compare(a.Name, b.Name))
+	})
+
+	result := []duGroup{}
+	for _, g := range groups[:min(top, len(groups))] {
+		result = append(result, *g)
+	}
+	return result
+}
+
+// printDuReport prints the folders and the breakdowns of a du report as tables
+func printDuReport(sh *Shell, report duReport) {
+	w := tabwriter.NewWriter(sh.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "SIZE\tFILES\tFOLDER")
+	for _, folder := range report.Folders {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", humanSize(folder.Size), folder.Files, colorize(sh.out, folder.Path, folderColor))
+	}
+
+	if len(report.Mimetypes) > 0 {
+		fmt.Fprintln(w, "\nSIZE\tFILES\tMIMETYPE")
+		for _, g := range report.Mimetypes {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", humanSize(g.Size), g.Files, g.Name)
+		}
+	}
+
+	if len(report.Owners) > 0 {
+		fmt.Fprintln(w, "\nSIZE\tFILES\tOWNER")
+		for _, g := range report.Owners {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", humanSize(g.Size), g.Files, g.Name)
+		}
+	}
+
+	if len(report.Largest) > 0 {
+		fmt.Fprintln(w, "\nSIZE\tUUID\tLARGEST FILES")
+		for _, file := range report.Largest {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", humanSize(file.Size), file.UUID, file.Path)
+		}
+	}
+	w.Flush()
+
+	sh.printf("\nTotal: %s in %d files\n", humanSize(report.Size), report.Files)
+	for _, err := range report.Errors {
+		sh.eprintln("Warning: could not list", err)
+	}
+}
+
+func (c *DuCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	word := wordBeforeCursor(d)
+	if strings.HasPrefix(word, "-") {
+		return prompt.FilterHasPrefix([]prompt.Suggest{
+			{Text: "-d", Description: "Number of folder levels shown"},
+			{Text: "--top", Description: "Number of largest files, mimetypes and owners shown"},
+			{Text: "-o", Description: "Output format"},
+		}, word, false)
+	}
+	return getNodeSuggestions(sh, word, func(node antbox.Node) bool {
+		return node.Mimetype == "application/vnd.antbox.folder"
+	})
+}
+
+func init() {
+	RegisterCommand(&DuCommand{})
+}
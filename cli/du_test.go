@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+func TestCollectDu(t *testing.T) {
+	sh := newTestShell(&folderMockClient{children: map[string][]antbox.Node{
+		"root": {
+			{UUID: "notes", Title: "notes.txt", Mimetype: "text/plain", Owner: "ana", Size: 100},
+			{UUID: "docs", Title: "Docs", Mimetype: "application/vnd.antbox.folder"},
+			{UUID: "locked", Title: "Locked", Mimetype: "application/vnd.antbox.folder"},
+			{UUID: "search", Title: "Search", Mimetype: "application/vnd.antbox.smartfolder"},
+		},
+		"docs": {
+			{UUID: "q1", Title: "q1.pdf", Mimetype: "application/pdf", Owner: "rui", Size: 2000},
+			{UUID: "old", Title: "Old", Mimetype: "application/vnd.antbox.folder"},
+		},
+		"old": {
+			{UUID: "q0", Title: "q0.pdf", Mimetype: "application/pdf", Owner: "ana", Size: 500},
+		},
+		"search": {
+			{UUID: "q1", Title: "q1.pdf", Mimetype: "application/pdf", Owner: "rui", Size: 2000},
+		},
+	}})
+
+	report := collectDu(sh, antbox.Node{UUID: "root", Title: "Home"}, 1, 2)
+
+	if report.Size != 2600 || report.Files != 3 {
+		t.Errorf("Expected 2600 bytes in 3 files, got %d in %d", report.Size, report.Files)
+	}
+
+	var folders []string
+	for _, f := range report.Folders {
+		folders = append(folders, f.Path)
+	}
+	if strings.Join(folders, ",") != "Home,Home/Docs,Home/Locked" {
+		t.Errorf("Unexpected folders: %v", folders)
+	}
+	if docs := report.Folders[1]; docs.Size != 2500 || docs.Files != 2 || docs.Folders != 1 {
+		t.Errorf("Unexpected totals for Docs: %+v", docs)
+	}
+	if root := report.Folders[0]; root.Folders != 3 {
+		t.Errorf("Expected 3 folders under the root, got %d", root.Folders)
+	}
+
+	if len(report.Mimetypes) != 2 || report.Mimetypes[0] != (duGroup{Name: "application/pdf", Size: 2500, Files: 2}) {
+		t.Errorf("Unexpected mimetypes: %+v", report.Mimetypes)
+	}
+	if len(report.Owners) != 2 || report.Owners[0] != (duGroup{Name: "rui", Size: 2000, Files: 1}) || report.Owners[1] != (duGroup{Name: "ana", Size: 600, Files: 2}) {
+		t.Errorf("Unexpected owners: %+v", report.Owners)
+	}
+	if len(report.Largest) != 2 || report.Largest[0].Path != "Home/Docs/q1.pdf" || report.Largest[1].UUID != "q0" {
+		t.Errorf("Unexpected largest files: %+v", report.Largest)
+	}
+	if len(report.Errors) != 1 || !strings.HasPrefix(report.Errors[0], "Home/Locked") {
+		t.Errorf("Expected an error for the locked folder, got %v", report.Errors)
+	}
+}
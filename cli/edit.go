@@ -0,0 +1,356 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
+	"gopkg.in/yaml.v3"
+)
+
+type EditCommand struct{}
+
+func (c *EditCommand) GetName() string {
+	return "edit"
+}
+
+func (c *EditCommand) GetDescription() string {
+	return "Edit node metadata or content in $EDITOR"
+}
+
+// editableNode holds the node fields that can be changed through the editor
+type editableNode struct {
+	Title       string             `yaml:"title"`
+	Mimetype    string             `yaml:"mimetype"`
+	Parent      string             `yaml:"parent"`
+	Permissions antbox.Permissions `yaml:"permissions"`
+}
+
+func (c *EditCommand) Execute(sh *Shell, args []string) {
+	if len(args) == 0 {
+		sh.println("Usage: edit [-c] <uuid>")
+		sh.println("  -c: Edit the node content instead of its metadata")
+		sh.println()
+		sh.println("Description:")
+		sh.println("  Opens the node metadata as YAML in $EDITOR. Only the fields that")
+		sh.println("  changed are sent to the server. If the update is rejected, the")
+		sh.println("  editor is reopened with the error included as a comment.")
+		sh.println()
+		sh.println("Examples:")
+		sh.println("  edit abc123")
+		sh.println("  edit -c abc123")
+		return
+	}
+
+	editContent := false
+	var nodeUUID string
+	for _, arg := range args {
+		if arg == "-c" {
+			editContent = true
+			continue
+		}
+		nodeUUID = arg
+	}
+
+	if nodeUUID == "" {
+		sh.eprintln("Error: node UUID is required")
+		return
+	}
+
+	node, err := sh.client.GetNode(nodeUUID)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	if editContent {
+		c.editContent(sh, node)
+		return
+	}
+
+	c.editMetadata(sh, node)
+}
+
+// editMetadata opens the node metadata in the editor and applies the changed fields
+func (c *EditCommand) editMetadata(sh *Shell, node *antbox.Node) {
+	original := editableNode{
+		Title:       node.Title,
+		Mimetype:    node.Mimetype,
+		Parent:      node.Parent,
+		Permissions: node.Permissions,
+	}
+
+	body, err := yaml.Marshal(original)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	tmpFile, err := os.CreateTemp("", "antx-edit-*.yaml")
+	if err != nil {
+		sh.eprintln("Error creating temporary file:", err)
+		return
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpPath)
+
+	header := fmt.Sprintf("# Editing node %s (%s)\n"+
+		"# Lines starting with '#' are ignored. Save and close the editor to apply\n"+
+		"# the changes, or leave the file unchanged to abort.\n", node.UUID, node.Title)
+
+	errorComment := ""
+	for {
+		content := header + errorComment + "\n" + string(body)
+		if err := os.WriteFile(tmpPath, []byte(content), 0600); err != nil {
+			sh.eprintln("Error writing temporary file:", err)
+			return
+		}
+
+		if err := runEditor(tmpPath); err != nil {
+			sh.eprintln("Error running editor:", err)
+			return
+		}
+
+		edited, err := os.ReadFile(tmpPath)
+		if err != nil {
+			sh.eprintln("Error reading temporary file:", err)
+			return
+		}
+		retried := body
+		body = stripCommentLines(edited)
+
+		// After an error, leaving the file unchanged aborts instead of submitting it again
+		if errorComment != "" && bytes.Equal(bytes.TrimSpace(body), bytes.TrimSpace(retried)) {
+			sh.println("File left unchanged, edit aborted.")
+			return
+		}
+
+		var updated editableNode
+		if err := yaml.Unmarshal(body, &updated); err != nil {
+			errorComment = commentLines("ERROR: invalid YAML: " + err.Error())
+			continue
+		}
+
+		update, changed, err := diffEditableNode(original, updated)
+		if err != nil {
+			errorComment = commentLines("ERROR: " + err.Error())
+			continue
+		}
+
+		if len(changed) == 0 {
+			sh.println("No changes made.")
+			return
+		}
+
+		result, err := sh.client.UpdateNode(node.UUID, update)
+		if err != nil {
+			errorComment = commentLines("ERROR: update rejected by server:\n" + err.Error())
+			continue
+		}
+
+		sh.printf("Node %s updated successfully (%s)\n", result.UUID, strings.Join(changed, ", "))
+		return
+	}
+}
+
+// editContent downloads the node content, opens it in the editor and uploads it back if changed
+func (c *EditCommand) editContent(sh *Shell, node *antbox.Node) {
+	if folderFilter(*node) {
+		sh.eprintln("Error: folders have no content to edit")
+		return
+	}
+
+	if !isTextMimetype(node.Mimetype) {
+		sh.eprintf("Warning: %s does not look like a text mimetype\n", node.Mimetype)
+		if !sh.confirm("Edit anyway?") {
+			return
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "antx-edit-")
+	if err != nil {
+		sh.eprintln("Error creating temporary directory:", err)
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Keep the node title so the editor can pick up the file type from its extension
+	tmpPath := filepath.Join(tmpDir, filepath.Base(node.Title))
+	if err := sh.client.DownloadNode(node.UUID, tmpPath); err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	original, err := os.ReadFile(tmpPath)
+	if err != nil {
+		sh.eprintln("Error reading downloaded content:", err)
+		return
+	}
+
+	for {
+		if err := runEditor(tmpPath); err != nil {
+			sh.eprintln("Error running editor:", err)
+			return
+		}
+
+		edited, err := os.ReadFile(tmpPath)
+		if err != nil {
+			sh.eprintln("Error reading temporary file:", err)
+			return
+		}
+
+		if bytes.Equal(original, edited) {
+			sh.println("No changes made.")
+			return
+		}
+
+		result, err := sh.client.UpdateFile(node.UUID, tmpPath)
+		if err != nil {
+			sh.eprintln("Error: update rejected by server:", err)
+			if sh.confirm("Reopen the editor?") {
+				continue
+			}
+			return
+		}
+
+		sh.printf("Content of node %s updated successfully\n", result.UUID)
+		return
+	}
+}
+
+// diffEditableNode builds a NodeUpdate containing only the fields that changed
+func diffEditableNode(original, updated editableNode) (antbox.NodeUpdate, []string, error) {
+	var update antbox.NodeUpdate
+	var changed []string
+
+	if updated.Title != original.Title {
+		if strings.TrimSpace(updated.Title) == "" {
+			return update, nil, fmt.Errorf("title cannot be empty")
+		}
+		update.Title = updated.Title
+		changed = append(changed, "title")
+	}
+
+	if updated.Mimetype != original.Mimetype {
+		if strings.TrimSpace(updated.Mimetype) == "" {
+			return update, nil, fmt.Errorf("mimetype cannot be empty")
+		}
+		update.Mimetype = updated.Mimetype
+		changed = append(changed, "mimetype")
+	}
+
+	if updated.Parent != original.Parent {
+		if strings.TrimSpace(updated.Parent) == "" {
+			return update, nil, fmt.Errorf("parent cannot be empty")
+		}
+		update.Parent = updated.Parent
+		changed = append(changed, "parent")
+	}
+
+	if !permissionsEqual(original.Permissions, updated.Permissions) {
+		permissions := updated.Permissions
+		update.Permissions = &permissions
+		changed = append(changed, "permissions")
+	}
+
+	return update, changed, nil
+}
+
+// permissionsEqual compares permissions treating nil and empty collections as equal
+func permissionsEqual(a, b antbox.Permissions) bool {
+	normalize := func(p antbox.Permissions) antbox.Permissions {
+		if len(p.Group) == 0 {
+			p.Group = nil
+		}
+		if len(p.Authenticated) == 0 {
+			p.Authenticated = nil
+		}
+		if len(p.Anonymous) == 0 {
+			p.Anonymous = nil
+		}
+		if len(p.Advanced) == 0 {
+			p.Advanced = nil
+		}
+		return p
+	}
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+// runEditor opens the given file in $VISUAL or $EDITOR, falling back to vi
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor variable may contain arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// stripCommentLines removes full-line '#' comments
+func stripCommentLines(content []byte) []byte {
+	var result bytes.Buffer
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		result.WriteString(line)
+		result.WriteString("\n")
+	}
+	return result.Bytes()
+}
+
+// commentLines prefixes every line of the message with '# '
+func commentLines(message string) string {
+	var result strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(message, "\n"), "\n") {
+		result.WriteString("# ")
+		result.WriteString(line)
+		result.WriteString("\n")
+	}
+	return result.String()
+}
+
+// isTextMimetype reports whether the mimetype is safe to edit as text
+func isTextMimetype(mimetype string) bool {
+	if strings.HasPrefix(mimetype, "text/") {
+		return true
+	}
+
+	textTypes := []string{"json", "javascript", "xml", "yaml", "markdown", "csv", "sql"}
+	for _, t := range textTypes {
+		if strings.Contains(mimetype, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *EditCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	word := wordBeforeCursor(d)
+	if strings.HasPrefix(word, "-") {
+		return []prompt.Suggest{
+			{Text: "-c", Description: "Edit node content"},
+		}
+	}
+	return getNodeSuggestions(sh, word, nil)
+}
+
+func init() {
+	RegisterCommand(&EditCommand{})
+}
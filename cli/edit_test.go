@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+func TestDiffEditableNode(t *testing.T) {
+	original := editableNode{
+		Title:    "report.txt",
+		Mimetype: "text/plain",
+		Parent:   "folder-uuid",
+		Permissions: antbox.Permissions{
+			Group: []string{"read"},
+		},
+	}
+
+	// No changes should produce an empty update
+	update, changed, err := diffEditableNode(original, original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("Expected no changed fields, got %v", changed)
+	}
+
+	// Only the title changed
+	edited := original
+	edited.Title = "final-report.txt"
+	update, changed, err = diffEditableNode(original, edited)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changed) != 1 || changed[0] != "title" {
+		t.Errorf("Expected only 'title' to change, got %v", changed)
+	}
+	if update.Title != "final-report.txt" {
+		t.Errorf("Expected title 'final-report.txt', got '%s'", update.Title)
+	}
+	if update.Mimetype != "" || update.Parent != "" || update.Permissions != nil {
+		t.Errorf("Expected unchanged fields to be omitted, got %+v", update)
+	}
+
+	// Permissions changed
+	edited = original
+	edited.Permissions.Anonymous = []string{"read"}
+	update, changed, _ = diffEditableNode(original, edited)
+	if len(changed) != 1 || changed[0] != "permissions" || update.Permissions == nil {
+		t.Errorf("Expected permissions to change, got %v", changed)
+	}
+
+	// Empty title is rejected
+	edited = original
+	edited.Title = "  "
+	if _, _, err := diffEditableNode(original, edited); err == nil {
+		t.Error("Expected error for empty title")
+	}
+}
+
+func TestPermissionsEqualIgnoresEmptyCollections(t *testing.T) {
+	a := antbox.Permissions{Group: []string{}, Advanced: map[string]any{}}
+	b := antbox.Permissions{}
+	if !permissionsEqual(a, b) {
+		t.Error("Expected empty and nil permissions to be equal")
+	}
+}
+
+func TestStripCommentLines(t *testing.T) {
+	input := "# header\n# ERROR: bad\ntitle: abc\n  # indented comment\nparent: xyz\n"
+	result := string(stripCommentLines([]byte(input)))
+	if strings.Contains(result, "#") {
+		t.Errorf("Expected comments to be removed, got %q", result)
+	}
+	if !strings.Contains(result, "title: abc") || !strings.Contains(result, "parent: xyz") {
+		t.Errorf("Expected content lines to be kept, got %q", result)
+	}
+}
@@ -1,8 +1,6 @@
 package cli
 
 import (
-	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/c-bata/go-prompt"
@@ -18,66 +16,48 @@ func (c *ExecCommand) GetDescription() string {
 	return "Run an extension with optional parameters"
 }
 
-func (c *ExecCommand) Execute(args []string) {
+func (c *ExecCommand) Execute(sh *Shell, args []string) {
 	if len(args) < 1 {
-		fmt.Println("Usage: exec <extension_uuid> [param=value...]")
-		fmt.Println()
-		fmt.Println("Arguments:")
-		fmt.Println("  extension_uuid: UUID of the extension to run")
-		fmt.Println("  param=value: Optional parameters in key=value format")
-		fmt.Println()
-		fmt.Println("Examples:")
-		fmt.Println("  exec abc123")
-		fmt.Println("  exec abc123 input=hello format=json timeout=30")
+		sh.println("Usage: exec <extension_uuid> [param=value...]")
+		sh.println()
+		sh.println("Arguments:")
+		sh.println("  extension_uuid: UUID of the extension to run")
+		sh.println("  param=value: Parameters in key=value format, converted to the declared type")
+		sh.println()
+		sh.println("Arrays and objects are given as JSON (or a comma separated list for arrays).")
+		sh.println("File parameters accept a local path, which is uploaded first.")
+		sh.println("Missing required parameters are asked for interactively.")
+		sh.println()
+		sh.println("Examples:")
+		sh.println("  exec abc123")
+		sh.println("  exec abc123 input=hello format=json timeout=30")
 		return
 	}
 
 	extensionUUID := args[0]
 
-	// Parse parameters from remaining arguments
-	parameters := make(map[string]any)
-	for i := 1; i < len(args); i++ {
-		param := args[i]
-		if strings.Contains(param, "=") {
-			parts := strings.SplitN(param, "=", 2)
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-
-			// Try to convert value to appropriate type
-			if value == "true" {
-				parameters[key] = true
-			} else if value == "false" {
-				parameters[key] = false
-			} else {
-				// Try to parse as number, otherwise keep as string
-				if intVal, err := strconv.Atoi(value); err == nil {
-					parameters[key] = intVal
-				} else if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
-					parameters[key] = floatVal
-				} else {
-					parameters[key] = value
-				}
-			}
-		} else {
-			fmt.Printf("Warning: Ignoring invalid parameter format: %s (expected key=value)\n", param)
-		}
+	// Coerce parameters using the types declared by the extension
+	declared := findFeatureParameters(sh, extensionUUID, sh.GetCachedExtensions())
+	parameters, err := resolveParameters(sh, declared, parseParameterArgs(sh, args[1:]))
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
 	}
 
 	// Execute the extension
-	result, err := client.RunExtension(extensionUUID, parameters)
+	result, err := sh.client.RunExtension(extensionUUID, parameters)
 	if err != nil {
-		fmt.Println("Error running extension:", err)
+		sh.eprintln("Error running extension:", err)
 		return
 	}
 
 	// Display the result
-	fmt.Println("Extension executed successfully:")
-	printExtensionResult(result)
+	sh.println("Extension executed successfully:")
+	printExtensionResult(sh, result)
 }
 
-func (c *ExecCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	text := d.TextBeforeCursor()
-	args := strings.Fields(text)
+func (c *ExecCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := fieldsBeforeCursor(d)
 
 	if len(args) == 0 {
 		return []prompt.Suggest{}
@@ -85,16 +65,16 @@ func (c *ExecCommand) Suggest(d prompt.Document) []prompt.Suggest {
 
 	// Count actual arguments (excluding the command name)
 	argCount := len(args) - 1
-	if !strings.HasSuffix(text, " ") && len(args) > 1 {
+	if !cursorAfterBlank(d) && len(args) > 1 {
 		argCount = len(args) - 2 // We're still typing the current argument
 	}
 
 	switch argCount {
 	case 0:
 		// Suggesting extension UUID - use cached extensions
-		extensions := GetCachedExtensions()
+		extensions := sh.GetCachedExtensions()
 		var suggests []prompt.Suggest
-		currentWord := d.GetWordBeforeCursor()
+		currentWord := wordBeforeCursor(d)
 		for _, extension := range extensions {
 			if strings.HasPrefix(strings.ToLower(extension.UUID), strings.ToLower(currentWord)) ||
 				strings.HasPrefix(strings.ToLower(extension.Name), strings.ToLower(currentWord)) {
@@ -106,36 +86,29 @@ func (c *ExecCommand) Suggest(d prompt.Document) []prompt.Suggest {
 		}
 		return suggests
 	default:
-		// Suggesting parameters - show common parameter examples
-		currentWord := d.GetWordBeforeCursor()
-		if !strings.Contains(currentWord, "=") {
-			return []prompt.Suggest{
-				{Text: "input=", Description: "Input data parameter"},
-				{Text: "format=", Description: "Output format parameter"},
-				{Text: "timeout=", Description: "Timeout parameter"},
-				{Text: "config=", Description: "Configuration parameter"},
-				{Text: "mode=", Description: "Mode parameter"},
-			}
+		// Suggesting parameters based on the extension's parameter definitions
+		extension := findCachedFeature(args[1], sh.GetCachedExtensions())
+		if extension == nil {
+			return []prompt.Suggest{}
 		}
+		return getParameterSuggestions(wordBeforeCursor(d), extension.Parameters)
 	}
-
-	return []prompt.Suggest{}
 }
 
-func printExtensionResult(result any) {
+func printExtensionResult(sh *Shell, result any) {
 	switch v := result.(type) {
 	case string:
-		fmt.Printf("  %s\n", v)
+		sh.printf("  %s\n", v)
 	case map[string]any:
 		for key, value := range v {
-			fmt.Printf("  %s: %v\n", key, value)
+			sh.printf("  %s: %v\n", key, value)
 		}
 	case []any:
 		for i, item := range v {
-			fmt.Printf("  [%d]: %v\n", i, item)
+			sh.printf("  [%d]: %v\n", i, item)
 		}
 	default:
-		fmt.Printf("  %v\n", v)
+		sh.printf("  %v\n", v)
 	}
 }
 
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/c-bata/go-prompt"
@@ -17,12 +16,13 @@ func (c *ExitCommand) GetDescription() string {
 	return "Exit the CLI"
 }
 
-func (c *ExitCommand) Execute(args []string) {
-	fmt.Println("Bye!")
+func (c *ExitCommand) Execute(sh *Shell, args []string) {
+	sh.savePersistedCache()
+	sh.println("Bye!")
 	os.Exit(0)
 }
 
-func (c *ExitCommand) Suggest(d prompt.Document) []prompt.Suggest {
+func (c *ExitCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
 	return []prompt.Suggest{}
 }
 
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"fmt"
 	"sort"
 
 	"github.com/c-bata/go-prompt"
@@ -17,15 +16,21 @@ func (c *ExtensionsCommand) GetDescription() string {
 	return "List all available extensions"
 }
 
-func (c *ExtensionsCommand) Execute(args []string) {
-	extensions, err := client.ListExtensions()
+func (c *ExtensionsCommand) Execute(sh *Shell, args []string) {
+	opts, _, err := parseListFlags(sh, args)
 	if err != nil {
-		fmt.Println("Error listing extensions:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
 
-	if len(extensions) == 0 {
-		fmt.Println("No extensions available.")
+	extensions, err := sh.client.ListExtensions()
+	if err != nil {
+		sh.eprintln("Error listing extensions:", err)
+		return
+	}
+
+	if len(extensions) == 0 && opts.forPeople() && !sh.piping {
+		sh.println("No extensions available.")
 		return
 	}
 
@@ -34,45 +39,12 @@ func (c *ExtensionsCommand) Execute(args []string) {
 		return extensions[i].Name < extensions[j].Name
 	})
 
-	fmt.Printf("Available extensions (%d):\n", len(extensions))
-	fmt.Println()
-
-	for _, extension := range extensions {
-		fmt.Printf("UUID: %s\n", extension.UUID)
-		fmt.Printf("  Name: %s\n", extension.Name)
-		if extension.Description != "" {
-			fmt.Printf("  Description: %s\n", extension.Description)
-		}
-		if extension.RunAs != "" {
-			fmt.Printf("  Run As: %s\n", extension.RunAs)
-		}
-		if len(extension.GroupsAllowed) > 0 {
-			fmt.Printf("  Groups Allowed: %v\n", extension.GroupsAllowed)
-		}
-		if len(extension.Parameters) > 0 {
-			fmt.Printf("  Parameters:\n")
-			for _, param := range extension.Parameters {
-				required := ""
-				if param.Required {
-					required = " (required)"
-				}
-				fmt.Printf("    - %s (%s)%s: %s\n", param.Name, param.Type, required, param.Description)
-				if param.DefaultValue != nil {
-					fmt.Printf("      Default: %v\n", param.DefaultValue)
-				}
-			}
-		}
-		if extension.ReturnType != "" {
-			fmt.Printf("  Return Type: %s\n", extension.ReturnType)
-		}
-		if extension.ReturnDescription != "" {
-			fmt.Printf("  Return Description: %s\n", extension.ReturnDescription)
-		}
-		fmt.Println()
+	if err := renderList(sh, opts, extensions, featureColumns()); err != nil {
+		sh.eprintln("Error:", err)
 	}
 }
 
-func (c *ExtensionsCommand) Suggest(d prompt.Document) []prompt.Suggest {
+func (c *ExtensionsCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
 	// This command doesn't take arguments, so no suggestions needed
 	return []prompt.Suggest{}
 }
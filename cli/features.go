@@ -0,0 +1,383 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
+)
+
+// featureWatchInterval is how often `features dev` checks the watched file for changes
+const featureWatchInterval = 500 * time.Millisecond
+
+type FeaturesCommand struct{}
+
+func (c *FeaturesCommand) GetName() string {
+	return "features"
+}
+
+func (c *FeaturesCommand) GetDescription() string {
+	return "List, inspect, export, delete and live-deploy features"
+}
+
+func (c *FeaturesCommand) Execute(sh *Shell, args []string) {
+	if len(args) == 0 {
+		c.listFeatures(sh)
+		return
+	}
+
+	subcommand := args[0]
+	switch subcommand {
+	case "list":
+		c.listFeatures(sh)
+	case "show":
+		if len(args) < 2 {
+			sh.println("Usage: features show <uuid>")
+			return
+		}
+		c.showFeature(sh, args[1])
+	case "export":
+		if len(args) < 2 {
+			sh.println("Usage: features export <uuid> [file]")
+			return
+		}
+		outputPath := ""
+		if len(args) > 2 {
+			outputPath = strings.Join(args[2:], " ")
+		}
+		c.exportFeature(sh, args[1], outputPath)
+	case "rm":
+		if len(args) < 2 {
+			sh.println("Usage: features rm <uuid>")
+			return
+		}
+		c.removeFeature(sh, args[1])
+	case "dev":
+		if len(args) < 2 {
+			sh.println("Usage: features dev <file.js>")
+			return
+		}
+		c.watchFeature(sh, strings.Join(args[1:], " "))
+	case "help", "-h":
+		c.showUsage(sh)
+	default:
+		sh.printf("Unknown subcommand: %s\n", subcommand)
+		c.showUsage(sh)
+	}
+}
+
+func (c *FeaturesCommand) showUsage(sh *Shell) {
+	sh.println("Usage: features <subcommand> [args]")
+	sh.println()
+	sh.println("Subcommands:")
+	sh.println("  list                  List all features (default)")
+	sh.println("  show <uuid>           Show all feature details")
+	sh.println("  export <uuid> [file]  Export the feature source")
+	sh.println("  rm <uuid>             Delete a feature")
+	sh.println("  dev <file.js>         Watch a local file and re-upload it on every save")
+	sh.println()
+	sh.println("Examples:")
+	sh.println("  features")
+	sh.println("  features show copy_to_folder")
+	sh.println("  features export copy_to_folder ./copy_to_folder.js")
+	sh.println("  features dev ./copy_to_folder.js")
+}
+
+func (c *FeaturesCommand) listFeatures(sh *Shell) {
+	features, err := sh.client.ListFeatures()
+	if err != nil {
+		sh.eprintln("Error listing features:", err)
+		return
+	}
+
+	if len(features) == 0 {
+		sh.println("No features available.")
+		return
+	}
+
+	sort.Slice(features, func(i, j int) bool {
+		return features[i].Name < features[j].Name
+	})
+
+	sh.printf("Available features (%d):\n", len(features))
+	sh.println()
+
+	for _, feature := range features {
+		sh.printf("UUID: %s\n", feature.UUID)
+		sh.printf("  Name: %s\n", feature.Name)
+		if feature.Description != "" {
+			sh.printf("  Description: %s\n", feature.Description)
+		}
+		if exposures := featureExposures(feature); len(exposures) > 0 {
+			sh.printf("  Exposed As: %s\n", strings.Join(exposures, ", "))
+		}
+		sh.println()
+	}
+}
+
+func (c *FeaturesCommand) showFeature(sh *Shell, uuid string) {
+	feature, err := sh.client.GetFeature(uuid)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	printFeature(sh, feature)
+}
+
+// printFeature prints every field of a feature
+func printFeature(sh *Shell, feature *antbox.Feature) {
+	template := "%-16s: %v\n"
+	sh.printf(template, "UUID", feature.UUID)
+	sh.printf(template, "Name", feature.Name)
+	if feature.Description != "" {
+		sh.printf(template, "Description", feature.Description)
+	}
+
+	exposures := featureExposures(*feature)
+	if len(exposures) == 0 {
+		exposures = []string{"none"}
+	}
+	sh.printf(template, "Exposed As", strings.Join(exposures, ", "))
+
+	sh.printf(template, "Run Manually", feature.RunManually)
+	sh.printf(template, "Run on Creates", feature.RunOnCreates)
+	sh.printf(template, "Run on Updates", feature.RunOnUpdates)
+	if feature.RunAs != "" {
+		sh.printf(template, "Run As", feature.RunAs)
+	}
+	if len(feature.GroupsAllowed) > 0 {
+		sh.printf(template, "Groups Allowed", strings.Join(feature.GroupsAllowed, ", "))
+	}
+	if feature.Filters != nil {
+		if filters, err := json.Marshal(feature.Filters); err == nil {
+			sh.printf(template, "Filters", string(filters))
+		}
+	}
+
+	returnType := feature.ReturnType
+	if returnType == "" {
+		returnType = "void"
+	}
+	if feature.ReturnContentType != "" {
+		returnType = fmt.Sprintf("%s (%s)", returnType, feature.ReturnContentType)
+	}
+	sh.printf(template, "Returns", returnType)
+	if feature.ReturnDescription != "" {
+		sh.printf(template, "Return Details", feature.ReturnDescription)
+	}
+
+	if len(feature.Parameters) == 0 {
+		sh.printf(template, "Parameters", "none")
+		return
+	}
+
+	sh.printf(template, "Parameters", len(feature.Parameters))
+	for _, param := range feature.Parameters {
+		required := ""
+		if param.Required {
+			required = " (required)"
+		}
+		sh.printf("  - %s (%s)%s", param.Name, param.Type, required)
+		if param.Description != "" {
+			sh.printf(": %s", param.Description)
+		}
+		sh.println()
+		if param.DefaultValue != nil {
+			sh.printf("      Default: %v\n", param.DefaultValue)
+		}
+	}
+}
+
+// featureExposures returns the ways a feature is exposed to users
+func featureExposures(feature antbox.Feature) []string {
+	var exposures []string
+	if feature.ExposeAsAction {
+		exposures = append(exposures, "action")
+	}
+	if feature.ExposeAsExtension {
+		exposures = append(exposures, "extension")
+	}
+	if feature.ExposeAITool {
+		exposures = append(exposures, "AI tool")
+	}
+	return exposures
+}
+
+func (c *FeaturesCommand) exportFeature(sh *Shell, uuid string, outputPath string) {
+	source, err := sh.client.ExportFeature(uuid, "")
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	if outputPath == "" {
+		sh.println(source)
+		return
+	}
+
+	if err := os.WriteFile(outputPath, []byte(source), 0644); err != nil {
+		sh.eprintln("Error writing file:", err)
+		return
+	}
+
+	sh.printf("Feature %s exported to %s\n", uuid, outputPath)
+}
+
+func (c *FeaturesCommand) removeFeature(sh *Shell, uuid string) {
+	if err := sh.client.DeleteFeature(uuid); err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	// Drop the feature from the action and extension completion caches
+	isRemoved := func(f antbox.Feature) bool { return f.UUID == uuid }
+	sh.updateResources(func() {
+		sh.actions = slices.DeleteFunc(sh.actions, isRemoved)
+		sh.extensions = slices.DeleteFunc(sh.extensions, isRemoved)
+	})
+
+	sh.printf("Feature %s removed successfully\n", uuid)
+}
+
+// watchFeature uploads the file and then re-uploads it every time it is saved, until Ctrl+C
+func (c *FeaturesCommand) watchFeature(sh *Shell, filePath string) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+	if info.IsDir() {
+		sh.eprintf("Error: %s is a directory\n", filePath)
+		return
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	sh.printf("Watching %s for changes. Press Ctrl+C to stop.\n", filePath)
+	deployFeature(sh, filePath)
+
+	lastModTime := info.ModTime()
+	lastSize := info.Size()
+
+	ticker := time.NewTicker(featureWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-interrupt:
+			sh.println()
+			sh.println("Stopped watching", filePath)
+			refreshFeatureCaches(sh)
+			return
+		case <-ticker.C:
+			info, err := os.Stat(filePath)
+			if err != nil {
+				// Editors often replace the file on save, so it may briefly disappear
+				continue
+			}
+			if info.ModTime().Equal(lastModTime) && info.Size() == lastSize {
+				continue
+			}
+			lastModTime = info.ModTime()
+			lastSize = info.Size()
+			deployFeature(sh, filePath)
+		}
+	}
+}
+
+// deployFeature uploads the feature file and reports the outcome
+func deployFeature(sh *Shell, filePath string) {
+	timestamp := time.Now().Format("15:04:05")
+
+	feature, err := sh.client.UploadFeature(filePath)
+	if err != nil {
+		sh.eprintf("[%s] ✗ Deploy failed: %v\n", timestamp, err)
+		return
+	}
+
+	sh.printf("[%s] ✓ Deployed %s (%s)\n", timestamp, feature.Name, feature.UUID)
+}
+
+// refreshFeatureCaches reloads the cached actions and extensions used for completion
+func refreshFeatureCaches(sh *Shell) {
+	if actions, err := sh.client.ListActions(); err == nil {
+		sh.updateResources(func() { sh.actions = actions })
+	}
+	if extensions, err := sh.client.ListExtensions(); err == nil {
+		sh.updateResources(func() { sh.extensions = extensions })
+	}
+}
+
+func (c *FeaturesCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := fieldsBeforeCursor(d)
+
+	if len(args) == 0 {
+		return []prompt.Suggest{}
+	}
+
+	// Count actual arguments (excluding the command name)
+	argCount := len(args) - 1
+	if !cursorAfterBlank(d) && len(args) > 1 {
+		argCount = len(args) - 2 // We're still typing the current argument
+	}
+
+	currentWord := wordBeforeCursor(d)
+
+	switch argCount {
+	case 0:
+		subcommands := []prompt.Suggest{
+			{Text: "list", Description: "List all features"},
+			{Text: "show", Description: "Show feature details"},
+			{Text: "export", Description: "Export the feature source"},
+			{Text: "rm", Description: "Delete a feature"},
+			{Text: "dev", Description: "Watch a local file and re-upload on save"},
+		}
+
+		var filtered []prompt.Suggest
+		for _, cmd := range subcommands {
+			if strings.HasPrefix(strings.ToLower(cmd.Text), strings.ToLower(currentWord)) {
+				filtered = append(filtered, cmd)
+			}
+		}
+		return filtered
+	case 1:
+		subcommand := args[1]
+		if subcommand != "show" && subcommand != "export" && subcommand != "rm" {
+			return []prompt.Suggest{}
+		}
+
+		// Actions and extensions are the features we keep cached
+		seen := make(map[string]bool)
+		var suggests []prompt.Suggest
+		for _, feature := range slices.Concat(sh.GetCachedActions(), sh.GetCachedExtensions()) {
+			if seen[feature.UUID] {
+				continue
+			}
+			seen[feature.UUID] = true
+			if strings.HasPrefix(strings.ToLower(feature.UUID), strings.ToLower(currentWord)) ||
+				strings.HasPrefix(strings.ToLower(feature.Name), strings.ToLower(currentWord)) {
+				suggests = append(suggests, prompt.Suggest{
+					Text:        feature.UUID,
+					Description: feature.Name,
+				})
+			}
+		}
+		return suggests
+	}
+
+	return []prompt.Suggest{}
+}
+
+func init() {
+	RegisterCommand(&FeaturesCommand{})
+}
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/c-bata/go-prompt"
@@ -17,60 +16,64 @@ func (c *FindCommand) GetDescription() string {
 	return "Find nodes using filter criteria"
 }
 
-func (c *FindCommand) Execute(args []string) {
+func (c *FindCommand) Execute(sh *Shell, args []string) {
 	if len(args) == 0 {
-		fmt.Println("Usage: find <criteria>")
-		fmt.Println("  Simple: find some text")
-		fmt.Println("  Complex: find title == Document,owner ~= admin,size > 1000")
+		sh.println("Usage: find [-o table|wide|json|yaml|csv] [--columns a,b] [--template T] [--full-uuid] <criteria>")
+		sh.println("  Simple: find some text")
+		sh.println("  Complex: find title == Document,owner ~= admin,size '>' 1000")
+		sh.println("  Quote > so it isn't read as a redirection; >= can be typed as it is.")
+		return
+	}
+
+	opts, args, err := parseNodeListFlags(sh, args)
+	if err != nil {
+		sh.eprintln("Error:", err)
 		return
 	}
 
 	searchText := strings.Join(args, " ")
+	if condition, ok := incompleteCondition(searchText); ok {
+		sh.eprintf("Error: condition '%s' has no operator. Quote > so it isn't read as a redirection: %s '>' 1000\n", condition, condition)
+		return
+	}
 
-	result, err := client.FindNodes(searchText, 20, 1)
+	result, err := sh.client.FindNodes(searchText, 20, 1)
 	if err != nil {
-		fmt.Println("Error:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
-	if len(result.Nodes) == 0 {
-		fmt.Println("No nodes found matching the criteria")
+	if len(result.Nodes) == 0 && opts.forPeople() && !sh.piping {
+		sh.println("No nodes found matching the criteria")
 		return
 	}
 
-	fmt.Printf("Found %d nodes:\n", len(result.Nodes))
-	fmt.Printf(" %-12s  %4s  %-12s  %-30s  %s\n", "UUID", "SIZE", "MODIFIED", "MIMETYPE", "TITLE")
-	fmt.Printf(" %-12s  %4s  %-12s  %-30s  %s\n", "----", "----", "--------", "--------", "-----")
+	if opts.forPeople() {
+		sh.printf("Found %d nodes:\n", len(result.Nodes))
+	}
 
 	// Sort nodes: directories first, then files, both alphabetically by title
-	sortedNodes := sortNodesForListing(result.Nodes)
-
-	for _, node := range sortedNodes {
-		// Format UUID (first 12 characters)
-		uuid := node.UUID
-		if len(uuid) > 12 {
-			uuid = uuid[:12]
-		}
-
-		// Format size with padding
-		size := node.HumanReadableSize()
-
-		// Format modified date
-		modifiedAt := formatModifiedDate(node.ModifiedAt)
-
-		// Format mimetype (max 30 characters with ellipsis)
-		mimetype := node.Mimetype
-		if len(mimetype) > 30 {
-			mimetype = fmt.Sprintf("%s...", mimetype[:27])
-		}
-
-		// Title is free form (no truncation)
-		title := node.Title
+	if err := renderList(sh, opts, sortNodesForListing(result.Nodes), nodeColumns); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
 
-		fmt.Printf(" %-12s  %4s  %-12s  %-30s  %s\n", uuid, size, modifiedAt, mimetype, title)
+// incompleteCondition returns the last condition of a filter such as "title == Doc,size" when
+// it is a lone field after conditions, which is what is left when an unquoted > is read as a
+// redirection. Text searches with commas are left alone.
+func incompleteCondition(filter string) (string, bool) {
+	conditions := strings.Split(filter, ",")
+	last := strings.TrimSpace(conditions[len(conditions)-1])
+	if len(conditions) < 2 || last == "" || strings.ContainsAny(last, " \t") {
+		return "", false
+	}
+	first := extractSingleFilter(normalizeOperators(conditions[0]))[0]
+	if field, _ := first[0].(string); field == ":content" {
+		return "", false
 	}
+	return last, true
 }
 
-func (c *FindCommand) Suggest(d prompt.Document) []prompt.Suggest {
+func (c *FindCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
 	return []prompt.Suggest{}
 }
 
@@ -0,0 +1,25 @@
+package cli
+
+import "testing"
+
+func TestIncompleteCondition(t *testing.T) {
+	testCases := []struct {
+		filter    string
+		condition string
+		ok        bool
+	}{
+		{"title == Document,size", "size", true},
+		{"title == Document,size > 1000", "", false},
+		{"annual report", "", false},
+		{"size", "", false},
+		{"title == Document,", "", false},
+		{"smith,john", "", false},
+	}
+
+	for _, tc := range testCases {
+		condition, ok := incompleteCondition(tc.filter)
+		if condition != tc.condition || ok != tc.ok {
+			t.Errorf("%q: expected %q, %v, got %q, %v", tc.filter, tc.condition, tc.ok, condition, ok)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+type HeadCommand struct{}
+
+func (c *HeadCommand) GetName() string {
+	return "head"
+}
+
+func (c *HeadCommand) GetDescription() string {
+	return "Keep the first piped records"
+}
+
+// defaultHeadCount is the number of records head keeps when no count is given
+const defaultHeadCount = 10
+
+func (c *HeadCommand) Execute(sh *Shell, args []string) {
+	count := defaultHeadCount
+	if len(args) > 0 && args[0] == "-n" {
+		args = args[1:]
+	}
+	if len(args) > 0 {
+		n, err := strconv.Atoi(strings.TrimPrefix(args[0], "-"))
+		if err != nil || n < 0 {
+			sh.eprintln("Error: invalid count:", args[0])
+			return
+		}
+		count = n
+	}
+	if len(args) > 1 || sh.input == nil {
+		sh.printf("Usage: <command> | head [-n] [count]  (%d records by default)\n", defaultHeadCount)
+		sh.println("  Example: ls | sort -r size | head 5")
+		return
+	}
+
+	recs := sh.input
+
+	items := recs.items[:min(count, len(recs.items))]
+	if err := sh.renderRecords(recs.with(items)); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
+
+func (c *HeadCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	return []prompt.Suggest{}
+}
+
+func init() {
+	RegisterCommand(&HeadCommand{})
+}
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"fmt"
 	"sort"
 	"strings"
 
@@ -18,22 +17,22 @@ func (c *HelpCommand) GetDescription() string {
 	return "Show this help message"
 }
 
-func (c *HelpCommand) Execute(args []string) {
+func (c *HelpCommand) Execute(sh *Shell, args []string) {
 	// If a specific command is requested, show detailed help
 	if len(args) > 0 {
 		cmdName := args[0]
 		if cmd, exists := commands[cmdName]; exists {
-			fmt.Printf("Command: %s\n", cmd.GetName())
-			fmt.Printf("Description: %s\n", cmd.GetDescription())
-			fmt.Println()
+			sh.printf("Command: %s\n", cmd.GetName())
+			sh.printf("Description: %s\n", cmd.GetDescription())
+			sh.println()
 
 			// Execute the command with no args to show its usage
-			fmt.Println("Usage:")
-			cmd.Execute([]string{})
+			sh.println("Usage:")
+			cmd.Execute(sh, []string{})
 		} else {
-			fmt.Printf("Unknown command: %s\n", cmdName)
-			fmt.Println()
-			fmt.Println("Available commands:")
+			sh.printf("Unknown command: %s\n", cmdName)
+			sh.println()
+			sh.println("Available commands:")
 
 			// Show just a simple alphabetical list for unknown commands
 			var cmdNames []string
@@ -43,26 +42,28 @@ func (c *HelpCommand) Execute(args []string) {
 			sort.Strings(cmdNames)
 
 			for _, name := range cmdNames {
-				fmt.Printf("  %s\n", name)
+				sh.printf("  %s\n", name)
 			}
 		}
 		return
 	}
 
-	fmt.Println("Antbox CLI - Available Commands")
-	fmt.Println("===============================")
-	fmt.Println()
+	sh.println("Antbox CLI - Available Commands")
+	sh.println("===============================")
+	sh.println()
 
 	// Define command categories
 	categories := map[string][]string{
-		"Navigation & Browsing": {"cd", "ls", "pwd", "find", "stat"},
-		"File Operations":       {"cp", "duplicate", "mv", "rename", "rm", "upload", "download"},
+		"Navigation & Browsing": {"cd", "ls", "tree", "du", "pwd", "find", "stat"},
+		"File Operations":       {"cp", "duplicate", "edit", "mv", "rename", "rm", "upload", "download"},
 		"Folder Management":     {"mkdir", "mksmart"},
 		"Actions & Extensions":  {"run", "exec", "actions", "extensions"},
-		"AI & Agents":           {"chat", "answer", "rag", "agents"},
+		"Aspects & Features":    {"aspects", "features"},
+		"AI & Agents":           {"chat", "answer", "rag", "agents", "tools"},
 		"Session Management":    {"sessions"},
 		"Templates & Docs":      {"templates", "docs"},
-		"System Management":     {"aliases", "history", "reload", "status", "help", "exit"},
+		"Pipelines":             {"where", "select", "sort", "head", "count"},
+		"System Management":     {"aliases", "config", "history", "reload", "set", "status", "help", "exit"},
 	}
 
 	// Print commands by category
@@ -71,12 +72,14 @@ func (c *HelpCommand) Execute(args []string) {
 		"File Operations",
 		"Folder Management",
 		"Actions & Extensions",
+		"Aspects & Features",
 		"AI & Agents",
 		"Session Management",
 		"Templates & Docs",
+		"Pipelines",
 		"System Management",
 	} {
-		fmt.Printf("%s:\n", category)
+		sh.printf("%s:\n", category)
 
 		// Sort commands within category
 		cmdNames := categories[category]
@@ -84,10 +87,10 @@ func (c *HelpCommand) Execute(args []string) {
 
 		for _, name := range cmdNames {
 			if cmd, exists := commands[name]; exists {
-				fmt.Printf("  %-12s - %s\n", cmd.GetName(), cmd.GetDescription())
+				sh.printf("  %-12s - %s\n", cmd.GetName(), cmd.GetDescription())
 			}
 		}
-		fmt.Println()
+		sh.println()
 	}
 
 	// Show any uncategorized commands
@@ -110,31 +113,34 @@ func (c *HelpCommand) Execute(args []string) {
 
 	// Display uncategorized commands if any exist
 	if len(uncategorized) > 0 {
-		fmt.Println("Other Commands:")
+		sh.println("Other Commands:")
 		sort.Strings(uncategorized)
 		for _, name := range uncategorized {
 			cmd := commands[name]
-			fmt.Printf("  %-12s - %s\n", cmd.GetName(), cmd.GetDescription())
+			sh.printf("  %-12s - %s\n", cmd.GetName(), cmd.GetDescription())
 		}
-		fmt.Println()
+		sh.println()
 	}
 
-	fmt.Printf("Type 'help <command>' for detailed usage information. (%d commands total)\n", len(commands))
-	fmt.Println("Use Tab completion for command and argument suggestions.")
+	sh.printf("Type 'help <command>' for detailed usage information. (%d commands total)\n", len(commands))
+	sh.println("Use Tab completion for command and argument suggestions.")
+	sh.println(`Quote arguments with spaces, as in rename <uuid> "Q3  Report"; # starts a comment.`)
+	sh.println("Connect commands with | (ls | where mimetype ~= pdf | head 5) and write the output")
+	sh.println("to a local file with > file or >> file. Use variables with set name=value and $name;")
+	sh.println("$_ is the node last created or selected, $1, $2... the rows of the last listing.")
 }
 
-func (c *HelpCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	text := d.TextBeforeCursor()
-	args := strings.Fields(text)
+func (c *HelpCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := fieldsBeforeCursor(d)
 
 	if len(args) == 0 {
 		return []prompt.Suggest{}
 	}
 
 	// If we're typing the first argument after "help", suggest command names
-	if len(args) == 2 || (len(args) == 1 && strings.HasSuffix(text, " ")) {
+	if len(args) == 2 || (len(args) == 1 && cursorAfterBlank(d)) {
 		var suggests []prompt.Suggest
-		currentWord := d.GetWordBeforeCursor()
+		currentWord := wordBeforeCursor(d)
 
 		// Get all command names and sort them
 		var cmdNames []string
@@ -0,0 +1,362 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// token is an argument or an operator of a command line
+type token struct {
+	value    string // the argument without its quotes and escapes, or the operator
+	quoted   bool   // part of the argument was quoted or escaped
+	operator bool   // the token is |, > or >>
+	start    int    // offset of the token in the line
+	end      int    // offset just after the token
+}
+
+// expander gives the values of the $name references and $(command) substitutions of a
+// command line. Undefined references are kept as written, so text such as a price still reads
+// the same.
+type expander interface {
+	variable(name string) (string, bool)
+	substitute(command string) (string, error)
+}
+
+// tokenize splits a command line into arguments the way a POSIX shell does. Blanks separate
+// arguments. Single quotes keep everything up to the next single quote as it is. Double quotes
+// do too, except for \", \\, \$ and \` escapes. Outside quotes a backslash keeps the next
+// character as it is, and # at the start of an argument comments out the rest of the line.
+// Unquoted | is an operator, and so are > and >> at the start of an argument (>= isn't, so
+// filters such as size >= 10 can be typed). An unterminated quote or a trailing backslash is
+// an error; the tokens read so far are returned with it, the last one being the unterminated
+// argument. $name references and $(command) substitutions are kept as they are, see expand.
+func tokenize(line string) ([]token, error) {
+	return lex(line, nil)
+}
+
+// expand tokenizes a command line like tokenize, replacing $name, ${name} and $(command)
+// outside single quotes with their values. Unquoted values are split at blanks into several
+// arguments; values inside double quotes stay a single argument.
+func expand(line string, exp expander) ([]token, error) {
+	return lex(line, exp)
+}
+
+func lex(line string, exp expander) ([]token, error) {
+	var (
+		tokens  []token
+		current token
+		value   strings.Builder
+		inToken bool
+		quote   rune // the quote being read, 0 outside quotes
+		escaped bool
+		next    int // offset of the next rune to read, after an operator or a reference
+	)
+
+	begin := func(i int) {
+		if !inToken {
+			inToken = true
+			current = token{start: i}
+			value.Reset()
+		}
+	}
+	end := func(i int) {
+		if inToken {
+			current.value, current.end = value.String(), i
+			tokens = append(tokens, current)
+			inToken = false
+		}
+	}
+
+	for i, r := range line {
+		if i < next {
+			continue
+		}
+		switch {
+		case escaped:
+			escaped = false
+			if quote == '"' && !strings.ContainsRune("\"\\$`", r) {
+				value.WriteRune('\\')
+			}
+			value.WriteRune(r)
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				value.WriteRune(r)
+			}
+		case r == '$' && referenceLength(line[i:]) > 0:
+			next = i + referenceLength(line[i:])
+			result := line[i:next]
+			if exp != nil {
+				var err error
+				if result, err = resolveReference(result, exp); err != nil {
+					return nil, err
+				}
+			}
+			if quote == '"' || exp == nil {
+				begin(i)
+				value.WriteString(result)
+				break
+			}
+			// Unquoted values are split into arguments
+			if strings.TrimLeft(result, " \t\n\r") != result {
+				end(i)
+			}
+			for j, field := range strings.Fields(result) {
+				if j > 0 {
+					end(i)
+				}
+				begin(i)
+				current.quoted = true
+				value.WriteString(field)
+			}
+			if strings.TrimRight(result, " \t\n\r") != result {
+				end(next)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				value.WriteRune(r)
+			}
+		case r == '\\':
+			begin(i)
+			current.quoted = true
+			escaped = true
+		case r == '\'' || r == '"':
+			begin(i)
+			current.quoted = true
+			quote = r
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			end(i)
+		case r == '|' || (r == '>' && !inToken && !strings.HasPrefix(line[i:], ">=")):
+			end(i)
+			op := string(r)
+			if strings.HasPrefix(line[i:], ">>") {
+				op = ">>"
+			}
+			tokens = append(tokens, token{value: op, operator: true, start: i, end: i + len(op)})
+			next = i + len(op)
+		case r == '#' && !inToken:
+			return tokens, nil
+		default:
+			begin(i)
+			value.WriteRune(r)
+		}
+	}
+
+	var err error
+	switch {
+	case quote != 0:
+		err = fmt.Errorf("unterminated %c quote", quote)
+	case escaped:
+		err = errors.New("unterminated escape at the end of the line")
+	}
+	end(len(line))
+	return tokens, err
+}
+
+// referenceLength returns the length of the $name, ${name} or $(command) reference s starts
+// with, or 0 when the $ is just a dollar sign. An unterminated ${ or $( takes the rest of s.
+// As in POSIX shells, $1 to $9 take a single digit; longer numbers such as $100 are left as
+// they are, and ${10} refers to row 10.
+func referenceLength(s string) int {
+	switch {
+	case strings.HasPrefix(s, "$("):
+		return substitutionLength(s)
+	case strings.HasPrefix(s, "${"):
+		if end := strings.IndexByte(s, '}'); end >= 0 {
+			return end + 1
+		}
+		return len(s)
+	}
+	if len(s) > 1 && '0' <= s[1] && s[1] <= '9' {
+		if len(s) > 2 && '0' <= s[2] && s[2] <= '9' {
+			return 0
+		}
+		return 2
+	}
+	n := 1
+	for n < len(s) && isNameChar(s[n]) {
+		n++
+	}
+	if n == 1 {
+		return 0
+	}
+	return n
+}
+
+// isNameChar reports whether c can be part of a variable name
+func isNameChar(c byte) bool {
+	switch {
+	case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return false
+}
+
+// substitutionLength returns the length of the $(command) s starts with, skipping quoted
+// and nested parentheses
+func substitutionLength(s string) int {
+	depth := 0
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case c == '\\':
+			i++
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+// resolveReference returns the value of a $name, ${name} or $(command) reference. Undefined
+// variables are returned as written.
+func resolveReference(ref string, exp expander) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, "$("):
+		if !strings.HasSuffix(ref, ")") {
+			return "", errors.New("unterminated $(")
+		}
+		return exp.substitute(ref[2 : len(ref)-1])
+	case strings.HasPrefix(ref, "${"):
+		if !strings.HasSuffix(ref, "}") {
+			return "", errors.New("unterminated ${")
+		}
+		if value, ok := exp.variable(ref[2 : len(ref)-1]); ok {
+			return value, nil
+		}
+	default:
+		if value, ok := exp.variable(ref[1:]); ok {
+			return value, nil
+		}
+	}
+	return ref, nil
+}
+
+// splitArgs splits a command line into arguments, see tokenize
+func splitArgs(line string) ([]string, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, len(tokens))
+	for i, t := range tokens {
+		args[i] = t.value
+	}
+	return args, nil
+}
+
+// stageBeforeCursor tokenizes the text before the cursor and returns the tokens of the
+// command being typed, the one after the last |. It also reports whether the cursor starts a
+// new argument: it follows a blank outside quotes, or nothing was typed yet.
+func stageBeforeCursor(d prompt.Document) ([]token, bool) {
+	text := d.TextBeforeCursor()
+	tokens, _ := tokenize(text)
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i].operator && tokens[i].value == "|" {
+			tokens = tokens[i+1:]
+			break
+		}
+	}
+	return tokens, len(tokens) == 0 || tokens[len(tokens)-1].end < len(text)
+}
+
+// fieldsBeforeCursor splits the text before the cursor into arguments, following the same
+// quoting rules as the executor. Only the arguments of the command being typed are returned;
+// the argument being typed is the last one, unless the cursor follows a blank.
+func fieldsBeforeCursor(d prompt.Document) []string {
+	tokens, _ := stageBeforeCursor(d)
+	args := make([]string, len(tokens))
+	for i, t := range tokens {
+		args[i] = t.value
+	}
+	return args
+}
+
+// cursorAfterBlank reports whether the cursor starts a new argument
+func cursorAfterBlank(d prompt.Document) bool {
+	_, afterBlank := stageBeforeCursor(d)
+	return afterBlank
+}
+
+// argsBeforeCursor is fieldsBeforeCursor, always ending with the argument being typed: ""
+// when the cursor starts a new argument
+func argsBeforeCursor(d prompt.Document) []string {
+	args := fieldsBeforeCursor(d)
+	if cursorAfterBlank(d) {
+		args = append(args, "")
+	}
+	return args
+}
+
+// wordBeforeCursor returns the argument being typed, without its quotes and escapes
+func wordBeforeCursor(d prompt.Document) string {
+	args := argsBeforeCursor(d)
+	return args[len(args)-1]
+}
+
+// shellSpecialChars are the characters that have to be quoted to be part of an argument
+const shellSpecialChars = " \t\n\r'\"\\#"
+
+// quoteArg quotes an argument so the lexer reads it back as it is. Arguments without special
+// characters are left alone.
+func quoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, shellSpecialChars) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// quoteSuggestions adapts suggestions to the argument being typed. go-prompt replaces the
+// text after the last blank with the selected suggestion, so suggestions that need quoting
+// are quoted, and inside quotes only the rest of the argument is inserted, keeping the quote
+// the user opened.
+func quoteSuggestions(text string, suggests []prompt.Suggest) []prompt.Suggest {
+	tokens, _ := tokenize(text)
+	raw := ""
+	if n := len(tokens); n > 0 && tokens[n-1].end == len(text) {
+		raw = text[tokens[n-1].start:]
+	}
+	// The part of the argument go-prompt keeps
+	kept := raw[:strings.LastIndex(raw, " ")+1]
+
+	for i, s := range suggests {
+		var quoted string
+		switch {
+		case strings.HasPrefix(raw, `"`):
+			quoted = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(s.Text) + `"`
+		case strings.HasPrefix(raw, "'"):
+			quoted = "'" + strings.ReplaceAll(s.Text, "'", `'\''`) + "'"
+		default:
+			quoted = quoteArg(s.Text)
+		}
+		if quoted != s.Text && strings.HasPrefix(quoted, kept) {
+			suggests[i].Text = quoted[len(kept):]
+		}
+	}
+	return suggests
+}
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: `rename abc "Q3  Report"`, want: []string{"rename", "abc", "Q3  Report"}},
+		{line: "ls\t-l   folder", want: []string{"ls", "-l", "folder"}},
+		{line: `mkdir 'it''s'`, want: []string{"mkdir", "its"}},
+		{line: `mkdir "say \"hi\"" 'a\b'`, want: []string{"mkdir", `say "hi"`, `a\b`}},
+		{line: `mkdir "a\b" Q3\ Report \#1`, want: []string{"mkdir", `a\b`, "Q3 Report", "#1"}},
+		{line: `mkdir a#b # a comment`, want: []string{"mkdir", "a#b"}},
+		{line: `rename abc ""`, want: []string{"rename", "abc", ""}},
+		{line: "# only a comment", want: []string{}},
+		{line: "", want: []string{}},
+	}
+
+	for _, test := range tests {
+		got, err := splitArgs(test.line)
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitArgs(%q) = %q, %v; want %q", test.line, got, err, test.want)
+		}
+	}
+
+	for _, line := range []string{`rename abc "Q3 Report`, `mkdir 'a`, `mkdir a\`} {
+		if _, err := splitArgs(line); err == nil {
+			t.Errorf("Expected an error for %q", line)
+		}
+	}
+}
+
+func TestTokenizeMarksQuotedArgs(t *testing.T) {
+	tokens, err := tokenize(`cd docs "docs" d\ocs`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, quoted := range []bool{false, false, true, true} {
+		if tokens[i].quoted != quoted {
+			t.Errorf("token %d: quoted = %v, want %v", i, tokens[i].quoted, quoted)
+		}
+	}
+}
+
+func TestArgsBeforeCursor(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "", want: []string{""}},
+		{text: "ls", want: []string{"ls"}},
+		{text: "cd ", want: []string{"cd", ""}},
+		{text: `rename abc "Q3  Re`, want: []string{"rename", "abc", "Q3  Re"}},
+		{text: `rename abc "Q3 Report" `, want: []string{"rename", "abc", "Q3 Report", ""}},
+		{text: `cd "`, want: []string{"cd", ""}},
+	}
+
+	for _, test := range tests {
+		d := createTestDocument(test.text)
+		if got := argsBeforeCursor(d); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("argsBeforeCursor(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+
+	if cursorAfterBlank(createTestDocument(`cd "My `)) {
+		t.Error("Expected a blank inside quotes not to start a new argument")
+	}
+}
+
+func TestQuoteSuggestions(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		// go-prompt replaces the text after the last blank with the suggestion
+		{text: "cd My", want: "'My Docs'"},
+		{text: `cd "My `, want: `Docs"`},
+		{text: `cd 'My D`, want: `Docs'`},
+		{text: `cd "`, want: `"My Docs"`},
+	}
+
+	for _, test := range tests {
+		got := quoteSuggestions(test.text, []prompt.Suggest{{Text: "My Docs"}})
+		if got[0].Text != test.want {
+			t.Errorf("quoteSuggestions(%q) = %q, want %q", test.text, got[0].Text, test.want)
+		}
+	}
+
+	if got := quoteSuggestions("cd Do", []prompt.Suggest{{Text: "Docs"}}); got[0].Text != "Docs" {
+		t.Errorf("Expected plain suggestions to be left alone, got %q", got[0].Text)
+	}
+}
+
+// folderRecorder records the titles of the folders created
+type folderRecorder struct {
+	mockClient
+	titles []string
+}
+
+func (c *folderRecorder) CreateFolder(parent, name string) (*antbox.Node, error) {
+	c.titles = append(c.titles, name)
+	return c.mockClient.CreateFolder(parent, name)
+}
+
+func TestExecuteQuotedArgs(t *testing.T) {
+	client := &folderRecorder{}
+	sh := newTestShell(client)
+
+	sh.Execute(`mkdir "Q3  Report" # quarterly`)
+	sh.Execute(`mkdir "unterminated`)
+	sh.Execute("# nothing to run")
+
+	if !reflect.DeepEqual(client.titles, []string{"Q3  Report"}) {
+		t.Errorf("Expected only the quoted title to be created, got %q", client.titles)
+	}
+}
+
+func TestTokenizeOperators(t *testing.T) {
+	tokens, err := tokenize(`ls|head >>out.txt size >= 10 a>b '|'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, t := range tokens {
+		if t.operator {
+			got = append(got, "op:"+t.value)
+		} else {
+			got = append(got, t.value)
+		}
+	}
+	want := []string{"ls", "op:|", "head", "op:>>", "out.txt", "size", ">=", "10", "a>b", "|"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenize = %q, want %q", got, want)
+	}
+
+	d := createTestDocument("ls | where ti")
+	if got := argsBeforeCursor(d); !reflect.DeepEqual(got, []string{"where", "ti"}) {
+		t.Errorf("Expected the arguments of the last command, got %q", got)
+	}
+}
+
+// fakeExpander expands variables from a map and substitutions to the command in brackets
+type fakeExpander map[string]string
+
+func (e fakeExpander) variable(name string) (string, bool) {
+	value, ok := e[name]
+	return value, ok
+}
+
+func (e fakeExpander) substitute(command string) (string, error) {
+	return "[" + command + "]", nil
+}
+
+func TestExpand(t *testing.T) {
+	exp := fakeExpander{"title": "Q3  Report", "ids": " a b ", "_": "last", "1": "row1", "empty": ""}
+
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: `rename $_ "$title"`, want: []string{"rename", "last", "Q3  Report"}},
+		{line: `rename $1 $title`, want: []string{"rename", "row1", "Q3", "Report"}},
+		{line: `run x $ids y`, want: []string{"run", "x", "a", "b", "y"}},
+		{line: `echo pre${title}post`, want: []string{"echo", "preQ3", "Reportpost"}},
+		{line: `echo '$title' \$title "\$title" $ 5$`, want: []string{"echo", "$title", "$title", "$title", "$", "5$"}},
+		{line: `cd $empty`, want: []string{"cd"}},
+		{line: `run a $(find "x y" | where size gt 1)`, want: []string{"run", "a", `[find`, `"x`, `y"`, "|", "where", "size", "gt", "1]"}},
+		{line: `set a="$(ls (x))"`, want: []string{"set", "a=[ls (x)]"}},
+		{line: `answer a "is it under $100?" $1x`, want: []string{"answer", "a", "is it under $100?", "row1x"}},
+		{line: `cd $nope "${nope}" $2`, want: []string{"cd", "$nope", "${nope}", "$2"}},
+	}
+
+	for _, test := range tests {
+		tokens, err := expand(test.line, exp)
+		if err != nil {
+			t.Errorf("expand(%q): %v", test.line, err)
+			continue
+		}
+		got := make([]string, len(tokens))
+		for i, token := range tokens {
+			got[i] = token.value
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("expand(%q) = %q, want %q", test.line, got, test.want)
+		}
+	}
+
+	// Completion keeps references as they are
+	tokens, _ := tokenize(`run a $(find "x y") $_`)
+	if len(tokens) != 4 || tokens[2].value != `$(find "x y")` || tokens[3].value != "$_" {
+		t.Errorf("unexpected tokens: %+v", tokens)
+	}
+}
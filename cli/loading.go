@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sync"
 	"time"
@@ -46,6 +47,8 @@ type LoadingAnimation struct {
 	wg            sync.WaitGroup
 	isRunning     bool
 	mu            sync.Mutex
+	// out receives the animation, which is only drawn on terminals, and the completion message
+	out io.Writer
 }
 
 // NewLoadingAnimation creates a new loading animation with the given message
@@ -59,6 +62,7 @@ func NewLoadingAnimationWithStyle(message string, style AnimationStyle) *Loading
 		message: message,
 		style:   style,
 		stopCh:  make(chan bool),
+		out:     os.Stdout,
 	}
 }
 
@@ -91,11 +95,13 @@ func (l *LoadingAnimation) Stop() {
 	l.isRunning = false
 
 	// Clear the current line
-	fmt.Print("\r\033[K")
+	if isTerminal(l.out) {
+		fmt.Fprint(l.out, "\r\033[K")
+	}
 
 	// Show completion message if set
 	if l.completionMsg != "" {
-		fmt.Printf("%s\n", l.completionMsg)
+		fmt.Fprintf(l.out, "%s\n", l.completionMsg)
 	}
 }
 
@@ -144,11 +150,14 @@ func (l *LoadingAnimation) animate() {
 		case <-l.stopCh:
 			return
 		case <-ticker.C:
+			if !isTerminal(l.out) {
+				continue
+			}
 			// Clear current line and print message with animation
 			if l.style == SpinnerStyle || l.style == BarStyle {
-				fmt.Printf("\r\033[K%s %s", frames[i%len(frames)], l.message)
+				fmt.Fprintf(l.out, "\r\033[K%s %s", frames[i%len(frames)], l.message)
 			} else {
-				fmt.Printf("\r\033[K%s%s", l.message, frames[i%len(frames)])
+				fmt.Fprintf(l.out, "\r\033[K%s%s", l.message, frames[i%len(frames)])
 			}
 			i++
 		}
@@ -169,6 +178,14 @@ func StartLoadingAnimationWithStyle(message string, style AnimationStyle) *Loadi
 	return animation
 }
 
+// startLoadingAnimation starts a loading animation on the shell output
+func (sh *Shell) startLoadingAnimation(message string, style AnimationStyle) *LoadingAnimation {
+	animation := NewLoadingAnimationWithStyle(message, style)
+	animation.out = sh.out
+	animation.Start()
+	return animation
+}
+
 // ShowLoadingWhile shows a loading animation while executing the provided function
 func ShowLoadingWhile(message string, fn func()) {
 	animation := StartLoadingAnimation(message)
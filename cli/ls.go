@@ -2,6 +2,9 @@ package cli
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/c-bata/go-prompt"
 	"github.com/kindalus/antx/antbox"
@@ -17,80 +20,318 @@ func (c *LsCommand) GetDescription() string {
 	return "List content of a folder"
 }
 
-func (c *LsCommand) Execute(args []string) {
+func (c *LsCommand) Execute(sh *Shell, args []string) {
+	opts, args, err := parseNodeListFlags(sh, args)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+	filters, args, err := parseLsFlags(args)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
 	var folder string
 	if len(args) > 0 {
 		folder = args[0]
 	} else {
-		folder = currentNode.UUID
+		folder = sh.getCurrentNode().UUID
 	}
 
 	var nodes []antbox.Node
-	var err error
 
 	if folder != "--root--" {
-		folderNode, err := client.GetNode(folder)
+		folderNode, err := sh.client.GetNode(folder)
 		if err != nil {
-			fmt.Println("Error:", err)
+			sh.eprintln("Error:", err)
 			return
 		}
 
 		if folderNode.Mimetype == "application/vnd.antbox.smartfolder" {
-			nodes, err = client.EvaluateNode(folder)
+			nodes, err = sh.client.EvaluateNode(folder)
 		} else {
-			nodes, err = client.ListNodes(folder)
+			nodes, err = sh.client.ListNodes(folder)
 		}
 	} else {
-		nodes, err = client.ListNodes(folder)
+		nodes, err = sh.client.ListNodes(folder)
 	}
 
 	if err != nil {
-		fmt.Println("Error:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
 
-	currentNodes = nodes
+	sh.setCurrentNodes(nodes)
+	sh.prefetchSubfolders(nodes)
+
+	if filters.long && opts.Format == "table" {
+		opts.Columns = longColumns(opts.Columns)
+	}
+	if err := renderList(sh, opts, filters.apply(nodes), nodeColumns); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
 
-	// Sort nodes: directories first, then files, both alphabetically by title
-	sortedNodes := sortNodesForListing(nodes)
+// lsOptions are the flags of ls choosing and ordering the nodes listed
+type lsOptions struct {
+	long    bool   // -l: show permissions, owner and group
+	sortBy  string // "title", "modified" (-t) or "size" (-S)
+	reverse bool   // -r
+	kind    string // --type folder|file|smart
+	mime    string // --mime: part of the mimetype, e.g. pdf
+}
 
-	// Print header
-	fmt.Printf(" %-12s  %4s  %-12s  %-30s  %s\n", "UUID", "SIZE", "MODIFIED", "MIMETYPE", "TITLE")
-	fmt.Printf(" %-12s  %4s  %-12s  %-30s  %s\n", "----", "----", "--------", "--------", "-----")
+var lsTypes = []string{"folder", "file", "smart"}
 
-	for _, node := range sortedNodes {
-		// Format UUID (first 12 characters)
-		uuid := node.UUID
-		if len(uuid) > 12 {
-			uuid = uuid[:12]
+// parseLsFlags removes the flags of ls from args. Short flags can be combined, as in -ltr.
+func parseLsFlags(args []string) (lsOptions, []string, error) {
+	o := lsOptions{sortBy: "title"}
+
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		flag, value, hasValue := strings.Cut(arg, "=")
+		switch {
+		case flag == "--type" || flag == "--mime":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return lsOptions{}, nil, fmt.Errorf("%s requires a value", flag)
+				}
+				i++
+				value = args[i]
+			}
+			if flag == "--mime" {
+				o.mime = value
+			} else if slices.Contains(lsTypes, value) {
+				o.kind = value
+			} else {
+				return lsOptions{}, nil, fmt.Errorf("unknown type: %s (use %s)", value, strings.Join(lsTypes, ", "))
+			}
+		case arg == "--root--" || !strings.HasPrefix(arg, "-") || arg == "-":
+			rest = append(rest, arg)
+		case strings.HasPrefix(arg, "--"):
+			return lsOptions{}, nil, fmt.Errorf("unknown flag: %s", arg)
+		default:
+			for _, letter := range arg[1:] {
+				switch letter {
+				case 'l':
+					o.long = true
+				case 't':
+					o.sortBy = "modified"
+				case 'S':
+					o.sortBy = "size"
+				case 'r':
+					o.reverse = true
+				default:
+					return lsOptions{}, nil, fmt.Errorf("unknown flag: -%c", letter)
+				}
+			}
 		}
+	}
+	return o, rest, nil
+}
 
-		// Format size with padding
-		size := node.HumanReadableSize()
+// matches reports whether a node passes the --type and --mime filters
+func (o lsOptions) matches(node antbox.Node) bool {
+	switch o.kind {
+	case "folder":
+		if node.Mimetype != "application/vnd.antbox.folder" {
+			return false
+		}
+	case "smart":
+		if node.Mimetype != "application/vnd.antbox.smartfolder" {
+			return false
+		}
+	case "file":
+		if folderFilter(node) {
+			return false
+		}
+	}
+	return o.mime == "" || strings.Contains(strings.ToLower(node.Mimetype), strings.ToLower(o.mime))
+}
+
+// apply filters and sorts the nodes of a listing
+func (o lsOptions) apply(nodes []antbox.Node) []antbox.Node {
+	var listed []antbox.Node
+	for _, node := range nodes {
+		if o.matches(node) {
+			listed = append(listed, node)
+		}
+	}
+	return sortNodes(listed, o.sortBy, o.reverse)
+}
 
-		// Format modified date
-		modifiedAt := formatModifiedDate(node.ModifiedAt)
+// longColumns returns the columns of ls -l: permissions, owner and group before the
+// columns otherwise shown
+func longColumns(columns []string) []string {
+	if len(columns) == 0 {
+		for _, column := range nodeColumns {
+			if !column.Wide {
+				columns = append(columns, column.Name)
+			}
+		}
+	}
 
-		// Format mimetype (max 30 characters with ellipsis)
-		mimetype := node.Mimetype
-		if len(mimetype) > 30 {
-			mimetype = fmt.Sprintf("%s...", mimetype[:27])
+	long := []string{"permissions", "owner", "group"}
+	for _, name := range columns {
+		if !slices.ContainsFunc(long, func(l string) bool { return strings.EqualFold(l, name) }) {
+			long = append(long, name)
 		}
+	}
+	return long
+}
 
-		// Title is free form (no truncation)
-		title := node.Title
-		if node.Mimetype == "application/vnd.antbox.folder" ||
-			node.Mimetype == "application/vnd.antbox.smartfolder" {
-			// Text in cyan
-			title = fmt.Sprintf("\x1b[38;2;62;146;204;1m%s\x1b[0m", title)
+// formatPermissions formats node permissions as ls -l does: read, write and export for the
+// group, authenticated users and anonymous users, e.g. rwxr-----
+func formatPermissions(permissions antbox.Permissions) string {
+	var b strings.Builder
+	for _, granted := range [][]string{permissions.Group, permissions.Authenticated, permissions.Anonymous} {
+		for _, p := range []struct {
+			name   string
+			letter byte
+		}{{"read", 'r'}, {"write", 'w'}, {"export", 'x'}} {
+			if slices.ContainsFunc(granted, func(g string) bool { return strings.EqualFold(g, p.name) }) {
+				b.WriteByte(p.letter)
+			} else {
+				b.WriteByte('-')
+			}
 		}
+	}
+	return b.String()
+}
+
+// parseNodeListFlags parses the output flags of node listings. Tables show the columns of
+// the columns setting when --columns isn't given.
+func parseNodeListFlags(sh *Shell, args []string) (listOptions, []string, error) {
+	opts, args, err := parseListFlags(sh, args)
+	if err != nil {
+		return listOptions{}, nil, err
+	}
+	if len(opts.Columns) == 0 && opts.Format == "table" {
+		opts.Columns = splitColumns(sh.getSetting("columns"))
+	}
+	return opts, args, nil
+}
 
-		fmt.Printf(" %-12s  %4s  %-12s  %-30s  %s\n", uuid, size, modifiedAt, mimetype, title)
+// validateNodeColumns checks the value of the columns setting
+func validateNodeColumns(value string) error {
+	names := splitColumns(value)
+	if len(names) == 0 {
+		return fmt.Errorf("no columns given")
 	}
+	_, err := selectColumns(nodeColumns, names)
+	return err
 }
 
-func (c *LsCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	return []prompt.Suggest{}
+// folderColor is the color of folder titles in listings
+const folderColor = "\x1b[38;2;62;146;204;1m"
+
+// nodeColumns are the columns of node listings
+var nodeColumns = []listColumn[antbox.Node]{
+	{
+		Name: "uuid", Header: "UUID",
+		Value: func(n antbox.Node) string { return n.UUID },
+		Cell: func(n antbox.Node, opts listOptions) string {
+			// UUIDs are shortened unless -o wide or --full-uuid is given
+			if len(n.UUID) > 12 && !opts.wide() && !opts.FullUUID {
+				return n.UUID[:12]
+			}
+			return n.UUID
+		},
+	},
+	{
+		Name: "size", Header: "SIZE",
+		Value: func(n antbox.Node) string { return strconv.Itoa(n.Size) },
+		Cell:  func(n antbox.Node, opts listOptions) string { return n.HumanReadableSize() },
+	},
+	{
+		Name: "modifiedTime", Header: "MODIFIED",
+		Value: func(n antbox.Node) string { return n.ModifiedAt },
+		Cell:  func(n antbox.Node, opts listOptions) string { return formatModifiedDate(n.ModifiedAt) },
+	},
+	{
+		Name: "createdTime", Header: "CREATED", Wide: true,
+		Value: func(n antbox.Node) string { return n.CreatedAt },
+		Cell:  func(n antbox.Node, opts listOptions) string { return formatModifiedDate(n.CreatedAt) },
+	},
+	{
+		Name: "owner", Header: "OWNER", Wide: true,
+		Value: func(n antbox.Node) string { return n.Owner },
+	},
+	{
+		Name: "group", Header: "GROUP", Wide: true,
+		Value: func(n antbox.Node) string { return n.Group },
+	},
+	{
+		Name: "permissions", Header: "PERMISSIONS", Wide: true,
+		Value: func(n antbox.Node) string { return formatPermissions(n.Permissions) },
+	},
+	{
+		Name: "mimetype", Header: "MIMETYPE",
+		Value: func(n antbox.Node) string { return n.Mimetype },
+		Cell: func(n antbox.Node, opts listOptions) string {
+			// Long mimetypes are shortened with an ellipsis, except by -o wide
+			if len(n.Mimetype) > 30 && !opts.wide() {
+				return n.Mimetype[:27] + "..."
+			}
+			return n.Mimetype
+		},
+	},
+	{
+		Name: "title", Header: "TITLE",
+		Value: func(n antbox.Node) string { return n.Title },
+		Cell: func(n antbox.Node, opts listOptions) string {
+			if folderFilter(n) {
+				return colorize(opts.out, n.Title, folderColor)
+			}
+			return n.Title
+		},
+	},
+}
+
+func (c *LsCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	word := wordBeforeCursor(d)
+	args := fieldsBeforeCursor(d)
+
+	previous := ""
+	if cursorAfterBlank(d) && len(args) > 0 {
+		previous = args[len(args)-1]
+	} else if len(args) > 1 {
+		previous = args[len(args)-2]
+	}
+
+	var suggests []prompt.Suggest
+	switch previous {
+	case "--type":
+		for _, kind := range lsTypes {
+			suggests = append(suggests, prompt.Suggest{Text: kind})
+		}
+		return prompt.FilterHasPrefix(suggests, word, true)
+	case "-o", "--output":
+		for _, format := range outputFormats {
+			suggests = append(suggests, prompt.Suggest{Text: format})
+		}
+		return prompt.FilterHasPrefix(suggests, word, true)
+	case "--mime", "--columns", "--template":
+		return []prompt.Suggest{}
+	}
+
+	if strings.HasPrefix(word, "-") {
+		return prompt.FilterHasPrefix([]prompt.Suggest{
+			{Text: "-l", Description: "Show permissions, owner and group"},
+			{Text: "-t", Description: "Sort by modification time, newest first"},
+			{Text: "-S", Description: "Sort by size, largest first"},
+			{Text: "-r", Description: "Reverse the order"},
+			{Text: "--type", Description: "Only list folders, files or smart folders"},
+			{Text: "--mime", Description: "Only list nodes whose mimetype contains a text"},
+			{Text: "--columns", Description: "Columns to show"},
+			{Text: "--template", Description: "Go template applied to each node"},
+			{Text: "--full-uuid", Description: "Don't shorten UUIDs"},
+			{Text: "-o", Description: "Output format"},
+		}, word, false)
+	}
+	return getNodeSuggestions(sh, word, folderFilter)
 }
 
 func init() {
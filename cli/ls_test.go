@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+func TestParseLsFlags(t *testing.T) {
+	o, rest, err := parseLsFlags([]string{"-ltr", "--type", "file", "--mime=pdf", "folder"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !o.long || !o.reverse || o.sortBy != "modified" || o.kind != "file" || o.mime != "pdf" || strings.Join(rest, " ") != "folder" {
+		t.Errorf("got %+v %v", o, rest)
+	}
+
+	o, rest, err = parseLsFlags([]string{"-S", "--root--"})
+	if err != nil || o.sortBy != "size" || strings.Join(rest, " ") != "--root--" {
+		t.Errorf("got %+v %v %v", o, rest, err)
+	}
+
+	for _, args := range [][]string{{"-x"}, {"--type", "link"}, {"--mime"}, {"--recursive"}} {
+		if _, _, err := parseLsFlags(args); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
+func TestLsApply(t *testing.T) {
+	nodes := []antbox.Node{
+		{UUID: "a", Title: "b.pdf", Mimetype: "application/pdf", Size: 10, ModifiedAt: "2024-03-01T10:00:00Z"},
+		{UUID: "b", Title: "Archive", Mimetype: "application/vnd.antbox.folder", ModifiedAt: "2024-01-01T10:00:00Z"},
+		{UUID: "c", Title: "a.txt", Mimetype: "text/plain", Size: 300, ModifiedAt: "2024-02-01T10:00:00Z"},
+		{UUID: "d", Title: "Search", Mimetype: "application/vnd.antbox.smartfolder"},
+	}
+
+	uuids := func(nodes []antbox.Node) string {
+		var ids []string
+		for _, n := range nodes {
+			ids = append(ids, n.UUID)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	tests := []struct {
+		opts lsOptions
+		want string
+	}{
+		{opts: lsOptions{sortBy: "title"}, want: "b,d,c,a"},
+		{opts: lsOptions{sortBy: "title", reverse: true}, want: "d,b,a,c"},
+		{opts: lsOptions{sortBy: "modified"}, want: "a,c,b,d"},
+		{opts: lsOptions{sortBy: "size", reverse: true}, want: "d,b,a,c"},
+		{opts: lsOptions{sortBy: "title", kind: "file"}, want: "c,a"},
+		{opts: lsOptions{sortBy: "title", kind: "smart"}, want: "d"},
+		{opts: lsOptions{sortBy: "title", mime: "PDF"}, want: "a"},
+	}
+
+	for _, tt := range tests {
+		if got := uuids(tt.opts.apply(nodes)); got != tt.want {
+			t.Errorf("%+v: got %s, want %s", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestLongColumns(t *testing.T) {
+	if got := strings.Join(longColumns(nil), ","); got != "permissions,owner,group,uuid,size,modifiedTime,mimetype,title" {
+		t.Errorf("got %s", got)
+	}
+	if got := strings.Join(longColumns([]string{"title", "owner"}), ","); got != "permissions,owner,group,title" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestFormatPermissions(t *testing.T) {
+	permissions := antbox.Permissions{
+		Group:         []string{"Read", "Write", "Export"},
+		Authenticated: []string{"Read"},
+	}
+	if got := formatPermissions(permissions); got != "rwxr-----" {
+		t.Errorf("got %s, want rwxr-----", got)
+	}
+}
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/c-bata/go-prompt"
@@ -17,27 +16,23 @@ func (c *MkdirCommand) GetDescription() string {
 	return "Create a directory"
 }
 
-func (c *MkdirCommand) Execute(args []string) {
+func (c *MkdirCommand) Execute(sh *Shell, args []string) {
 	if len(args) == 0 {
-		fmt.Println("Usage: mkdir <name>")
+		sh.println("Usage: mkdir <name>")
 		return
 	}
 
 	fn := strings.Join(args, " ")
 
-	// Remove initial and final ' or " if present
-	if (strings.HasPrefix(fn, "'") && strings.HasSuffix(fn, "'")) || (strings.HasPrefix(fn, "\"") && strings.HasSuffix(fn, "\"")) {
-		fn = fn[1 : len(fn)-1]
-	}
-
-	_, err := client.CreateFolder(currentNode.UUID, fn)
+	node, err := sh.client.CreateFolder(sh.getCurrentNode().UUID, fn)
 	if err != nil {
-		fmt.Println("Error:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
+	sh.setLastNode(node.UUID)
 }
 
-func (c *MkdirCommand) Suggest(d prompt.Document) []prompt.Suggest {
+func (c *MkdirCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
 	return []prompt.Suggest{}
 }
 
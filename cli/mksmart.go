@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/c-bata/go-prompt"
@@ -18,11 +17,11 @@ func (c *MksmartCommand) GetDescription() string {
 	return "Create a smart folder"
 }
 
-func (c *MksmartCommand) Execute(args []string) {
+func (c *MksmartCommand) Execute(sh *Shell, args []string) {
 	if len(args) < 3 {
-		fmt.Println("Usage: mksmart <name> <field> <operator> [value]")
-		fmt.Println("  Example: mksmart \"My Documents\" title match document")
-		fmt.Println("  Example: mksmart \"Large Files\" size > 1000000")
+		sh.println("Usage: mksmart <name> <field> <operator> [value]")
+		sh.println("  Example: mksmart \"My Documents\" title match document")
+		sh.println("  Example: mksmart \"Large Files\" size '>' 1000000")
 		return
 	}
 
@@ -35,11 +34,6 @@ func (c *MksmartCommand) Execute(args []string) {
 		value = strings.Join(args[3:], " ")
 	}
 
-	// Remove initial and final ' or " if present
-	if (strings.HasPrefix(name, "'") && strings.HasSuffix(name, "'")) || (strings.HasPrefix(name, "\"") && strings.HasSuffix(name, "\"")) {
-		name = name[1 : len(name)-1]
-	}
-
 	// Create the filter for the smart folder
 	var filters antbox.NodeFilters1D
 	if value != "" {
@@ -53,16 +47,17 @@ func (c *MksmartCommand) Execute(args []string) {
 		}
 	}
 
-	_, err := client.CreateSmartFolder(currentNode.UUID, name, filters)
+	node, err := sh.client.CreateSmartFolder(sh.getCurrentNode().UUID, name, filters)
 	if err != nil {
-		fmt.Println("Error:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
+	sh.setLastNode(node.UUID)
 
-	fmt.Printf("Smart folder '%s' created successfully\n", name)
+	sh.printf("Smart folder '%s' created successfully\n", name)
 }
 
-func (c *MksmartCommand) Suggest(d prompt.Document) []prompt.Suggest {
+func (c *MksmartCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
 	return []prompt.Suggest{}
 }
 
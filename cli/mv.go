@@ -1,9 +1,6 @@
 package cli
 
 import (
-	"fmt"
-	"strings"
-
 	"github.com/c-bata/go-prompt"
 )
 
@@ -17,27 +14,27 @@ func (c *MvCommand) GetDescription() string {
 	return "Move a node to another location"
 }
 
-func (c *MvCommand) Execute(args []string) {
+func (c *MvCommand) Execute(sh *Shell, args []string) {
 	if len(args) != 2 {
-		fmt.Println("Usage: mv <uuid> <destination-uuid>")
+		sh.println("Usage: mv <uuid> <destination-uuid>")
 		return
 	}
 
-	err := client.MoveNode(args[0], args[1])
+	err := sh.client.MoveNode(args[0], args[1])
 	if err != nil {
-		fmt.Println("Error:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
 
-	fmt.Printf("Node %s moved to %s successfully\n", args[0], args[1])
+	sh.printf("Node %s moved to %s successfully\n", args[0], args[1])
 }
 
-func (c *MvCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	args := strings.Split(d.TextBeforeCursor(), " ")
+func (c *MvCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := argsBeforeCursor(d)
 	if len(args) == 2 {
-		return getNodeSuggestions(d.GetWordBeforeCursor(), nil)
+		return getNodeSuggestions(sh, wordBeforeCursor(d), nil)
 	} else if len(args) == 3 {
-		return getNodeSuggestions(d.GetWordBeforeCursor(), folderFilter)
+		return getNodeSuggestions(sh, wordBeforeCursor(d), folderFilter)
 	}
 	return []prompt.Suggest{}
 }
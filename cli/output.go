@@ -0,0 +1,416 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// outputFormats are the formats accepted by -o/--output
+var outputFormats = []string{"table", "wide", "json", "yaml", "csv"}
+
+// defaultOutputFormat returns the format used when -o isn't given, set with 'config set output'
+func defaultOutputFormat(sh *Shell) string {
+	if format := sh.getSetting("output"); format != "" {
+		return format
+	}
+	return "table"
+}
+
+// parseOutputFlag removes -o/--output from args and returns the requested output format,
+// or the default one when the flag isn't given
+func parseOutputFlag(sh *Shell, args []string) (string, []string, error) {
+	format := defaultOutputFormat(sh)
+
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-o" || arg == "--output":
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("%s requires a format (%s)", arg, strings.Join(outputFormats, ", "))
+			}
+			format = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--output="):
+			format = strings.TrimPrefix(arg, "--output=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if !slices.Contains(outputFormats, format) {
+		return "", nil, fmt.Errorf("unknown output format: %s (use %s)", format, strings.Join(outputFormats, ", "))
+	}
+	return format, rest, nil
+}
+
+// isTableFormat reports whether a format is meant to be read by people
+func isTableFormat(format string) bool {
+	return format == "table" || format == "wide"
+}
+
+// listOptions are the options of a listing
+type listOptions struct {
+	Format   string
+	Columns  []string // columns shown, in order; all of them when empty
+	Template string   // Go template applied to each item, replacing the format
+	FullUUID bool     // don't shorten UUIDs in tables
+
+	out io.Writer // where the listing is written, set by renderList
+}
+
+// wide reports whether the listing shows the wide columns and values in full
+func (o listOptions) wide() bool {
+	return o.Format == "wide"
+}
+
+// forPeople reports whether the listing is meant to be read by people, so messages such as
+// "nothing found" can be printed along with it
+func (o listOptions) forPeople() bool {
+	return isTableFormat(o.Format) && o.Template == ""
+}
+
+// parseListFlags removes the output flags of listings from args: -o/--output,
+// --columns a,b,c, --template '{{.Field}}' and --full-uuid. UUIDs are also shown in full
+// when the full-uuid setting is true.
+func parseListFlags(sh *Shell, args []string) (listOptions, []string, error) {
+	format, args, err := parseOutputFlag(sh, args)
+	if err != nil {
+		return listOptions{}, nil, err
+	}
+	opts := listOptions{Format: format, FullUUID: sh.getSetting("full-uuid") == "true"}
+
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		flag, inline, hasInline := strings.Cut(args[i], "=")
+		switch {
+		case args[i] == "--full-uuid":
+			opts.FullUUID = true
+		case flag == "--columns" || flag == "--template":
+			var value string
+			switch {
+			case hasInline:
+				value = inline
+			case i+1 < len(args):
+				i++
+				value = args[i]
+			default:
+				return listOptions{}, nil, fmt.Errorf("%s requires a value", flag)
+			}
+			if flag == "--columns" {
+				opts.Columns = splitColumns(value)
+			} else {
+				opts.Template = value
+			}
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	if len(opts.Columns) > 0 && (opts.Format == "json" || opts.Format == "yaml") {
+		return listOptions{}, nil, fmt.Errorf("--columns can't be used with -o %s", opts.Format)
+	}
+	return opts, rest, nil
+}
+
+// splitColumns splits a comma separated list of column names
+func splitColumns(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// selectColumns returns the named columns, in the given order. Names are matched ignoring
+// case; an unknown name is an error listing the available columns.
+func selectColumns[T any](columns []listColumn[T], names []string) ([]listColumn[T], error) {
+	selected := make([]listColumn[T], 0, len(names))
+	for _, name := range names {
+		i := slices.IndexFunc(columns, func(c listColumn[T]) bool { return strings.EqualFold(c.Name, name) })
+		if i < 0 {
+			available := make([]string, len(columns))
+			for j, column := range columns {
+				available[j] = column.Name
+			}
+			return nil, fmt.Errorf("unknown column: %s (use %s)", name, strings.Join(available, ", "))
+		}
+		column := columns[i]
+		// Columns asked for are shown even if they are wide ones
+		column.Wide = false
+		selected = append(selected, column)
+	}
+	return selected, nil
+}
+
+// listTemplateFuncs are the functions available to --template, besides the text/template ones
+var listTemplateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// parseListTemplate parses a --template. \t and \n are read as a tab and a newline, so they
+// can be typed on the command line.
+func parseListTemplate(text string) (*template.Template, error) {
+	text = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(text)
+	tmpl, err := template.New("template").Funcs(listTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// listColumn is a column of a listing. Value is the raw value written to CSV files; Cell, when
+// set, formats the value for tables.
+type listColumn[T any] struct {
+	Name   string
+	Header string
+	Wide   bool
+	Value  func(item T) string
+	Cell   func(item T, opts listOptions) string
+}
+
+// renderList prints a listing. A template is applied to each item; otherwise JSON and YAML
+// print the items themselves, CSV and the tables print the columns. -o table leaves out the
+// wide columns unless --columns asks for them. Inside a pipeline the items are passed to the
+// next command instead.
+func renderList[T any](sh *Shell, opts listOptions, items []T, columns []listColumn[T]) error {
+	if sh.piping {
+		sh.piped = newRecords(opts, items, columns)
+		return nil
+	}
+	sh.rows = listingUUIDs(items, columns)
+
+	opts.out = sh.out
+	if opts.Template != "" {
+		tmpl, err := parseListTemplate(opts.Template)
+		if err != nil {
+			return err
+		}
+		var out strings.Builder
+		for _, item := range items {
+			if err := tmpl.Execute(&out, item); err != nil {
+				return err
+			}
+			out.WriteString("\n")
+		}
+		sh.print(out.String())
+		return nil
+	}
+
+	if len(opts.Columns) > 0 {
+		selected, err := selectColumns(columns, opts.Columns)
+		if err != nil {
+			return err
+		}
+		columns = selected
+	}
+
+	switch opts.Format {
+	case "json", "yaml":
+		if items == nil {
+			items = []T{}
+		}
+		return renderValue(sh, opts.Format, items, nil)
+	case "csv":
+		writer := csv.NewWriter(sh.out)
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = column.Name
+		}
+		writer.Write(header)
+		for _, item := range items {
+			record := make([]string, len(columns))
+			for i, column := range columns {
+				record[i] = column.Value(item)
+			}
+			writer.Write(record)
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	wide := opts.wide()
+
+	var header []string
+	for _, column := range columns {
+		if !column.Wide || wide {
+			header = append(header, column.Header)
+		}
+	}
+	rows := [][]string{header}
+
+	for _, item := range items {
+		var cells []string
+		for _, column := range columns {
+			if column.Wide && !wide {
+				continue
+			}
+			cell := column.Value(item)
+			if column.Cell != nil {
+				cell = column.Cell(item, opts)
+			}
+			cells = append(cells, strings.ReplaceAll(cell, "\n", " "))
+		}
+		rows = append(rows, cells)
+	}
+	return writeTable(sh.out, rows)
+}
+
+// writeTable prints rows of cells in columns two spaces apart, as tabwriter does, except that
+// ANSI color sequences take no room. tabwriter counts them, misaligning colored cells.
+func writeTable(w io.Writer, rows [][]string) error {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], visibleWidth(cell))
+		}
+	}
+
+	var table strings.Builder
+	for _, row := range rows {
+		for i, cell := range row {
+			table.WriteString(cell)
+			if i < len(row)-1 {
+				table.WriteString(strings.Repeat(" ", widths[i]-visibleWidth(cell)+2))
+			}
+		}
+		table.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, table.String())
+	return err
+}
+
+// visibleWidth returns the number of characters text shows, leaving out ANSI sequences
+func visibleWidth(text string) int {
+	return utf8.RuneCountInString(ansiEscape.ReplaceAllString(text, ""))
+}
+
+// listingUUIDs returns the UUIDs of the items of a listing, in order, or nil when the listing
+// has no UUID column
+func listingUUIDs[T any](items []T, columns []listColumn[T]) []string {
+	i := slices.IndexFunc(columns, func(c listColumn[T]) bool { return c.Name == "uuid" })
+	if i < 0 {
+		return nil
+	}
+	uuids := make([]string, len(items))
+	for j, item := range items {
+		uuids[j] = columns[i].Value(item)
+	}
+	return uuids
+}
+
+// renderValue prints a single value. JSON and YAML use the JSON field names, CSV prints a
+// header with the top level fields and a row with their values, and the table formats
+// call text.
+func renderValue(sh *Shell, format string, value any, text func(wide bool)) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		sh.println(string(data))
+	case "yaml":
+		data, err := toYAML(value)
+		if err != nil {
+			return err
+		}
+		sh.print(string(data))
+	case "csv":
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		var decoded any
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return err
+		}
+		rows, ok := decoded.([]any)
+		if !ok {
+			rows = []any{decoded}
+		}
+
+		columns := structuredColumns(rows)
+		writer := csv.NewWriter(sh.out)
+		writer.Write(columns)
+		for _, row := range rows {
+			object, _ := row.(map[string]any)
+			record := make([]string, len(columns))
+			for i, column := range columns {
+				record[i] = tableCell(object[column])
+			}
+			writer.Write(record)
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		if text != nil {
+			text(format == "wide")
+		}
+	}
+	return nil
+}
+
+// toYAML encodes a value as YAML with the field names and order of its JSON encoding.
+// YAML is a superset of JSON, so the JSON is decoded as a YAML document and re-encoded
+// in block style.
+func toYAML(value any) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	resetYAMLStyle(&doc)
+	return yaml.Marshal(&doc)
+}
+
+// resetYAMLStyle drops the JSON flow style and quotes. Strings that YAML 1.1 reads as
+// booleans stay quoted, as yaml.v3 does when encoding Go strings.
+func resetYAMLStyle(node *yaml.Node) {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!str" || !slices.Contains(yamlOldBools, node.Value) {
+		node.Style = 0
+	}
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
+
+var yamlOldBools = []string{"y", "Y", "yes", "Yes", "YES", "n", "N", "no", "No", "NO", "on", "On", "ON", "off", "Off", "OFF"}
+
+// colorsEnabled reports whether output written to w may use ANSI colors: only on a terminal,
+// and not when NO_COLOR is set
+func colorsEnabled(w io.Writer) bool {
+	return os.Getenv("NO_COLOR") == "" && isTerminal(w)
+}
+
+// colorize wraps text in an ANSI color sequence when colors are enabled for w
+func colorize(w io.Writer, text, sequence string) string {
+	if !colorsEnabled(w) {
+		return text
+	}
+	return sequence + text + "\x1b[0m"
+}
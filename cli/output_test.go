@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+// captureOutput returns what fn prints to the shell it's given
+func captureOutput(fn func(sh *Shell)) string {
+	var out strings.Builder
+	fn(NewShell(&mockClient{}, strings.NewReader(""), &out, &out))
+	return out.String()
+}
+
+func TestParseOutputFlag(t *testing.T) {
+	sh := newTestShell(&mockClient{})
+
+	tests := []struct {
+		args     []string
+		setting  string
+		format   string
+		rest     []string
+		hasError bool
+	}{
+		{args: []string{"folder"}, format: "table", rest: []string{"folder"}},
+		{args: []string{"-o", "json", "folder"}, format: "json", rest: []string{"folder"}},
+		{args: []string{"folder", "--output=csv"}, format: "csv", rest: []string{"folder"}},
+		{args: []string{"folder"}, setting: "yaml", format: "yaml", rest: []string{"folder"}},
+		{args: []string{"-o", "wide"}, setting: "yaml", format: "wide"},
+		{args: []string{"-o", "xml"}, hasError: true},
+		{args: []string{"-o"}, hasError: true},
+	}
+
+	for _, tt := range tests {
+		settings := map[string]string{}
+		if tt.setting != "" {
+			settings["output"] = tt.setting
+		}
+		sh.restoreSettings(settings)
+
+		format, rest, err := parseOutputFlag(sh, tt.args)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("%v: expected an error", tt.args)
+			}
+			continue
+		}
+		if err != nil || format != tt.format || strings.Join(rest, " ") != strings.Join(tt.rest, " ") {
+			t.Errorf("%v: got %q %v %v, want %q %v", tt.args, format, rest, err, tt.format, tt.rest)
+		}
+	}
+}
+
+func TestRenderList(t *testing.T) {
+	nodes := []antbox.Node{
+		{UUID: "0123456789abcdef", Title: "Report, final", Mimetype: "application/pdf", Size: 2048, Owner: "ana@example.com"},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "table", want: "UUID          SIZE  MODIFIED  MIMETYPE         TITLE\n0123456789ab  2.0K  N/A       application/pdf  Report, final\n"},
+		{format: "csv", want: "uuid,size,modifiedTime,createdTime,owner,group,permissions,mimetype,title\n0123456789abcdef,2048,,,ana@example.com,,---------,application/pdf,\"Report, final\"\n"},
+		{format: "json", want: "[\n  {\n    \"uuid\": \"0123456789abcdef\",\n    \"title\": \"Report, final\",\n    \"mimetype\": \"application/pdf\",\n    \"owner\": \"ana@example.com\",\n    \"permissions\": {},\n    \"size\": 2048\n  }\n]\n"},
+	}
+
+	for _, tt := range tests {
+		got := captureOutput(func(sh *Shell) {
+			if err := renderList(sh, listOptions{Format: tt.format}, nodes, nodeColumns); err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.format, err)
+			}
+		})
+		if got != tt.want {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.format, got, tt.want)
+		}
+	}
+
+	wide := captureOutput(func(sh *Shell) { renderList(sh, listOptions{Format: "wide"}, nodes, nodeColumns) })
+	if !strings.Contains(wide, "0123456789abcdef") || !strings.Contains(wide, "OWNER") {
+		t.Errorf("Expected full UUIDs and wide columns with -o wide, got:\n%s", wide)
+	}
+
+	empty := captureOutput(func(sh *Shell) { renderList(sh, listOptions{Format: "json"}, []antbox.Node(nil), nodeColumns) })
+	if strings.TrimSpace(empty) != "[]" {
+		t.Errorf("Expected an empty JSON list, got %q", empty)
+	}
+}
+
+func TestParseListFlags(t *testing.T) {
+	sh := newTestShell(&mockClient{})
+
+	opts, rest, err := parseListFlags(sh, []string{"--columns", "uuid,title", "folder", "--full-uuid"})
+	if err != nil || strings.Join(opts.Columns, ",") != "uuid,title" || !opts.FullUUID || strings.Join(rest, " ") != "folder" {
+		t.Errorf("got %+v %v %v", opts, rest, err)
+	}
+
+	opts, rest, err = parseListFlags(sh, []string{"--template", "{{.UUID}} - {{.Title}}", "folder"})
+	if err != nil || opts.Template != "{{.UUID}} - {{.Title}}" || strings.Join(rest, " ") != "folder" {
+		t.Errorf("got %+v %v %v", opts, rest, err)
+	}
+
+	opts, _, err = parseListFlags(sh, []string{`--template={{.UUID}}\t{{.Title}}`})
+	if err != nil || opts.Template != `{{.UUID}}\t{{.Title}}` {
+		t.Errorf("got %+v %v", opts, err)
+	}
+
+	if _, _, err := parseListFlags(sh, []string{"-o", "json", "--columns", "uuid"}); err == nil {
+		t.Error("Expected an error for --columns with -o json")
+	}
+	if _, _, err := parseListFlags(sh, []string{"--template"}); err == nil {
+		t.Error("Expected an error for --template without a value")
+	}
+
+	sh.restoreSettings(map[string]string{"full-uuid": "true", "columns": "title,uuid"})
+	opts, _, _ = parseNodeListFlags(sh, nil)
+	if !opts.FullUUID || strings.Join(opts.Columns, ",") != "title,uuid" {
+		t.Errorf("Expected the settings to be the defaults, got %+v", opts)
+	}
+	opts, _, _ = parseNodeListFlags(sh, []string{"-o", "wide"})
+	if len(opts.Columns) != 0 {
+		t.Errorf("Expected -o wide to show every column, got %v", opts.Columns)
+	}
+}
+
+func TestRenderListColumnsAndTemplates(t *testing.T) {
+	nodes := []antbox.Node{
+		{UUID: "0123456789abcdef", Title: "Report", Owner: "ana@example.com"},
+	}
+
+	tests := []struct {
+		opts listOptions
+		want string
+	}{
+		{opts: listOptions{Format: "table", Columns: []string{"title", "OWNER"}}, want: "TITLE   OWNER\nReport  ana@example.com\n"},
+		{opts: listOptions{Format: "table", Columns: []string{"uuid"}, FullUUID: true}, want: "UUID\n0123456789abcdef\n"},
+		{opts: listOptions{Format: "csv", Columns: []string{"uuid", "title"}}, want: "uuid,title\n0123456789abcdef,Report\n"},
+		{opts: listOptions{Format: "table", Template: `{{.UUID}}\t{{.Title | upper}}`}, want: "0123456789abcdef\tREPORT\n"},
+		{opts: listOptions{Format: "json", Template: "{{json .Title}}"}, want: "\"Report\"\n"},
+	}
+
+	for _, tt := range tests {
+		got := captureOutput(func(sh *Shell) {
+			if err := renderList(sh, tt.opts, nodes, nodeColumns); err != nil {
+				t.Errorf("%+v: unexpected error: %v", tt.opts, err)
+			}
+		})
+		if got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+
+	sh := newTestShell(&mockClient{})
+	if err := renderList(sh, listOptions{Format: "table", Columns: []string{"name"}}, nodes, nodeColumns); err == nil || !strings.Contains(err.Error(), "modifiedTime") {
+		t.Errorf("Expected an error listing the columns, got %v", err)
+	}
+	if err := renderList(sh, listOptions{Format: "table", Template: "{{.Name}}"}, nodes, nodeColumns); err == nil {
+		t.Error("Expected an error for an unknown template field")
+	}
+	if err := validateSetting("columns", "uuid,size"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateSetting("columns", "uuid,colour"); err == nil {
+		t.Error("Expected an error for an unknown column in the columns setting")
+	}
+}
+
+func TestRenderValueYAML(t *testing.T) {
+	value := struct {
+		Name    string   `json:"name"`
+		Version string   `json:"version"`
+		Tags    []string `json:"tags"`
+	}{Name: "antx", Version: "1.10", Tags: []string{"yes", "cli"}}
+
+	got := captureOutput(func(sh *Shell) { renderValue(sh, "yaml", value, nil) })
+	want := "name: antx\nversion: \"1.10\"\ntags:\n    - \"yes\"\n    - cli\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestConfigSettingsRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	config := &CLIConfig{
+		CurrentNodeUUID: "folder-uuid",
+		Settings:        map[string]string{"output": "json"},
+		History:         []string{"ls", "cd docs"},
+	}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err := loadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.CurrentNodeUUID != "folder-uuid" || loaded.Settings["output"] != "json" || strings.Join(loaded.History, "|") != "ls|cd docs" {
+		t.Errorf("Config changed in a round trip: %+v", loaded)
+	}
+}
+
+func TestWriteTableIgnoresColors(t *testing.T) {
+	var out strings.Builder
+	rows := [][]string{
+		{"TITLE", "SIZE"},
+		{folderColor + "Docs" + "\x1b[0m", "-"},
+		{"report.pdf", "2 KB"},
+	}
+	if err := writeTable(&out, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(ansiEscape.ReplaceAllString(out.String(), ""), "\n")
+	expected := []string{"TITLE       SIZE", "Docs        -", "report.pdf  2 KB", ""}
+	if !slices.Equal(lines, expected) {
+		t.Errorf("expected aligned columns %q, got %q", expected, lines)
+	}
+}
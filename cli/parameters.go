@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
+)
+
+// parseParameterArgs splits key=value arguments into raw values. Tokens without '=' are
+// appended to the previous value when it is an unfinished JSON array or object.
+func parseParameterArgs(sh *Shell, args []string) map[string]string {
+	raw := make(map[string]string)
+	lastKey := ""
+
+	for _, arg := range args {
+		if key, value, ok := strings.Cut(arg, "="); ok && !isOpenJSONValue(raw[lastKey]) {
+			lastKey = strings.TrimSpace(key)
+			raw[lastKey] = strings.TrimSpace(value)
+			continue
+		}
+
+		if lastKey != "" && isOpenJSONValue(raw[lastKey]) {
+			raw[lastKey] += " " + arg
+			continue
+		}
+
+		sh.eprintf("Warning: Ignoring invalid parameter format: %s (expected key=value)\n", arg)
+	}
+
+	return raw
+}
+
+// isOpenJSONValue reports whether value starts a JSON array or object that is not yet valid
+func isOpenJSONValue(value string) bool {
+	if !strings.HasPrefix(value, "[") && !strings.HasPrefix(value, "{") {
+		return false
+	}
+	return !json.Valid([]byte(value))
+}
+
+// findCachedFeature returns the feature with the given UUID from the cache, or nil
+func findCachedFeature(uuid string, cached []antbox.Feature) *antbox.Feature {
+	for i := range cached {
+		if cached[i].UUID == uuid {
+			return &cached[i]
+		}
+	}
+	return nil
+}
+
+// findFeatureParameters returns the declared parameters of a feature, looking in the
+// given cache first and falling back to the server. It returns nil if the feature is unknown.
+func findFeatureParameters(sh *Shell, uuid string, cached []antbox.Feature) []antbox.Parameter {
+	if feature := findCachedFeature(uuid, cached); feature != nil {
+		return feature.Parameters
+	}
+
+	feature, err := sh.client.GetFeature(uuid)
+	if err != nil {
+		return nil
+	}
+	return feature.Parameters
+}
+
+// resolveParameters coerces the raw values to the declared parameter types and asks
+// for any required parameter that was not given and has no default value. Local files
+// given to file parameters are uploaded once every parameter is valid.
+func resolveParameters(sh *Shell, declared []antbox.Parameter, raw map[string]string) (map[string]any, error) {
+	values, missing, err := coerceParameters(sh, declared, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(missing) > 0 {
+		sh.println("Missing required parameters:")
+		for _, param := range missing {
+			value, err := readParameter(sh, param)
+			if err != nil {
+				return nil, err
+			}
+			values[param.Name] = value
+		}
+		sh.println()
+	}
+
+	if err := uploadParameterFiles(sh, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// coerceParameters converts the raw values using the declared parameter types. It returns
+// the converted values and the required parameters that are missing and have no default.
+func coerceParameters(sh *Shell, declared []antbox.Parameter, raw map[string]string) (map[string]any, []antbox.Parameter, error) {
+	values := make(map[string]any)
+	known := make(map[string]bool)
+
+	for _, param := range declared {
+		known[param.Name] = true
+
+		rawValue, ok := raw[param.Name]
+		if !ok {
+			continue
+		}
+
+		value, err := coerceParameterValue(param, rawValue)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parameter '%s': %w", param.Name, err)
+		}
+		values[param.Name] = value
+	}
+
+	var missing []antbox.Parameter
+	for _, param := range declared {
+		if _, ok := values[param.Name]; !ok && param.Required && param.DefaultValue == nil {
+			missing = append(missing, param)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(raw)) {
+		if known[key] {
+			continue
+		}
+		if len(declared) > 0 {
+			sh.eprintf("Warning: '%s' is not a declared parameter\n", key)
+		}
+		// Without a declaration the best we can do is guess the type
+		values[key] = convertValue(raw[key])
+	}
+
+	return values, missing, nil
+}
+
+// coerceParameterValue converts a raw string to the type declared by the parameter
+func coerceParameterValue(param antbox.Parameter, raw string) (any, error) {
+	switch param.Type {
+	case "string":
+		return raw, nil
+
+	case "number":
+		if intVal, err := strconv.Atoi(raw); err == nil {
+			return intVal, nil
+		}
+		floatVal, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a number", raw)
+		}
+		return floatVal, nil
+
+	case "boolean":
+		boolVal, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a boolean (use true or false)", raw)
+		}
+		return boolVal, nil
+
+	case "array":
+		if strings.HasPrefix(raw, "[") {
+			var array []any
+			if err := json.Unmarshal([]byte(raw), &array); err != nil {
+				return nil, fmt.Errorf("invalid JSON array: %w", err)
+			}
+			return array, nil
+		}
+
+		// Accept a plain comma separated list as a shorthand
+		array := []any{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				array = append(array, convertValue(item))
+			}
+		}
+		return array, nil
+
+	case "object":
+		var object map[string]any
+		if err := json.Unmarshal([]byte(raw), &object); err != nil {
+			return nil, fmt.Errorf("invalid JSON object: %w", err)
+		}
+		return object, nil
+
+	case "file":
+		return parseParameterFile(raw)
+
+	default:
+		return convertValue(raw), nil
+	}
+}
+
+// parameterFile is a local file given to a file parameter, uploaded when the parameters
+// are resolved
+type parameterFile string
+
+// nodeUUIDPattern matches node UUIDs, such as "--root--" or generated ones
+var nodeUUIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// parseParameterFile returns the local file to upload for a file parameter, or the node UUID
+// when the value is not a local file
+func parseParameterFile(raw string) (any, error) {
+	if info, err := os.Stat(raw); err == nil && !info.IsDir() {
+		return parameterFile(raw), nil
+	}
+	if !nodeUUIDPattern.MatchString(raw) {
+		return nil, fmt.Errorf("'%s' is neither a local file nor a node UUID", raw)
+	}
+	return raw, nil
+}
+
+// uploadParameterFiles uploads the local files of the parameter values to the current folder,
+// replacing them with the new node UUIDs
+func uploadParameterFiles(sh *Shell, values map[string]any) error {
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		path, ok := values[name].(parameterFile)
+		if !ok {
+			continue
+		}
+
+		metadata := antbox.NodeCreate{
+			Title:    filepath.Base(string(path)),
+			Mimetype: "application/octet-stream",
+			Parent:   sh.getCurrentNode().UUID,
+		}
+		node, err := sh.client.CreateFile(string(path), metadata)
+		if err != nil {
+			return fmt.Errorf("parameter '%s': uploading %s: %w", name, path, err)
+		}
+
+		sh.printf("Uploaded %s as node %s\n", path, node.UUID)
+		values[name] = node.UUID
+	}
+	return nil
+}
+
+// readParameter asks for a parameter value on stdin until it is valid
+func readParameter(sh *Shell, param antbox.Parameter) (any, error) {
+	label := fmt.Sprintf("  %s (%s)", param.Name, param.Type)
+	if param.Description != "" {
+		label += " - " + param.Description
+	}
+
+	for {
+		raw := sh.readInput(label, "")
+		if raw == "" {
+			return nil, fmt.Errorf("parameter '%s' is required", param.Name)
+		}
+
+		value, err := coerceParameterValue(param, raw)
+		if err != nil {
+			sh.eprintln("  Error:", err)
+			continue
+		}
+		return value, nil
+	}
+}
+
+// getParameterSuggestions suggests parameter names, or values for boolean parameters
+func getParameterSuggestions(word string, declared []antbox.Parameter) []prompt.Suggest {
+	currentWord := strings.TrimSpace(word)
+
+	if name, value, ok := strings.Cut(currentWord, "="); ok {
+		for _, param := range declared {
+			if param.Name != name || param.Type != "boolean" {
+				continue
+			}
+			var suggests []prompt.Suggest
+			for _, option := range []string{"true", "false"} {
+				if strings.HasPrefix(option, value) {
+					suggests = append(suggests, prompt.Suggest{Text: name + "=" + option})
+				}
+			}
+			return suggests
+		}
+		return []prompt.Suggest{}
+	}
+
+	var suggests []prompt.Suggest
+	for _, param := range declared {
+		if !strings.HasPrefix(strings.ToLower(param.Name), strings.ToLower(currentWord)) {
+			continue
+		}
+		description := fmt.Sprintf("%s (%s)", param.Description, param.Type)
+		if param.Required {
+			description += " - Required"
+		}
+		if param.DefaultValue != nil {
+			description += fmt.Sprintf(" - Default: %v", param.DefaultValue)
+		}
+		suggests = append(suggests, prompt.Suggest{
+			Text:        param.Name + "=",
+			Description: description,
+		})
+	}
+	return suggests
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+func TestCoerceParameterValue(t *testing.T) {
+	testCases := []struct {
+		paramType string
+		raw       string
+		expected  any
+		wantErr   bool
+	}{
+		{"string", "123", "123", false},
+		{"number", "42", 42, false},
+		{"number", "3.5", 3.5, false},
+		{"number", "abc", nil, true},
+		{"boolean", "true", true, false},
+		{"boolean", "yes", nil, true},
+		{"array", `["a", 1]`, []any{"a", float64(1)}, false},
+		{"array", "a, b,3", []any{"a", "b", 3}, false},
+		{"array", `[1,`, nil, true},
+		{"object", `{"dpi": 300}`, map[string]any{"dpi": float64(300)}, false},
+		{"object", `[1]`, nil, true},
+		{"", "true", true, false},
+	}
+
+	for _, tc := range testCases {
+		value, err := coerceParameterValue(antbox.Parameter{Name: "p", Type: tc.paramType}, tc.raw)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("Type %q with %q: expected error, got %v", tc.paramType, tc.raw, value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Type %q with %q: unexpected error: %v", tc.paramType, tc.raw, err)
+			continue
+		}
+		if !reflect.DeepEqual(value, tc.expected) {
+			t.Errorf("Type %q with %q: expected %#v, got %#v", tc.paramType, tc.raw, tc.expected, value)
+		}
+	}
+}
+
+func TestCoerceParametersReportsMissingRequired(t *testing.T) {
+	sh := newTestShell(&mockClient{})
+
+	declared := []antbox.Parameter{
+		{Name: "format", Type: "string", Required: true},
+		{Name: "quality", Type: "number", Required: true, DefaultValue: 80},
+		{Name: "overwrite", Type: "boolean"},
+	}
+
+	values, missing, err := coerceParameters(sh, declared, map[string]string{"overwrite": "false"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["overwrite"] != false {
+		t.Errorf("Expected overwrite=false, got %v", values["overwrite"])
+	}
+	if len(missing) != 1 || missing[0].Name != "format" {
+		t.Errorf("Expected only 'format' to be missing, got %v", missing)
+	}
+
+	if _, _, err := coerceParameters(sh, declared, map[string]string{"quality": "high"}); err == nil {
+		t.Error("Expected error for invalid number")
+	}
+}
+
+func TestParseParameterArgsJoinsJSONValues(t *testing.T) {
+	sh := newTestShell(&mockClient{})
+
+	raw := parseParameterArgs(sh, []string{"options={\"a\":", "1,", "\"b\":", "2}", "mode=fast"})
+
+	if raw["options"] != `{"a": 1, "b": 2}` {
+		t.Errorf("Expected JSON value to be joined, got %q", raw["options"])
+	}
+	if raw["mode"] != "fast" {
+		t.Errorf("Expected mode=fast, got %q", raw["mode"])
+	}
+}
+
+// uploadCountingClient counts the files uploaded
+type uploadCountingClient struct {
+	mockClient
+	uploads int
+}
+
+func (c *uploadCountingClient) CreateFile(filePath string, metadata antbox.NodeCreate) (*antbox.Node, error) {
+	c.uploads++
+	return c.mockClient.CreateFile(filePath, metadata)
+}
+
+func TestResolveParametersUploadsFilesLast(t *testing.T) {
+	client := &uploadCountingClient{}
+	sh := newTestShell(client)
+
+	path := filepath.Join(t.TempDir(), "input.pdf")
+	if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	declared := []antbox.Parameter{
+		{Name: "input", Type: "file"},
+		{Name: "quality", Type: "number"},
+	}
+
+	if _, err := resolveParameters(sh, declared, map[string]string{"input": path, "quality": "high"}); err == nil {
+		t.Error("Expected error for invalid number")
+	}
+	if client.uploads != 0 {
+		t.Errorf("Expected no upload when a parameter is invalid, got %d", client.uploads)
+	}
+
+	values, err := resolveParameters(sh, declared, map[string]string{"input": path, "quality": "80"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["input"] != "uploaded-uuid" || client.uploads != 1 {
+		t.Errorf("Expected the file to be uploaded once, got %v after %d uploads", values["input"], client.uploads)
+	}
+
+	if _, err := resolveParameters(sh, declared, map[string]string{"input": "missing/report.pdf"}); err == nil {
+		t.Error("Expected error for a value that is neither a file nor a UUID")
+	}
+	values, err = resolveParameters(sh, declared, map[string]string{"input": "--root--"})
+	if err != nil || values["input"] != "--root--" {
+		t.Errorf("Expected node UUID to be kept, got %v, %v", values["input"], err)
+	}
+}
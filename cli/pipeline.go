@@ -0,0 +1,328 @@
+package cli
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
+)
+
+// records are the items a command passes to the next one in a pipeline, such as the nodes
+// of ls or the agents of agents, with the options and columns of the listing they come from.
+// The last command of the pipeline prints them as that listing would.
+type records struct {
+	opts    listOptions
+	items   []any
+	columns []listColumn[any]
+}
+
+// newRecords returns the items of a listing as records
+func newRecords[T any](opts listOptions, items []T, columns []listColumn[T]) *records {
+	r := &records{opts: opts, items: make([]any, len(items))}
+	for i, item := range items {
+		r.items[i] = item
+	}
+
+	// Records piped through filters already have untyped columns
+	if untyped, ok := any(columns).([]listColumn[any]); ok {
+		r.columns = untyped
+		return r
+	}
+	r.columns = make([]listColumn[any], len(columns))
+	for i, column := range columns {
+		value, cell := column.Value, column.Cell
+		r.columns[i] = listColumn[any]{
+			Name:   column.Name,
+			Header: column.Header,
+			Wide:   column.Wide,
+			Value:  func(item any) string { return value(item.(T)) },
+		}
+		if cell != nil {
+			r.columns[i].Cell = func(item any, opts listOptions) string { return cell(item.(T), opts) }
+		}
+	}
+	return r
+}
+
+// kind returns what the records are, e.g. "nodes", for messages
+func (r *records) kind() string {
+	if len(r.items) == 0 {
+		return "records"
+	}
+	switch r.items[0].(type) {
+	case antbox.Node:
+		return "nodes"
+	case antbox.Feature:
+		return "features"
+	case antbox.Agent:
+		return "agents"
+	case antbox.Template:
+		return "templates"
+	default:
+		return "records"
+	}
+}
+
+// column returns the column with the given name, matched ignoring case
+func (r *records) column(name string) (listColumn[any], error) {
+	selected, err := selectColumns(r.columns, []string{name})
+	if err != nil {
+		return listColumn[any]{}, err
+	}
+	return selected[0], nil
+}
+
+// with returns records with the same listing and other items
+func (r *records) with(items []any) *records {
+	return &records{opts: r.opts, items: items, columns: r.columns}
+}
+
+// uuids returns the UUIDs of the records
+func (r *records) uuids() ([]string, error) {
+	if len(r.items) == 0 {
+		return nil, nil
+	}
+	column, err := r.column("uuid")
+	if err != nil {
+		return nil, fmt.Errorf("%s have no UUID", r.kind())
+	}
+	uuids := make([]string, len(r.items))
+	for i, item := range r.items {
+		uuids[i] = column.Value(item)
+	}
+	return uuids, nil
+}
+
+// nodes returns the records as nodes, for commands working on nodes
+func (r *records) nodes() ([]antbox.Node, error) {
+	nodes := make([]antbox.Node, 0, len(r.items))
+	for _, item := range r.items {
+		node, ok := item.(antbox.Node)
+		if !ok {
+			return nil, fmt.Errorf("expected nodes, got %s", r.kind())
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// pipeline is a parsed command line: commands connected with |, the output of the last
+// one optionally redirected to a local file with > or >>
+type pipeline struct {
+	stages     [][]token
+	redirect   string
+	appendMode bool
+}
+
+// parsePipeline splits the tokens of a command line at the | and > operators
+func parsePipeline(tokens []token) (pipeline, error) {
+	var p pipeline
+	var stage []token
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case !t.operator:
+			stage = append(stage, t)
+		case t.value == "|":
+			if len(stage) == 0 {
+				return pipeline{}, errors.New("missing command before |")
+			}
+			p.stages = append(p.stages, stage)
+			stage = nil
+		default:
+			if i+1 >= len(tokens) || tokens[i+1].operator {
+				return pipeline{}, fmt.Errorf("missing file name after %s", t.value)
+			}
+			if i+2 < len(tokens) {
+				return pipeline{}, fmt.Errorf("%s must come at the end of the line", t.value)
+			}
+			p.redirect, p.appendMode = tokens[i+1].value, t.value == ">>"
+			i++
+		}
+	}
+	if len(stage) == 0 {
+		return pipeline{}, errors.New("missing command after |")
+	}
+	p.stages = append(p.stages, stage)
+	return p, nil
+}
+
+// runPipeline runs the commands of a pipeline, the last one printing to the shell output or to
+// the redirection file
+func (sh *Shell) runPipeline(p pipeline) {
+	if p.redirect == "" {
+		sh.runStages(p.stages, sh.out, false)
+		return
+	}
+	if err := sh.checkRedirectedStages(p.stages); err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if p.appendMode {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	file := &redirectFile{path: expandHome(p.redirect), flags: flags}
+	sh.runStages(p.stages, file, false)
+	if err := file.Close(); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
+
+// checkRedirectedStages catches the conditions an unquoted > was taken from, as in
+// find size > 1000 or where size > 10, before the file they would redirect to is touched
+func (sh *Shell) checkRedirectedStages(stages [][]token) error {
+	for _, stage := range stages {
+		args := make([]string, len(stage)-1)
+		for i, t := range stage[1:] {
+			args[i] = t.value
+		}
+		switch stage[0].value {
+		case "find":
+			if _, args, err := parseNodeListFlags(sh, args); err == nil {
+				if condition, ok := incompleteCondition(strings.Join(args, " ")); ok {
+					return fmt.Errorf("condition '%s' has no operator. Quote > so it isn't read as a redirection: %s '>' 1000", condition, condition)
+				}
+			}
+		case "where":
+			if len(args) == 1 {
+				return fmt.Errorf("where %s has no operator. Quote > so it isn't read as a redirection, or use gt", args[0])
+			}
+		}
+	}
+	return nil
+}
+
+// redirectFile is the file of a redirection. It is opened on the first write, so a command
+// that fails without printing anything leaves the file as it was.
+type redirectFile struct {
+	path  string
+	flags int
+	file  *os.File
+	err   error // the first error opening or writing the file
+}
+
+func (f *redirectFile) Write(p []byte) (int, error) {
+	if f.file == nil && f.err == nil {
+		f.file, f.err = os.OpenFile(f.path, f.flags, 0644)
+	}
+	if f.err != nil {
+		return 0, f.err
+	}
+	n, err := f.file.Write(p)
+	if err != nil {
+		f.err = err
+	}
+	return n, err
+}
+
+// Close closes the file and returns the first error opening, writing or closing it
+func (f *redirectFile) Close() error {
+	if f.file == nil {
+		return f.err
+	}
+	if err := f.file.Close(); f.err == nil {
+		f.err = err
+	}
+	return f.err
+}
+
+// runStages runs commands connected with | one after the other. The records each command
+// lists are given to the next one instead of being printed; other things it prints are shown
+// as usual when it lists nothing, and errors always go to the error output. The last command
+// prints to out, unless pipeLast is set: its records are then returned, or nil with what it
+// printed when it lists nothing.
+func (sh *Shell) runStages(stages [][]token, out io.Writer, pipeLast bool) (*records, string) {
+	shown, input, piping, piped := sh.out, sh.input, sh.piping, sh.piped
+	defer func() {
+		sh.out, sh.input, sh.piping, sh.piped = shown, input, piping, piped
+	}()
+
+	sh.input = nil
+	for i, stage := range stages {
+		commandName := stage[0].value
+		cmd, ok := commands[commandName]
+		if !ok {
+			sh.eprintln("Unknown command: " + commandName)
+			return nil, ""
+		}
+
+		args := make([]string, len(stage)-1)
+		for j, t := range stage[1:] {
+			args[j] = t.value
+			// Quoted arguments are taken literally, and ".." stays the parent folder for cd
+			if t.quoted || (commandName == "cd" && t.value == "..") {
+				continue
+			}
+			args[j] = sh.resolveAlias(t.value)
+		}
+
+		last := i == len(stages)-1
+		if last && !pipeLast {
+			sh.out, sh.piping = out, false
+			cmd.Execute(sh, args)
+			return nil, ""
+		}
+
+		var text strings.Builder
+		sh.out, sh.piping, sh.piped = &text, true, nil
+		cmd.Execute(sh, args)
+		if last {
+			return sh.piped, text.String()
+		}
+		if sh.piped == nil {
+			io.WriteString(shown, text.String())
+			sh.piped = &records{}
+		}
+		sh.input = sh.piped
+	}
+	return nil, ""
+}
+
+// renderRecords prints records, or passes them to the next command, as the listing they come
+// from would. Nothing is printed for the empty records of commands that don't list anything.
+func (sh *Shell) renderRecords(r *records) error {
+	if len(r.columns) == 0 && !sh.piping {
+		return nil
+	}
+	return renderList(sh, r.opts, r.items, r.columns)
+}
+
+// compareValues compares two column values, as numbers when both are numbers and as text,
+// ignoring case, otherwise
+func compareValues(a, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return cmp.Compare(x, y)
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// columnSuggestions suggests the columns of node listings, the records piped most often
+func columnSuggestions(word string) []prompt.Suggest {
+	var suggests []prompt.Suggest
+	for _, column := range nodeColumns {
+		suggests = append(suggests, prompt.Suggest{Text: column.Name, Description: "Node column " + column.Header})
+	}
+	return prompt.FilterHasPrefix(suggests, word, true)
+}
+
+// expandHome replaces a leading ~ in a local path with the home folder
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return home + path[1:]
+}
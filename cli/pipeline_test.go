@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+// listingClient lists a few files in every folder
+type listingClient struct {
+	mockClient
+}
+
+func (c *listingClient) ListNodes(parent string) ([]antbox.Node, error) {
+	return []antbox.Node{
+		{UUID: "uuid-report", Title: "Report.pdf", Mimetype: "application/pdf", Size: 2048},
+		{UUID: "uuid-notes", Title: "notes.txt", Mimetype: "text/plain", Size: 10},
+		{UUID: "uuid-scan", Title: "Scan.pdf", Mimetype: "application/pdf", Size: 500000},
+	}, nil
+}
+
+// runPiped runs a command line in a shell listing the nodes of listingClient
+func runPiped(line string) string {
+	var out strings.Builder
+	sh := NewShell(&listingClient{}, strings.NewReader(""), &out, &out)
+	sh.Execute(line)
+	return out.String()
+}
+
+func TestParsePipeline(t *testing.T) {
+	tokens, _ := tokenize("ls -l | where size gt 10 >> out.txt")
+	p, err := parsePipeline(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.stages) != 2 || p.stages[1][0].value != "where" || p.redirect != "out.txt" || !p.appendMode {
+		t.Errorf("unexpected pipeline: %+v", p)
+	}
+
+	for _, line := range []string{"| ls", "ls |", "ls | | count", "ls >", "ls > a b", "ls > a | count"} {
+		tokens, _ := tokenize(line)
+		if _, err := parsePipeline(tokens); err == nil {
+			t.Errorf("Expected an error for %q", line)
+		}
+	}
+}
+
+func TestPipelineFilters(t *testing.T) {
+	out := runPiped("ls | where mimetype ~= PDF | select title")
+	if !strings.Contains(out, "Report.pdf") || !strings.Contains(out, "Scan.pdf") || strings.Contains(out, "notes.txt") || strings.Contains(out, "uuid-") {
+		t.Errorf("unexpected where/select output:\n%s", out)
+	}
+
+	out = runPiped("ls --full-uuid | sort -r size | head 1")
+	if !strings.Contains(out, "uuid-scan") || strings.Contains(out, "uuid-report") {
+		t.Errorf("unexpected sort/head output:\n%s", out)
+	}
+
+	if out := runPiped("ls | where size gt 1000 | count"); strings.TrimSpace(out) != "2" {
+		t.Errorf("Expected 2 records, got %q", out)
+	}
+
+	// The format of the listing is kept through the filters
+	out = runPiped("ls -o json | where title == notes.txt")
+	if !strings.HasPrefix(out, "[") || !strings.Contains(out, `"uuid": "uuid-notes"`) || strings.Contains(out, "Report") {
+		t.Errorf("unexpected JSON output:\n%s", out)
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	if out := runPiped("where size gt 1"); !strings.Contains(out, "Usage:") {
+		t.Errorf("Expected usage when nothing is piped, got %q", out)
+	}
+	if out := runPiped("ls | where nope == 1"); !strings.Contains(out, "Error: unknown column: nope") {
+		t.Errorf("Expected an unknown column error, got %q", out)
+	}
+	// Commands that list nothing pass no records and their output is shown
+	if out := runPiped("pwd | count"); !strings.Contains(out, "0") {
+		t.Errorf("Expected no records, got %q", out)
+	}
+}
+
+func TestPipelineRedirection(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "nodes.csv")
+
+	if out := runPiped("ls -o csv > " + quoteArg(file)); strings.Contains(out, "Report.pdf") {
+		t.Errorf("Expected the listing to go to the file, got %q", out)
+	}
+	runPiped("ls | count >> " + quoteArg(file))
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "uuid,size") || !strings.HasSuffix(string(data), "\n3\n") {
+		t.Errorf("unexpected file content:\n%s", data)
+	}
+
+	// Errors are shown, not written to the file
+	errorsFile := filepath.Join(t.TempDir(), "errors.txt")
+	if out := runPiped("ls | where nope == 1 > " + quoteArg(errorsFile)); !strings.Contains(out, "Error: unknown column: nope") {
+		t.Errorf("Expected the error to be shown, got %q", out)
+	}
+	if data, _ := os.ReadFile(errorsFile); len(data) != 0 {
+		t.Errorf("Expected no error in the file, got %q", data)
+	}
+
+	// A condition whose > was read as a redirection doesn't touch the file
+	dir := t.TempDir()
+	for _, line := range []string{"find title == Doc,size > 1000", "ls | where size > 10"} {
+		target := filepath.Join(dir, strings.Fields(line)[len(strings.Fields(line))-1])
+		if err := os.WriteFile(target, []byte("precious"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		sh := NewShell(&listingClient{}, strings.NewReader(""), io.Discard, io.Discard)
+		sh.Execute(strings.Replace(line, "> ", "> "+quoteArg(dir)+"/", 1))
+		if data, _ := os.ReadFile(target); string(data) != "precious" {
+			t.Errorf("%s: expected the file to be left as it was, got %q", line, data)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+const (
+	// prefetchConcurrency is the number of folders listed at the same time in the background
+	prefetchConcurrency = 4
+	// maxPrefetchFolders is the number of subfolders of a listing that are prefetched
+	maxPrefetchFolders = 50
+)
+
+// prefetcher fetches folder listings in the background. Only one prefetch runs at a time:
+// starting another, or navigating to another folder, cancels it.
+type prefetcher struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// prefetchSubfolders caches the listings of the folders among nodes, so cd into them is
+// instant and completion can suggest what they hold. It does nothing when the cache is
+// disabled.
+func (sh *Shell) prefetchSubfolders(nodes []antbox.Node) {
+	if sh.cache == nil || sh.cacheTTL() <= 0 {
+		return
+	}
+
+	var folders []string
+	for _, node := range nodes {
+		// Smart folders are searches, evaluated only when asked for
+		if node.Mimetype == "application/vnd.antbox.folder" {
+			folders = append(folders, node.UUID)
+		}
+	}
+	sh.prefetcher.start(folders[:min(len(folders), maxPrefetchFolders)], sh.cache.Prefetch)
+}
+
+// start calls fetch for each folder in a pool of prefetchConcurrency goroutines, cancelling
+// the prefetch started before. Errors are ignored, the folder is fetched again when needed.
+func (p *prefetcher) start(folders []string, fetch func(uuid string) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		p.cancel()
+	}
+	if len(folders) == 0 {
+		p.cancel, p.done = nil, nil
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	p.cancel, p.done = cancel, done
+
+	go func() {
+		defer close(done)
+
+		jobs := make(chan string)
+		var wg sync.WaitGroup
+		for range min(prefetchConcurrency, len(folders)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for uuid := range jobs {
+					fetch(uuid)
+				}
+			}()
+		}
+
+		// Requests already sent complete, but no new ones are sent once cancelled
+	feed:
+		for _, uuid := range folders {
+			select {
+			case <-ctx.Done():
+				break feed
+			case jobs <- uuid:
+			}
+		}
+		close(jobs)
+		wg.Wait()
+	}()
+}
+
+// stop cancels the running prefetch without waiting for it
+func (p *prefetcher) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+}
+
+// wait waits for the running prefetch to finish
+func (p *prefetcher) wait() {
+	p.mu.Lock()
+	done := p.done
+	p.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+func TestPrefetcherBoundsAndCancels(t *testing.T) {
+	var p prefetcher
+	var running, maxRunning, fetched atomic.Int32
+	release := make(chan struct{})
+
+	fetch := func(uuid string) error {
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		fetched.Add(1)
+		return nil
+	}
+
+	folders := make([]string, 20)
+	for i := range folders {
+		folders[i] = string(rune('a' + i))
+	}
+	p.start(folders, fetch)
+
+	deadline := time.Now().Add(time.Second)
+	for running.Load() < prefetchConcurrency && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	p.stop()
+	close(release)
+	p.wait()
+
+	if maxRunning.Load() > prefetchConcurrency {
+		t.Errorf("Expected at most %d folders fetched at a time, got %d", prefetchConcurrency, maxRunning.Load())
+	}
+	if n := fetched.Load(); n == 0 || n >= int32(len(folders)) {
+		t.Errorf("Expected the prefetch to stop early, fetched %d of %d folders", n, len(folders))
+	}
+}
+
+func TestPrefetchSubfolders(t *testing.T) {
+	nodes := []antbox.Node{
+		{UUID: "docs", Title: "Docs", Mimetype: "application/vnd.antbox.folder"},
+		{UUID: "search", Title: "Search", Mimetype: "application/vnd.antbox.smartfolder"},
+		{UUID: "notes", Title: "notes.txt", Mimetype: "text/plain"},
+	}
+	sh := newTestShell(antbox.NewCachedClient(&folderMockClient{children: map[string][]antbox.Node{
+		"docs": {
+			{UUID: "q1", Title: "q1.pdf", Mimetype: "application/pdf"},
+			{UUID: "old", Title: "Old", Mimetype: "application/vnd.antbox.folder"},
+		},
+	}}, time.Minute))
+	sh.setCurrentNodes(nodes)
+
+	sh.prefetchSubfolders(nodes)
+	sh.prefetcher.wait()
+
+	if stats := sh.cache.Stats(); stats.Listings != 1 || stats.Misses != 0 {
+		t.Errorf("Expected only the folder to be prefetched, without misses, got %+v", stats)
+	}
+
+	suggests := getNodeSuggestions(sh, "Docs/q", nil)
+	if len(suggests) != 1 || suggests[0].Text != "q1" || suggests[0].Description != "Docs/q1.pdf" {
+		t.Errorf("Unexpected suggestions for Docs/q: %+v", suggests)
+	}
+	suggests = getNodeSuggestions(sh, "docs/", folderFilter)
+	if len(suggests) != 1 || suggests[0].Text != "old" {
+		t.Errorf("Expected only subfolders with the folder filter, got %+v", suggests)
+	}
+}
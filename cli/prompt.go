@@ -1,8 +1,12 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/kindalus/antx/antbox"
@@ -42,58 +46,60 @@ import (
 // - cp <source_uuid> <destination_uuid> [new_title]: Copy a node to another location
 // - duplicate <uuid>: Duplicate a node in the same location
 
-// - reload: Reload cached data from server (aspects, actions, extensions, agents)
+// - reload: Reload cached data from server (aspects, actions, extensions, AI tools, agents)
 // - status: Show cached data statistics
 
-var (
-	client       antbox.Antbox
-	currentNode  antbox.Node
-	currentNodes []antbox.Node
-	cliHistory   []string
-
-	// Cached data loaded at startup
-	cachedAspects    []antbox.Aspect
-	cachedActions    []antbox.Feature
-	cachedExtensions []antbox.Feature
-	cachedAgents     []antbox.Agent
-)
-
-func executor(in string) {
+// Execute runs a command line in the shell. The line is split into arguments with shell
+// quoting rules, replacing variables and $(command) substitutions, see expand; commands can
+// be connected with | and their output redirected to a local file with > or >>, see
+// runPipeline.
+func (sh *Shell) Execute(in string) {
 	in = strings.TrimSpace(in)
 
-	parts := strings.Split(in, " ")
-	commandName := parts[0]
-	args := parts[1:]
-
-	// Resolve aliases in arguments (except for cd command with .. argument)
-	for i, arg := range args {
-		// Special case: don't resolve ".." for cd command to preserve navigation behavior
-		if commandName == "cd" && arg == ".." {
-			continue
-		}
-		args[i] = resolveAlias(arg)
+	tokens, err := expand(in, sh)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		sh.println("")
+		return
+	}
+	// Nothing to run on empty lines and comments
+	if len(tokens) == 0 {
+		return
 	}
 
-	if cmd, ok := commands[commandName]; ok {
-		cmd.Execute(args)
-	} else {
-		fmt.Println("Unknown command: " + commandName)
+	p, err := parsePipeline(tokens)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		sh.println("")
+		return
 	}
+	sh.runPipeline(p)
 
 	// Add command to history AFTER execution (so currentNode is updated)
-	addCommandToHistory(in)
+	sh.addCommandToHistory(in)
 
-	fmt.Println("")
+	sh.println("")
 }
 
-func completer(d prompt.Document) []prompt.Suggest {
+// complete returns the suggestions for the command line being typed
+func (sh *Shell) complete(d prompt.Document) []prompt.Suggest {
 	text := d.TextBeforeCursor()
+	args := argsBeforeCursor(d)
+	commandName := args[0]
+
+	// Local file names after > and >> aren't completed
+	if stage, _ := stageBeforeCursor(d); slices.ContainsFunc(stage, func(t token) bool { return t.operator }) {
+		return []prompt.Suggest{}
+	}
+
+	// Variables are suggested in any argument starting with $
+	if word := args[len(args)-1]; len(args) > 1 && strings.HasPrefix(word, "$") {
+		return sh.variableSuggestions(word)
+	}
 
 	// Check if Tab was the last keystroke - if so, force show suggestions
 	if d.LastKeyStroke() == prompt.Tab || d.LastKeyStroke() == prompt.ControlI {
 		// For Tab, we want to show suggestions even when they would normally be hidden
-		args := strings.Split(text, " ")
-		commandName := strings.TrimSpace(args[0])
 
 		// Case 1: Empty text or just whitespace - show all commands
 		if strings.TrimSpace(text) == "" {
@@ -105,7 +111,7 @@ func completer(d prompt.Document) []prompt.Suggest {
 		}
 
 		// Case 2: Single word (command name) - show matching commands
-		if len(args) == 1 && !strings.HasSuffix(text, " ") {
+		if len(args) == 1 {
 			var suggests []prompt.Suggest
 			for name, cmd := range commands {
 				// Show all commands that match the prefix (including exact matches)
@@ -118,7 +124,7 @@ func completer(d prompt.Document) []prompt.Suggest {
 
 		// Case 3: Command followed by space or arguments - show command suggestions
 		if cmd, ok := commands[commandName]; ok {
-			return cmd.Suggest(d)
+			return quoteSuggestions(text, cmd.Suggest(sh, d))
 		}
 
 		// Case 4: Invalid command - show all commands as fallback
@@ -134,13 +140,10 @@ func completer(d prompt.Document) []prompt.Suggest {
 		return []prompt.Suggest{}
 	}
 
-	args := strings.Split(text, " ")
-	commandName := args[0]
-
 	// If we're typing the first word (command name)
 	if len(args) == 1 {
-		// Hide suggestions if command is followed by space (user moved on)
-		if strings.HasSuffix(text, " ") {
+		// Hide suggestions until a command after | is started
+		if commandName == "" {
 			return []prompt.Suggest{}
 		}
 
@@ -161,33 +164,34 @@ func completer(d prompt.Document) []prompt.Suggest {
 
 	// For arguments: only show if command exists and we're actively typing
 	if cmd, ok := commands[commandName]; ok {
-		// Hide if multiple consecutive spaces (user finished typing)
-		if strings.Contains(text, "  ") {
-			return []prompt.Suggest{}
-		}
-
-		// Hide if last character is space and previous wasn't (user just added space)
-		if len(text) > 1 && strings.HasSuffix(text, " ") && !strings.HasSuffix(text[:len(text)-1], " ") {
-			// Get current word being typed
-			lastWord := ""
-			if len(args) > 1 {
-				lastWord = args[len(args)-1]
+		// Hide if multiple consecutive blanks between arguments (user finished typing).
+		// Blanks inside quotes are part of an argument.
+		tokens, _ := tokenize(text)
+		for i, t := range tokens {
+			next := len(text)
+			if i+1 < len(tokens) {
+				next = tokens[i+1].start
 			}
-
-			// Only hide if the last word looks complete (no partial typing)
-			if lastWord == "" || len(lastWord) > 2 {
+			if next-t.end > 1 {
 				return []prompt.Suggest{}
 			}
 		}
 
-		return cmd.Suggest(d)
+		// Hide if the user just added a blank after an argument
+		if cursorAfterBlank(d) {
+			return []prompt.Suggest{}
+		}
+
+		return quoteSuggestions(text, cmd.Suggest(sh, d))
 	}
 
 	return []prompt.Suggest{}
 }
 
+// Start connects to the server and runs an interactive shell, keeping its state in the
+// config file between runs
 func Start(serverURL, apiKey, root, jwt string, debug bool) {
-	client = antbox.NewClient(serverURL, apiKey, root, jwt, debug)
+	client := antbox.NewCachedClient(antbox.NewClient(serverURL, apiKey, root, jwt, debug), defaultCacheTTL)
 	if root != "" {
 		if err := client.Login(); err != nil {
 			fmt.Println("Login failed:", err)
@@ -195,34 +199,41 @@ func Start(serverURL, apiKey, root, jwt string, debug bool) {
 		}
 	}
 
+	sh := NewShell(client, os.Stdin, os.Stdout, os.Stderr)
+	sh.server = serverURL
+	sh.identity = cacheIdentity(apiKey, root, jwt)
+	sh.persistent = true
+	sh.sessions = newSessionManager(newSessionStore())
+
 	// Initialize current node and load cached data at startup
-	initializeCurrentNodeAndCacheData()
+	sh.initializeCurrentNodeAndCacheData()
 
 	// Restore CLI state from saved configuration
-	if err := restoreFromConfig(); err != nil {
-		fmt.Printf("Note: Could not restore previous session: %v\n", err)
+	if err := sh.restoreFromConfig(); err != nil {
+		sh.printf("Note: Could not restore previous session: %v\n", err)
 	}
+	sh.configureCache()
 
 	// Show breadcrumbs on startup
-	showStartupBreadcrumbs()
+	sh.showStartupBreadcrumbs()
 
 	// Initial ls
 	if cmd, ok := commands["ls"]; ok {
-		cmd.Execute([]string{})
+		cmd.Execute(sh, []string{})
 	}
 
 	p := prompt.New(
-		executor,
-		completer,
+		sh.Execute,
+		sh.complete,
 		prompt.OptionTitle("Antbox CLI"),
 		prompt.OptionLivePrefix(func() (string, bool) {
-			folderName := getCurrentFolderName()
+			folderName := sh.getCurrentFolderName()
 			return fmt.Sprintf("%s # ", folderName), true
 		}),
 		prompt.OptionCompletionWordSeparator(" "),
 		prompt.OptionMaxSuggestion(10),
 		// Integrate saved history for up/down arrow navigation
-		prompt.OptionHistory(cliHistory),
+		prompt.OptionHistory(sh.getHistory()),
 		// Add custom key bindings for better completion control
 		prompt.OptionAddKeyBind(prompt.KeyBind{
 			Key: prompt.Escape,
@@ -238,39 +249,36 @@ func Start(serverURL, apiKey, root, jwt string, debug bool) {
 		}),
 	)
 	p.Run()
+	sh.savePersistedCache()
 }
 
 // initializeCurrentNodeAndCacheData initializes current node and loads cached data at startup
-func initializeCurrentNodeAndCacheData() {
-	fmt.Print("Initializing... ")
+func (sh *Shell) initializeCurrentNodeAndCacheData() {
+	sh.print("Initializing... ")
 
 	// Initialize current node (start at root - will be overridden by restoreFromConfig if saved state exists)
-	currentNode = antbox.Node{
-		UUID:     "--root--",
-		Title:    "root",
-		Mimetype: "application/vnd.antbox.folder",
-	}
-
-	// Initialize empty history
-	cliHistory = []string{}
+	sh.setCurrentNode(rootNode())
 
 	// Load current folder contents
-	if nodes, err := client.ListNodes("--root--"); err == nil {
-		currentNodes = nodes
+	if nodes, err := sh.client.ListNodes("--root--"); err == nil {
+		sh.setCurrentNodes(nodes)
 	}
 
 	// Load cached data
-	loadCachedData()
+	sh.loadCachedData()
+
+	// Apply the retention policy to saved chat and RAG sessions
+	sh.sessions.pruneSavedSessions()
 
-	fmt.Println("✓ Ready")
+	sh.println("✓ Ready")
 }
 
 // showStartupBreadcrumbs displays the current location path on startup
-func showStartupBreadcrumbs() {
-	breadcrumbs, err := client.GetBreadcrumbs(currentNode.UUID)
+func (sh *Shell) showStartupBreadcrumbs() {
+	breadcrumbs, err := sh.client.GetBreadcrumbs(sh.getCurrentNode().UUID)
 	if err != nil {
 		// Fallback to simple display
-		fmt.Printf("Current location: %s\n\n", getCurrentFolderName())
+		sh.printf("Current location: %s\n\n", sh.getCurrentFolderName())
 		return
 	}
 
@@ -283,44 +291,52 @@ func showStartupBreadcrumbs() {
 	}
 
 	if len(pathParts) == 0 {
-		fmt.Printf("Current location: /\n\n")
+		sh.printf("Current location: /\n\n")
 	} else {
-		fmt.Printf("Current location: /%s\n\n", strings.Join(pathParts, "/"))
+		sh.printf("Current location: /%s\n\n", strings.Join(pathParts, "/"))
 	}
 }
 
-// loadCachedData loads aspects, actions, extensions, and agents
-func loadCachedData() {
+// loadCachedData loads aspects, actions, extensions, AI tools, and agents
+func (sh *Shell) loadCachedData() {
 	var loaded []string
 	var failed []string
 
 	// Load aspects
-	if aspects, err := client.ListAspects(); err == nil {
-		cachedAspects = aspects
+	if aspects, err := sh.client.ListAspects(); err == nil {
+		sh.updateResources(func() { sh.aspects = aspects })
 		loaded = append(loaded, fmt.Sprintf("%d aspects", len(aspects)))
 	} else {
 		failed = append(failed, "aspects")
 	}
 
 	// Load actions
-	if actions, err := client.ListActions(); err == nil {
-		cachedActions = actions
+	if actions, err := sh.client.ListActions(); err == nil {
+		sh.updateResources(func() { sh.actions = actions })
 		loaded = append(loaded, fmt.Sprintf("%d actions", len(actions)))
 	} else {
 		failed = append(failed, "actions")
 	}
 
 	// Load extensions
-	if extensions, err := client.ListExtensions(); err == nil {
-		cachedExtensions = extensions
+	if extensions, err := sh.client.ListExtensions(); err == nil {
+		sh.updateResources(func() { sh.extensions = extensions })
 		loaded = append(loaded, fmt.Sprintf("%d extensions", len(extensions)))
 	} else {
 		failed = append(failed, "extensions")
 	}
 
+	// Load AI tools
+	if tools, err := sh.client.ListAITools(); err == nil {
+		sh.updateResources(func() { sh.tools = tools })
+		loaded = append(loaded, fmt.Sprintf("%d tools", len(tools)))
+	} else {
+		failed = append(failed, "tools")
+	}
+
 	// Load agents
-	if agents, err := client.ListAgents(); err == nil {
-		cachedAgents = agents
+	if agents, err := sh.client.ListAgents(); err == nil {
+		sh.updateResources(func() { sh.agents = agents })
 		loaded = append(loaded, fmt.Sprintf("%d agents", len(agents)))
 	} else {
 		failed = append(failed, "agents")
@@ -328,21 +344,26 @@ func loadCachedData() {
 
 	// Report results quietly during initialization
 	if len(failed) > 0 {
-		fmt.Printf(" ✗ Failed: %s", strings.Join(failed, ", "))
+		sh.printf(" ✗ Failed: %s", strings.Join(failed, ", "))
 	}
 }
 
 // reloadCachedData reloads all cached data from the server
-func reloadCachedData() error {
-	fmt.Print("Reloading resources from server... ")
+func (sh *Shell) reloadCachedData() error {
+	sh.print("Reloading resources from server... ")
+
+	// Nodes and listings are fetched again when next needed
+	if sh.cache != nil {
+		sh.cache.Invalidate()
+	}
 
 	var loaded []string
 	var failed []string
 	var errors []string
 
 	// Reload aspects
-	if aspects, err := client.ListAspects(); err == nil {
-		cachedAspects = aspects
+	if aspects, err := sh.client.ListAspects(); err == nil {
+		sh.updateResources(func() { sh.aspects = aspects })
 		loaded = append(loaded, fmt.Sprintf("%d aspects", len(aspects)))
 	} else {
 		failed = append(failed, "aspects")
@@ -350,8 +371,8 @@ func reloadCachedData() error {
 	}
 
 	// Reload actions
-	if actions, err := client.ListActions(); err == nil {
-		cachedActions = actions
+	if actions, err := sh.client.ListActions(); err == nil {
+		sh.updateResources(func() { sh.actions = actions })
 		loaded = append(loaded, fmt.Sprintf("%d actions", len(actions)))
 	} else {
 		failed = append(failed, "actions")
@@ -359,17 +380,26 @@ func reloadCachedData() error {
 	}
 
 	// Reload extensions
-	if extensions, err := client.ListExtensions(); err == nil {
-		cachedExtensions = extensions
+	if extensions, err := sh.client.ListExtensions(); err == nil {
+		sh.updateResources(func() { sh.extensions = extensions })
 		loaded = append(loaded, fmt.Sprintf("%d extensions", len(extensions)))
 	} else {
 		failed = append(failed, "extensions")
 		errors = append(errors, fmt.Sprintf("extensions: %v", err))
 	}
 
+	// Reload AI tools
+	if tools, err := sh.client.ListAITools(); err == nil {
+		sh.updateResources(func() { sh.tools = tools })
+		loaded = append(loaded, fmt.Sprintf("%d tools", len(tools)))
+	} else {
+		failed = append(failed, "tools")
+		errors = append(errors, fmt.Sprintf("tools: %v", err))
+	}
+
 	// Reload agents
-	if agents, err := client.ListAgents(); err == nil {
-		cachedAgents = agents
+	if agents, err := sh.client.ListAgents(); err == nil {
+		sh.updateResources(func() { sh.agents = agents })
 		loaded = append(loaded, fmt.Sprintf("%d agents", len(agents)))
 	} else {
 		failed = append(failed, "agents")
@@ -377,62 +407,104 @@ func reloadCachedData() error {
 	}
 
 	if len(failed) == 0 {
-		fmt.Printf("done (%s)\n", strings.Join(loaded, ", "))
+		sh.printf("done (%s)\n", strings.Join(loaded, ", "))
 		return nil
 	} else {
-		fmt.Printf("done with errors\n")
+		sh.printf("done with errors\n")
 		if len(loaded) > 0 {
-			fmt.Printf("  Successfully loaded: %s\n", strings.Join(loaded, ", "))
+			sh.printf("  Successfully loaded: %s\n", strings.Join(loaded, ", "))
 		}
-		fmt.Printf("  Failed to load: %s\n", strings.Join(failed, ", "))
+		sh.printf("  Failed to load: %s\n", strings.Join(failed, ", "))
 		for _, errMsg := range errors {
-			fmt.Printf("    %s\n", errMsg)
+			sh.printf("    %s\n", errMsg)
 		}
 		return fmt.Errorf("%d resources failed to load", len(failed))
 	}
 }
 
-// GetCachedActions returns the cached list of actions
-func GetCachedActions() []antbox.Feature {
-	return cachedActions
-}
-
-// GetCachedExtensions returns the cached list of extensions
-func GetCachedExtensions() []antbox.Feature {
-	return cachedExtensions
-}
-
-// GetCachedAgents returns the cached list of agents
-func GetCachedAgents() []antbox.Agent {
-	return cachedAgents
-}
-
 // getCurrentFolderName returns the display name for the current folder
-func getCurrentFolderName() string {
-	if currentNode.UUID == "--root--" {
+func (sh *Shell) getCurrentFolderName() string {
+	current := sh.getCurrentNode()
+	if current.UUID == "--root--" {
 		return "root"
 	}
-	return currentNode.Title
+	return current.Title
 }
 
 // resolveAlias resolves special aliases to actual UUIDs
 // . -> current node UUID
 // .. -> parent node UUID
-func resolveAlias(arg string) string {
+func (sh *Shell) resolveAlias(arg string) string {
 	switch arg {
 	case ".":
-		return currentNode.UUID
+		return sh.getCurrentNode().UUID
 	case "..":
-		if currentNode.Parent == "" {
-			return "--root--"
+		if parent := sh.getCurrentNode().Parent; parent != "" {
+			return parent
 		}
-		return currentNode.Parent
+		return "--root--"
 	default:
 		return arg
 	}
 }
 
-// GetCachedAspects returns the cached list of aspects
-func GetCachedAspects() []antbox.Aspect {
-	return cachedAspects
+// variable returns the value of a $name reference: a variable set with set, $_ for the node
+// last created or selected, or $1, $2... for the UUIDs of the rows of the last listing. It
+// reports false when the reference isn't set.
+func (sh *Shell) variable(name string) (string, bool) {
+	if name == "_" {
+		return sh.lastNode, sh.lastNode != ""
+	}
+	if row, err := strconv.Atoi(name); err == nil {
+		if row < 1 || row > len(sh.rows) {
+			return "", false
+		}
+		return sh.rows[row-1], true
+	}
+	value, ok := sh.variables[name]
+	return value, ok
+}
+
+// variableSuggestions suggests the variables matching a $ reference being typed
+func (sh *Shell) variableSuggestions(word string) []prompt.Suggest {
+	var suggests []prompt.Suggest
+	for _, name := range slices.Sorted(maps.Keys(sh.variables)) {
+		suggests = append(suggests, prompt.Suggest{Text: "$" + name, Description: sh.variables[name]})
+	}
+	if sh.lastNode != "" {
+		suggests = append(suggests, prompt.Suggest{Text: "$_", Description: "Node last created or selected: " + sh.lastNode})
+	}
+	for i, uuid := range sh.rows {
+		suggests = append(suggests, prompt.Suggest{Text: "$" + strconv.Itoa(i+1), Description: "Row of the last listing: " + uuid})
+	}
+	return prompt.FilterHasPrefix(suggests, word, false)
+}
+
+// substitute runs the command of a $(command) substitution and returns the UUIDs of the
+// records it lists, one per line, or what it prints when it lists nothing
+func (sh *Shell) substitute(command string) (string, error) {
+	tokens, err := expand(command, sh)
+	if err != nil {
+		return "", err
+	}
+	if len(tokens) == 0 {
+		return "", nil
+	}
+	p, err := parsePipeline(tokens)
+	if err != nil {
+		return "", err
+	}
+	if p.redirect != "" {
+		return "", errors.New("the output of $(...) can't be redirected")
+	}
+
+	recs, text := sh.runStages(p.stages, sh.out, true)
+	if recs == nil {
+		return strings.TrimRight(text, "\n"), nil
+	}
+	uuids, err := recs.uuids()
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(uuids, "\n"), nil
 }
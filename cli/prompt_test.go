@@ -1,12 +1,11 @@
 package cli
 
 import (
+	"io"
 	"net/http"
-	"reflect"
 	"slices"
 	"strings"
 	"testing"
-	"unsafe"
 
 	"github.com/kindalus/antx/antbox"
 
@@ -141,6 +140,18 @@ func (c *mockClient) RagChat(message string, options map[string]any) (antbox.Cha
 	}, nil
 }
 
+func (c *mockClient) ChatWithAgentStream(agentUUID string, message string, conversationID string, temperature *float64, maxTokens *int, history []map[string]any, chatContext map[string]any, onPart antbox.ChatStreamHandler) (antbox.ChatHistory, error) {
+	return c.ChatWithAgent(agentUUID, message, conversationID, temperature, maxTokens, history)
+}
+
+func (c *mockClient) AnswerFromAgentStream(agentUUID string, query string, temperature *float64, maxTokens *int, chatContext map[string]any, onPart antbox.ChatStreamHandler) (antbox.ChatHistory, error) {
+	return c.AnswerFromAgent(agentUUID, query, temperature, maxTokens)
+}
+
+func (c *mockClient) RagChatStream(message string, options map[string]any, onPart antbox.ChatStreamHandler) (antbox.ChatHistory, error) {
+	return c.RagChat(message, options)
+}
+
 // New interface methods
 func (c *mockClient) CopyNode(uuid, parent, title string) (*antbox.Node, error) {
 	return &antbox.Node{UUID: "copied-uuid", Title: title, Parent: parent}, nil
@@ -323,42 +334,37 @@ func (c *mockClient) GetDoc(uuid string) (string, error) {
 }
 
 func TestExecutor(t *testing.T) {
-	client = &mockClient{}
+	sh := newTestShell(&mockClient{})
 
-	executor("ls")
-	executor("pwd")
-	executor("stat test-uuid")
-	executor("cd test-uuid")
-	executor("cd ..")
-	executor("mkdir test-folder")
-	executor("mksmart Facturas_2025 title match comprovativo")
-	executor("update test-uuid /path/to/file.txt")
+	sh.Execute("ls")
+	sh.Execute("pwd")
+	sh.Execute("stat test-uuid")
+	sh.Execute("cd test-uuid")
+	sh.Execute("cd ..")
+	sh.Execute("mkdir test-folder")
+	sh.Execute("mksmart Facturas_2025 title match comprovativo")
+	sh.Execute("update test-uuid /path/to/file.txt")
 }
 
-// Helper function to create a properly configured Document for testing
+// Helper function to create a Document with the cursor at the end of text
 func createTestDocument(text string) prompt.Document {
-	doc := prompt.Document{Text: text}
-
-	// Use reflection to set the private cursorPosition field to the end of text
-	v := reflect.ValueOf(&doc).Elem()
-	cursorField := v.FieldByName("cursorPosition")
-
-	if cursorField.IsValid() {
-		// Make the field accessible
-		cursorField = reflect.NewAt(cursorField.Type(), unsafe.Pointer(cursorField.UnsafeAddr())).Elem()
-		cursorField.SetInt(int64(len(text)))
-	}
+	buf := prompt.NewBuffer()
+	buf.InsertText(text, false, true)
+	return *buf.Document()
+}
 
-	return doc
+// newTestShell returns a shell using client that discards its output
+func newTestShell(client antbox.Antbox) *Shell {
+	return NewShell(client, strings.NewReader(""), io.Discard, io.Discard)
 }
 
 func TestCompleter(t *testing.T) {
-	client = &mockClient{}
-	currentNodes = []antbox.Node{{UUID: "test-uuid", Title: "test-title", Mimetype: "application/vnd.antbox.folder", CreatedAt: "2024-01-01T12:00:00Z", ModifiedAt: "2024-01-01T12:00:00Z"}}
+	sh := newTestShell(&mockClient{})
+	sh.currentNodes = []antbox.Node{{UUID: "test-uuid", Title: "test-title", Mimetype: "application/vnd.antbox.folder", CreatedAt: "2024-01-01T12:00:00Z", ModifiedAt: "2024-01-01T12:00:00Z"}}
 
 	// Test with single character - should return command suggestions for prefix matches
 	doc := createTestDocument("l")
-	suggests := completer(doc)
+	suggests := sh.complete(doc)
 	if len(suggests) != 1 {
 		t.Errorf("Expected 1 suggestion for 'l', got %d", len(suggests))
 	}
@@ -368,23 +374,23 @@ func TestCompleter(t *testing.T) {
 
 	// Test with exact command match - should return no suggestions
 	doc = createTestDocument("ls")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 	if len(suggests) != 0 {
 		t.Errorf("Expected 0 suggestions for exact command 'ls', got %d", len(suggests))
 	}
 
-	// Test ls command with arguments - ls doesn't provide node suggestions
+	// Test ls command with arguments - ls suggests folders, like cd
 	doc = createTestDocument("ls te")
-	suggests = completer(doc)
-	if len(suggests) != 0 {
-		t.Errorf("Expected 0 suggestions for 'ls te', got %d", len(suggests))
+	suggests = sh.complete(doc)
+	if len(suggests) != 1 || suggests[0].Text != "test-uuid" {
+		t.Errorf("Expected the folder 'test-uuid' for 'ls te', got %+v", suggests)
 	}
 
 	// Test cd command - should use UUID for folder suggestions
 	doc = createTestDocument("cd te")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 	if len(suggests) != 1 {
-		t.Errorf("Expected 1 suggestion for 'cd te', got %d. Current nodes: %+v", len(suggests), currentNodes)
+		t.Errorf("Expected 1 suggestion for 'cd te', got %d. Current nodes: %+v", len(suggests), sh.currentNodes)
 		for i, s := range suggests {
 			t.Errorf("Suggestion %d: Text='%s', Description='%s'", i, s.Text, s.Description)
 		}
@@ -398,18 +404,18 @@ func TestCompleter(t *testing.T) {
 		}
 	}
 
-	// Test command with single char argument - should return no suggestions (word too short)
-	doc = createTestDocument("ls t")
-	suggests = completer(doc)
+	// Test command with single char argument - pwd takes no arguments
+	doc = createTestDocument("pwd t")
+	suggests = sh.complete(doc)
 	if len(suggests) != 0 {
 		t.Errorf("Expected 0 suggestions for single char argument, got %d", len(suggests))
 	}
 }
 
 func TestCompleterCdWithMixedNodeTypes(t *testing.T) {
-	client = &mockClient{}
+	sh := newTestShell(&mockClient{})
 	// Mix of folder and file nodes
-	currentNodes = []antbox.Node{
+	sh.currentNodes = []antbox.Node{
 		{UUID: "folder-uuid-1", Title: "documents", Mimetype: "application/vnd.antbox.folder", CreatedAt: "2024-01-01T12:00:00Z", ModifiedAt: "2024-01-01T12:00:00Z"},
 		{UUID: "file-uuid-1", Title: "document.txt", Mimetype: "text/plain", CreatedAt: "2024-01-01T12:00:00Z", ModifiedAt: "2024-01-01T12:00:00Z"},
 		{UUID: "folder-uuid-2", Title: "downloads", Mimetype: "application/vnd.antbox.folder", CreatedAt: "2024-01-01T12:00:00Z", ModifiedAt: "2024-01-01T12:00:00Z"},
@@ -417,7 +423,7 @@ func TestCompleterCdWithMixedNodeTypes(t *testing.T) {
 
 	// Test cd command with folder prefix - should only suggest folders and use UUIDs
 	doc := createTestDocument("cd do")
-	suggests := completer(doc)
+	suggests := sh.complete(doc)
 
 	// Should get 2 suggestions: documents and downloads (both folders)
 	if len(suggests) != 2 {
@@ -435,18 +441,17 @@ func TestCompleterCdWithMixedNodeTypes(t *testing.T) {
 		}
 	}
 
-	// Test ls command with same prefix - ls doesn't provide node suggestions
+	// Test ls command with same prefix - ls only suggests folders too
 	doc = createTestDocument("ls do")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 
-	// ls command doesn't provide node suggestions, should get 0
-	if len(suggests) != 0 {
-		t.Errorf("Expected 0 suggestions for 'ls do', got %d", len(suggests))
+	if len(suggests) != 2 {
+		t.Errorf("Expected 2 folder suggestions for 'ls do', got %d", len(suggests))
 	}
 
 	// Test cd with exact folder name
 	doc = createTestDocument("cd documents")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 
 	if len(suggests) != 1 {
 		t.Errorf("Expected 1 suggestion for exact folder name, got %d", len(suggests))
@@ -458,29 +463,29 @@ func TestCompleterCdWithMixedNodeTypes(t *testing.T) {
 }
 
 func TestCompleterNewCommands(t *testing.T) {
-	client = &mockClient{}
-	currentNodes = []antbox.Node{
+	sh := newTestShell(&mockClient{})
+	sh.currentNodes = []antbox.Node{
 		{UUID: "folder-uuid", Title: "test-folder", Mimetype: "application/vnd.antbox.folder", CreatedAt: "2024-01-01T12:00:00Z", ModifiedAt: "2024-01-01T12:00:00Z"},
 		{UUID: "file-uuid", Title: "test-file.txt", Mimetype: "text/plain", CreatedAt: "2024-01-01T12:00:00Z", ModifiedAt: "2024-01-01T12:00:00Z"},
 	}
 
 	// Test rm command suggestions (should suggest all nodes)
 	doc := createTestDocument("rm te")
-	suggests := completer(doc)
+	suggests := sh.complete(doc)
 	if len(suggests) != 2 {
 		t.Errorf("Expected 2 suggestions for 'rm te', got %d", len(suggests))
 	}
 
 	// Test mv command first argument (should suggest all nodes)
 	doc = createTestDocument("mv te")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 	if len(suggests) != 2 {
 		t.Errorf("Expected 2 suggestions for 'mv te', got %d", len(suggests))
 	}
 
 	// Test mv command second argument (should only suggest folders with UUID)
 	doc = createTestDocument("mv test-uuid te")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 	if len(suggests) != 1 {
 		t.Errorf("Expected 1 suggestion for mv second argument, got %d", len(suggests))
 	}
@@ -490,35 +495,35 @@ func TestCompleterNewCommands(t *testing.T) {
 
 	// Test cp command first argument (should not provide suggestions)
 	doc = createTestDocument("cp /path/to/file")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 	if len(suggests) != 0 {
 		t.Errorf("Expected 0 suggestions for cp file path, got %d", len(suggests))
 	}
 
 	// Test cp command - cp is implemented, should get folder suggestions only
 	doc = createTestDocument("cp /path/to/file te")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 	if len(suggests) != 1 {
 		t.Errorf("Expected 1 suggestion for cp command (folders only), got %d", len(suggests))
 	}
 
 	// Test stat command suggestions (should suggest all nodes)
 	doc = createTestDocument("stat te")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 	if len(suggests) != 2 {
 		t.Errorf("Expected 2 suggestions for 'stat te', got %d", len(suggests))
 	}
 
 	// Test rename command suggestions (should suggest all nodes)
 	doc = createTestDocument("rename te")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 	if len(suggests) != 2 {
 		t.Errorf("Expected 2 suggestions for 'rename te', got %d", len(suggests))
 	}
 }
 
 func TestCommandSuggestions(t *testing.T) {
-	client = &mockClient{}
+	sh := newTestShell(&mockClient{})
 
 	// Test that all commands are suggested when typing partial matches
 	testCases := []struct {
@@ -532,7 +537,7 @@ func TestCommandSuggestions(t *testing.T) {
 		{"cd", []string{}}, // exact matches return no suggestions
 		{"up", []string{"upload"}},
 		{"ex", []string{"exec", "exit", "extensions"}},
-		{"he", []string{"help"}},
+		{"he", []string{"head", "help"}},
 		{"pw", []string{"pwd"}},
 		{"st", []string{"stat", "status"}},
 		{"fi", []string{"find"}},
@@ -548,7 +553,7 @@ func TestCommandSuggestions(t *testing.T) {
 
 	for _, tc := range testCases {
 		doc := createTestDocument(tc.input)
-		suggests := completer(doc)
+		suggests := sh.complete(doc)
 
 		if len(suggests) != len(tc.expected) {
 			t.Errorf("For input '%s': expected %d suggestions, got %d", tc.input, len(tc.expected), len(suggests))
@@ -571,7 +576,7 @@ func TestCommandSuggestions(t *testing.T) {
 }
 
 func TestCommandSuggestionsMinLength(t *testing.T) {
-	client = &mockClient{}
+	sh := newTestShell(&mockClient{})
 
 	// Test that single character inputs return no suggestions
 	// Test inputs that should return command suggestions
@@ -582,15 +587,15 @@ func TestCommandSuggestionsMinLength(t *testing.T) {
 		{"l", 1}, // should match "ls"
 		{"r", 5}, // should match "rm", "rename", "rag", "reload", "run"
 		{"m", 3}, // should match "mkdir", "mv", "mksmart"
-		{"c", 3}, // should match "cd", "chat", "cp"
-		{"e", 3}, // should match "exec", "exit", "extensions"
-		{"a", 4}, // should match "agents", "actions", "answer", "aliases"
-		{"h", 2}, // should match "help", "history"
+		{"c", 6}, // should match "cd", "chat", "clone", "config", "count", "cp"
+		{"e", 4}, // should match "edit", "exec", "exit", "extensions"
+		{"a", 5}, // should match "agents", "actions", "answer", "aliases", "aspects"
+		{"h", 3}, // should match "head", "help", "history"
 	}
 
 	for _, test := range testInputs {
 		doc := createTestDocument(test.input)
-		suggests := completer(doc)
+		suggests := sh.complete(doc)
 
 		if len(suggests) != test.expected {
 			t.Errorf("For input '%s': expected %d suggestions, got %d", test.input, test.expected, len(suggests))
@@ -599,22 +604,22 @@ func TestCommandSuggestionsMinLength(t *testing.T) {
 
 	// Test that exact command matches return no suggestions
 	doc := createTestDocument("ls")
-	suggests := completer(doc)
+	suggests := sh.complete(doc)
 	if len(suggests) != 0 {
 		t.Errorf("Expected 0 suggestions for exact command 'ls', got %d suggestions", len(suggests))
 	}
 }
 
 func TestUploadUpdateCommandSuggestions(t *testing.T) {
-	client = &mockClient{}
-	currentNodes = []antbox.Node{
+	sh := newTestShell(&mockClient{})
+	sh.currentNodes = []antbox.Node{
 		{UUID: "folder-uuid", Title: "test-folder", Mimetype: "application/vnd.antbox.folder", CreatedAt: "2024-01-01T12:00:00Z", ModifiedAt: "2024-01-01T12:00:00Z"},
 		{UUID: "file-uuid", Title: "test-file.txt", Mimetype: "text/plain", CreatedAt: "2024-01-01T12:00:00Z", ModifiedAt: "2024-01-01T12:00:00Z"},
 	}
 
 	// Test upload -u command first argument (should suggest only files, not folders)
 	doc := createTestDocument("upload -u te")
-	suggests := completer(doc)
+	suggests := sh.complete(doc)
 	if len(suggests) != 1 {
 		t.Errorf("Expected 1 suggestion for 'upload -u te', got %d", len(suggests))
 	}
@@ -640,23 +645,23 @@ func TestUploadUpdateCommandSuggestions(t *testing.T) {
 }
 
 func TestFindCommand(t *testing.T) {
-	client = &mockClient{}
+	sh := newTestShell(&mockClient{})
 
 	// Test find with simple search
 	doc := createTestDocument("find te")
-	suggests := completer(doc)
+	suggests := sh.complete(doc)
 	if len(suggests) != 0 {
 		t.Errorf("Expected 0 suggestions for find command, got %d", len(suggests))
 	}
 }
 
 func TestUploadUpdateCommand(t *testing.T) {
-	client = &mockClient{}
-	currentNodes = []antbox.Node{{UUID: "test-uuid", Title: "test-file.txt", Mimetype: "text/plain"}}
+	sh := newTestShell(&mockClient{})
+	sh.currentNodes = []antbox.Node{{UUID: "test-uuid", Title: "test-file.txt", Mimetype: "text/plain"}}
 
 	// Test upload -u command first argument - should suggest nodes
 	doc := createTestDocument("upload -u te")
-	suggests := completer(doc)
+	suggests := sh.complete(doc)
 	if len(suggests) != 1 {
 		t.Errorf("Expected 1 suggestion for 'upload -u te', got %d", len(suggests))
 	}
@@ -666,7 +671,7 @@ func TestUploadUpdateCommand(t *testing.T) {
 }
 
 func TestUploadFeatureCommand(t *testing.T) {
-	client = &mockClient{}
+	sh := newTestShell(&mockClient{})
 
 	// Test that upload -f executes without error
 	uploadCmd := &UploadCommand{}
@@ -680,11 +685,11 @@ func TestUploadFeatureCommand(t *testing.T) {
 		}
 	}()
 
-	uploadCmd.Execute([]string{"-f", "/path/to/feature.js"})
+	uploadCmd.Execute(sh, []string{"-f", "/path/to/feature.js"})
 }
 
 func TestUploadAspectCommand(t *testing.T) {
-	client = &mockClient{}
+	sh := newTestShell(&mockClient{})
 
 	// Test that upload -a executes without error
 	uploadCmd := &UploadCommand{}
@@ -696,15 +701,15 @@ func TestUploadAspectCommand(t *testing.T) {
 		}
 	}()
 
-	uploadCmd.Execute([]string{"-a", "/path/to/aspect.xml"})
+	uploadCmd.Execute(sh, []string{"-a", "/path/to/aspect.xml"})
 }
 
 func TestUploadCommandFlags(t *testing.T) {
-	client = &mockClient{}
+	sh := newTestShell(&mockClient{})
 
 	// Test upload command flag suggestions
 	doc := createTestDocument("upload -")
-	suggests := completer(doc)
+	suggests := sh.complete(doc)
 
 	expectedFlags := []string{"-f", "-a", "-i", "-u"}
 	if len(suggests) != len(expectedFlags) {
@@ -719,7 +724,7 @@ func TestUploadCommandFlags(t *testing.T) {
 }
 
 func TestCommandSuggestionsWithNewCommands(t *testing.T) {
-	client = &mockClient{}
+	sh := newTestShell(&mockClient{})
 
 	testCases := []struct {
 		input    string
@@ -731,7 +736,7 @@ func TestCommandSuggestionsWithNewCommands(t *testing.T) {
 
 	for _, tc := range testCases {
 		doc := createTestDocument(tc.input)
-		suggests := completer(doc)
+		suggests := sh.complete(doc)
 
 		if len(suggests) != len(tc.expected) {
 			t.Errorf("For input '%s': expected %d suggestions, got %d", tc.input, len(tc.expected), len(suggests))
@@ -755,28 +760,28 @@ func TestCommandSuggestionsWithNewCommands(t *testing.T) {
 
 func TestRunCommandSuggestions(t *testing.T) {
 	// Setup enhanced mock client with actions that have proper flags
-	client = &enhancedMockClient{}
+	sh := newTestShell(&enhancedMockClient{})
 
 	// Load cached actions to simulate startup
-	actions, err := client.ListActions()
+	actions, err := sh.client.ListActions()
 	if err != nil {
 		t.Fatalf("Failed to get actions: %v", err)
 	}
-	cachedActions = actions
+	sh.actions = actions
 
 	// Also set up some mock nodes for testing
-	currentNodes = []antbox.Node{
+	sh.currentNodes = []antbox.Node{
 		{UUID: "node-uuid", Title: "Test Node", Mimetype: "text/plain"},
 		{UUID: "folder-uuid", Title: "Test Folder", Mimetype: "application/vnd.antbox.folder"},
 	}
 
 	// Test action suggestion (first argument)
 	doc := createTestDocument("run a")
-	suggests := completer(doc)
+	suggests := sh.complete(doc)
 
 	// Should only suggest actions that are exposed as actions and can be run manually
 	if len(suggests) == 0 {
-		t.Errorf("Expected at least one action suggestion, got %d. Available actions: %v", len(suggests), cachedActions)
+		t.Errorf("Expected at least one action suggestion, got %d. Available actions: %v", len(suggests), sh.actions)
 	}
 
 	// Verify we only get the enabled action, not the disabled one
@@ -808,18 +813,18 @@ func TestRunCommandSuggestions(t *testing.T) {
 
 	// Test node suggestion (second argument) - should use action filters
 	doc = createTestDocument("run action-uuid n")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 
 	// Should suggest nodes (implementation depends on mock data)
 	// The key improvement is that it should filter nodes based on action filters
 	// For this test, we just verify that suggestions are returned
 	if len(suggests) == 0 {
-		t.Errorf("Expected node suggestions for second argument, got %d. Available nodes: %v", len(suggests), currentNodes)
+		t.Errorf("Expected node suggestions for second argument, got %d. Available nodes: %v", len(suggests), sh.currentNodes)
 	}
 
 	// Test parameter suggestion (third+ arguments)
 	doc = createTestDocument("run action-uuid node-uuid f")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 
 	// Should suggest parameters with '=' suffix that start with 'f'
 	foundParameterSuggestion := false
@@ -849,7 +854,7 @@ func TestRunCommandSuggestions(t *testing.T) {
 
 	// Test parameter suggestion for 'q' prefix
 	doc = createTestDocument("run action-uuid node-uuid q")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 
 	// Should suggest quality parameter (starts with 'q')
 	expectedQualityParam := "quality="
@@ -867,28 +872,28 @@ func TestRunCommandSuggestions(t *testing.T) {
 
 func TestRunCommandNodeSuggestions(t *testing.T) {
 	// Setup enhanced mock client with actions that have proper flags
-	client = &enhancedMockClient{}
+	sh := newTestShell(&enhancedMockClient{})
 
 	// Load cached actions to simulate startup
-	actions, err := client.ListActions()
+	actions, err := sh.client.ListActions()
 	if err != nil {
 		t.Fatalf("Failed to get actions: %v", err)
 	}
-	cachedActions = actions
+	sh.actions = actions
 
 	// Also set up some mock nodes for testing
-	currentNodes = []antbox.Node{
+	sh.currentNodes = []antbox.Node{
 		{UUID: "node-uuid-1", Title: "Test Node 1", Mimetype: "text/plain", CreatedAt: "2024-01-01T12:00:00Z", ModifiedAt: "2024-01-01T12:00:00Z"},
 		{UUID: "node-uuid-2", Title: "Test Node 2", Mimetype: "application/pdf", CreatedAt: "2024-01-01T12:00:00Z", ModifiedAt: "2024-01-01T12:00:00Z"},
 	}
 
 	// Test node suggestion (second argument) - start typing node name
 	doc := createTestDocument("run action-uuid n")
-	suggests := completer(doc)
+	suggests := sh.complete(doc)
 
 	// Should suggest nodes that start with 'n'
 	if len(suggests) == 0 {
-		t.Errorf("Expected node suggestions for 'run action-uuid n', got 0. Available nodes: %v", currentNodes)
+		t.Errorf("Expected node suggestions for 'run action-uuid n', got 0. Available nodes: %v", sh.currentNodes)
 	}
 
 	// Verify we get node suggestions with proper UUIDs
@@ -906,7 +911,7 @@ func TestRunCommandNodeSuggestions(t *testing.T) {
 
 	// Test with action UUID and typing first letter of node
 	doc = createTestDocument("run action-uuid no")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 
 	if len(suggests) == 0 {
 		t.Errorf("Expected node suggestions for 'run action-uuid no', got 0")
@@ -938,38 +943,38 @@ func TestExecCommandRegistration(t *testing.T) {
 func TestRunCommandBehaviorDocumentation(t *testing.T) {
 	// This test documents the current behavior of the run command suggestions
 	// Setup enhanced mock client with actions
-	client = &enhancedMockClient{}
+	sh := newTestShell(&enhancedMockClient{})
 
 	// Load cached actions to simulate startup
-	actions, err := client.ListActions()
+	actions, err := sh.client.ListActions()
 	if err != nil {
 		t.Fatalf("Failed to get actions: %v", err)
 	}
-	cachedActions = actions
+	sh.actions = actions
 
 	// Set up some mock nodes for testing
-	currentNodes = []antbox.Node{
+	sh.currentNodes = []antbox.Node{
 		{UUID: "node-uuid-1", Title: "Test Node 1", Mimetype: "text/plain", CreatedAt: "2024-01-01T12:00:00Z", ModifiedAt: "2024-01-01T12:00:00Z"},
 		{UUID: "node-uuid-2", Title: "Test Node 2", Mimetype: "application/pdf", CreatedAt: "2024-01-01T12:00:00Z", ModifiedAt: "2024-01-01T12:00:00Z"},
 	}
 
 	// Test 1: Action suggestions work when typing partial action name
 	doc := createTestDocument("run act")
-	suggests := completer(doc)
+	suggests := sh.complete(doc)
 	if len(suggests) == 0 {
 		t.Error("Expected action suggestions for 'run act'")
 	}
 
 	// Test 2: Node suggestions work when typing partial node identifier
 	doc = createTestDocument("run action-uuid n")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 	if len(suggests) == 0 {
 		t.Error("Expected node suggestions for 'run action-uuid n'")
 	}
 
 	// Test 3: Parameter suggestions work when typing partial parameter name
 	doc = createTestDocument("run action-uuid node-uuid-1 f")
-	suggests = completer(doc)
+	suggests = sh.complete(doc)
 	if len(suggests) == 0 {
 		t.Error("Expected parameter suggestions for 'run action-uuid node-uuid-1 f'")
 	}
@@ -998,7 +1003,7 @@ func TestNewListCommands(t *testing.T) {
 
 	// Test command descriptions
 	if agentsCmd, exists := commands["agents"]; exists {
-		if agentsCmd.GetDescription() != "List all available agents" {
+		if agentsCmd.GetDescription() != "List and manage agents" {
 			t.Errorf("Unexpected agents description: %s", agentsCmd.GetDescription())
 		}
 	}
@@ -1018,11 +1023,10 @@ func TestNewListCommands(t *testing.T) {
 
 func TestUpdatedCommandOutputs(t *testing.T) {
 	// Test that updated commands have the correct behavior
-	client = &mockClient{}
 
 	// Test agents command doesn't include owner/model in output
 	agentsCmd := &AgentsCommand{}
-	if agentsCmd.GetDescription() != "List all available agents" {
+	if agentsCmd.GetDescription() != "List and manage agents" {
 		t.Error("Agents command description incorrect")
 	}
 
@@ -1040,8 +1044,6 @@ func TestUpdatedCommandOutputs(t *testing.T) {
 }
 
 func TestFindCommandParsing(t *testing.T) {
-	client = &mockClient{}
-
 	// Test the parsing functions directly
 	testCases := []struct {
 		input    string
@@ -1084,8 +1086,6 @@ func TestFindCommandParsing(t *testing.T) {
 }
 
 func TestFindCommandCompleteWorkflow(t *testing.T) {
-	client = &mockClient{}
-
 	// Test complete parsing workflow for complex searches
 	testCases := []struct {
 		name          string
@@ -1169,11 +1169,11 @@ func TestFindCommandCompleteWorkflow(t *testing.T) {
 }
 
 func TestMksmartCommand(t *testing.T) {
-	client = &mockClient{}
+	sh := newTestShell(&mockClient{})
 
 	// Test mksmart command suggestions
 	doc := createTestDocument("mks")
-	suggests := completer(doc)
+	suggests := sh.complete(doc)
 	if len(suggests) != 1 || suggests[0].Text != "mksmart" {
 		t.Errorf("Expected 1 'mksmart' suggestion for 'mks', got %v", suggests)
 	}
@@ -1217,7 +1217,7 @@ func TestMksmartCommand(t *testing.T) {
 			}()
 
 			// This will execute the command through our mock client
-			mksmart(tc.args)
+			mksmart(sh, tc.args)
 		})
 	}
 }
@@ -1367,6 +1367,18 @@ func (c *enhancedMockClient) RagChat(message string, options map[string]any) (an
 	}, nil
 }
 
+func (c *enhancedMockClient) ChatWithAgentStream(agentUUID string, message string, conversationID string, temperature *float64, maxTokens *int, history []map[string]any, chatContext map[string]any, onPart antbox.ChatStreamHandler) (antbox.ChatHistory, error) {
+	return c.ChatWithAgent(agentUUID, message, conversationID, temperature, maxTokens, history)
+}
+
+func (c *enhancedMockClient) AnswerFromAgentStream(agentUUID string, query string, temperature *float64, maxTokens *int, chatContext map[string]any, onPart antbox.ChatStreamHandler) (antbox.ChatHistory, error) {
+	return c.AnswerFromAgent(agentUUID, query, temperature, maxTokens)
+}
+
+func (c *enhancedMockClient) RagChatStream(message string, options map[string]any, onPart antbox.ChatStreamHandler) (antbox.ChatHistory, error) {
+	return c.RagChat(message, options)
+}
+
 // New interface methods
 func (c *enhancedMockClient) CopyNode(uuid, parent, title string) (*antbox.Node, error) {
 	return &antbox.Node{UUID: "copied-uuid", Title: title, Parent: parent}, nil
@@ -1579,15 +1591,12 @@ func (c *enhancedMockClient) GetDoc(uuid string) (string, error) {
 }
 
 func TestSmartfolderCdAndLsBehavior(t *testing.T) {
-	originalClient := client
-	defer func() { client = originalClient }()
-
 	// Test cd to smartfolder - should use evaluate endpoint
 	t.Run("cd to smartfolder uses evaluate", func(t *testing.T) {
 		mockClient := &enhancedMockClient{}
-		client = mockClient
+		sh := newTestShell(mockClient)
 
-		cd([]string{"smartfolder-uuid"})
+		cd(sh, []string{"smartfolder-uuid"})
 
 		if !mockClient.evaluateCalled {
 			t.Errorf("Expected EvaluateNode to be called for smartfolder, but it wasn't")
@@ -1600,9 +1609,9 @@ func TestSmartfolderCdAndLsBehavior(t *testing.T) {
 	// Test cd to regular folder - should use list endpoint
 	t.Run("cd to regular folder uses list", func(t *testing.T) {
 		mockClient := &enhancedMockClient{}
-		client = mockClient
+		sh := newTestShell(mockClient)
 
-		cd([]string{"regular-folder-uuid"})
+		cd(sh, []string{"regular-folder-uuid"})
 
 		if mockClient.evaluateCalled {
 			t.Errorf("Expected EvaluateNode NOT to be called for regular folder, but it was")
@@ -1615,18 +1624,16 @@ func TestSmartfolderCdAndLsBehavior(t *testing.T) {
 	// Test ls with smartfolder as current folder
 	t.Run("ls in smartfolder uses evaluate", func(t *testing.T) {
 		mockClient := &enhancedMockClient{}
-		client = mockClient
+		sh := newTestShell(mockClient)
 
 		// Set current node to smartfolder
-		originalCurrentNode := currentNode
-		currentNode = antbox.Node{
+		sh.currentNode = antbox.Node{
 			UUID:     "smartfolder-uuid",
 			Title:    "Smart Folder",
 			Mimetype: "application/vnd.antbox.smartfolder",
 		}
-		defer func() { currentNode = originalCurrentNode }()
 
-		ls([]string{})
+		ls(sh, []string{})
 
 		if !mockClient.evaluateCalled {
 			t.Errorf("Expected EvaluateNode to be called when ls in smartfolder, but it wasn't")
@@ -1639,18 +1646,16 @@ func TestSmartfolderCdAndLsBehavior(t *testing.T) {
 	// Test ls with regular folder as current folder
 	t.Run("ls in regular folder uses list", func(t *testing.T) {
 		mockClient := &enhancedMockClient{}
-		client = mockClient
+		sh := newTestShell(mockClient)
 
 		// Set current node to regular folder
-		originalCurrentNode := currentNode
-		currentNode = antbox.Node{
+		sh.currentNode = antbox.Node{
 			UUID:     "regular-folder-uuid",
 			Title:    "Regular Folder",
 			Mimetype: "application/vnd.antbox.folder",
 		}
-		defer func() { currentNode = originalCurrentNode }()
 
-		ls([]string{})
+		ls(sh, []string{})
 
 		if mockClient.evaluateCalled {
 			t.Errorf("Expected EvaluateNode NOT to be called when ls in regular folder, but it was")
@@ -1663,9 +1668,9 @@ func TestSmartfolderCdAndLsBehavior(t *testing.T) {
 	// Test ls with explicit smartfolder UUID as argument
 	t.Run("ls with smartfolder UUID uses evaluate", func(t *testing.T) {
 		mockClient := &enhancedMockClient{}
-		client = mockClient
+		sh := newTestShell(mockClient)
 
-		ls([]string{"smartfolder-uuid"})
+		ls(sh, []string{"smartfolder-uuid"})
 
 		if !mockClient.evaluateCalled {
 			t.Errorf("Expected EvaluateNode to be called when ls with smartfolder UUID, but it wasn't")
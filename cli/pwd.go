@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/c-bata/go-prompt"
@@ -17,12 +16,12 @@ func (c *PwdCommand) GetDescription() string {
 	return "Show current location as path"
 }
 
-func (c *PwdCommand) Execute(args []string) {
-	breadcrumbs, err := client.GetBreadcrumbs(currentNode.UUID)
+func (c *PwdCommand) Execute(sh *Shell, args []string) {
+	breadcrumbs, err := sh.client.GetBreadcrumbs(sh.getCurrentNode().UUID)
 	if err != nil {
-		fmt.Println("Error getting breadcrumbs:", err)
+		sh.eprintln("Error getting breadcrumbs:", err)
 		// Fallback to old behavior
-		fmt.Printf("%s  %s\n", currentNode.UUID, getCurrentFolderName())
+		sh.printf("%s  %s\n", sh.getCurrentNode().UUID, sh.getCurrentFolderName())
 		return
 	}
 
@@ -35,13 +34,13 @@ func (c *PwdCommand) Execute(args []string) {
 	}
 
 	if len(pathParts) == 0 {
-		fmt.Println("/")
+		sh.println("/")
 	} else {
-		fmt.Printf("/%s\n", strings.Join(pathParts, "/"))
+		sh.printf("/%s\n", strings.Join(pathParts, "/"))
 	}
 }
 
-func (c *PwdCommand) Suggest(d prompt.Document) []prompt.Suggest {
+func (c *PwdCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
 	return []prompt.Suggest{}
 }
 
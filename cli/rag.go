@@ -3,8 +3,10 @@ package cli
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
 	markdown "go.xrstf.de/go-term-markdown"
 )
 
@@ -19,9 +21,11 @@ func (c *RagCommand) GetDescription() string {
 	return "Send message to RAG agent"
 }
 
-func (c *RagCommand) Execute(args []string) {
+func (c *RagCommand) Execute(sh *Shell, args []string) {
 	var useLocation bool
+	var sessionID string
 	var messageArgs []string
+	trace := false
 
 	// Parse flags and arguments
 	i := 0
@@ -31,6 +35,16 @@ func (c *RagCommand) Execute(args []string) {
 		case "-l":
 			useLocation = true
 			i++
+		case "--trace":
+			trace = true
+			i++
+		case "-c":
+			if i+1 >= len(args) {
+				sh.eprintln("Error: -c requires a session ID")
+				return
+			}
+			sessionID = args[i+1]
+			i += 2
 		default:
 			// Remaining arguments are the message
 			messageArgs = args[i:]
@@ -38,20 +52,33 @@ func (c *RagCommand) Execute(args []string) {
 		}
 	}
 
+	var parent string
+	if useLocation {
+		parent = sh.getCurrentNode().UUID
+	}
+
+	// A resumed session keeps the location it was started with
+	if sessionID != "" && !useLocation {
+		parent, _ = sh.sessions.GetSession(sessionID).Options["parent"].(string)
+	}
+
 	// If no message provided, enter interactive mode
 	if len(messageArgs) == 0 {
-		c.startInteractiveSession(useLocation)
+		if sessionID == "" {
+			sessionID = "rag-" + time.Now().Format("20060102-150405")
+		}
+		c.startInteractiveSession(sh, sessionID, parent, trace)
 		return
 	}
 
-	// Single message mode
+	// Single message mode, kept in a session only when one is given
 	message := strings.Join(messageArgs, " ")
-	c.sendMessage(message, nil, useLocation)
+	c.sendMessage(sh, message, sessionID, parent, trace)
 }
 
-func (c *RagCommand) Suggest(d prompt.Document) []prompt.Suggest {
+func (c *RagCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
 	text := d.TextBeforeCursor()
-	args := strings.Fields(text)
+	args := fieldsBeforeCursor(d)
 
 	if len(args) == 0 {
 		return []prompt.Suggest{}
@@ -60,11 +87,17 @@ func (c *RagCommand) Suggest(d prompt.Document) []prompt.Suggest {
 	lastArg := args[len(args)-1]
 
 	// Suggest flags if we're typing a flag or at the beginning
-	if strings.HasPrefix(lastArg, "-") || (len(args) <= 3 && !strings.HasSuffix(text, " ")) {
+	if strings.HasPrefix(lastArg, "-") || (len(args) <= 3 && !cursorAfterBlank(d)) {
 		var suggestions []prompt.Suggest
 		if !strings.Contains(text, "-l") {
 			suggestions = append(suggestions, prompt.Suggest{Text: "-l", Description: "Use current location as context"})
 		}
+		if !strings.Contains(text, "-c") {
+			suggestions = append(suggestions, prompt.Suggest{Text: "-c", Description: "Continue a saved session"})
+		}
+		if !strings.Contains(text, "--trace") {
+			suggestions = append(suggestions, prompt.Suggest{Text: "--trace", Description: "Show tool calls and reasoning"})
+		}
 		return suggestions
 	}
 
@@ -72,18 +105,28 @@ func (c *RagCommand) Suggest(d prompt.Document) []prompt.Suggest {
 }
 
 // startInteractiveSession starts an interactive RAG session
-func (c *RagCommand) startInteractiveSession(useLocation bool) {
-	fmt.Println("Starting interactive RAG session")
-	if useLocation {
-		fmt.Printf("Using location context: %s\n", getCurrentFolderName())
+func (c *RagCommand) startInteractiveSession(sh *Shell, sessionID string, parent string, trace bool) {
+	session := sh.sessions.GetSession(sessionID)
+
+	sh.println("Starting interactive RAG session")
+	if session.IsEmpty() {
+		sh.printf("Session: %s\n", sessionID)
+	} else {
+		sh.printf("Session: %s (resumed, %d messages)\n", sessionID, len(session.GetHistory()))
 	}
-	fmt.Println("Type 'exit' or press Ctrl+D to exit the session.")
-	fmt.Println()
+	if parent != "" {
+		sh.printf("Using location context: %s\n", ragLocationName(sh, parent))
+	}
+	sh.println("Type 'exit' or press Ctrl+D to exit the session, '/trace' to toggle tracing.")
+	sh.println()
 
 	// Create interactive session context
 	sessionContext := &RagSessionContext{
-		useLocation: useLocation,
-		command:     c,
+		sh:        sh,
+		sessionID: sessionID,
+		parent:    parent,
+		trace:     trace,
+		command:   c,
 	}
 
 	// Create a new prompt for the RAG session
@@ -96,11 +139,26 @@ func (c *RagCommand) startInteractiveSession(useLocation bool) {
 	p.Run()
 }
 
+// ragLocationName returns a display name for the folder used as RAG context
+func ragLocationName(sh *Shell, parent string) string {
+	if sh.getCurrentNode().UUID == parent {
+		return sh.getCurrentFolderName()
+	}
+	return parent
+}
+
+// ragAssistantPrefix returns the prefix of RAG answers, in green on terminals
+func ragAssistantPrefix(sh *Shell) string {
+	return colorize(sh.out, "Assistant:", "\033[32m") + " "
+}
+
 // RagSessionContext holds the context for an interactive RAG session
 type RagSessionContext struct {
-	useLocation bool
-	command     *RagCommand
-	history     []map[string]any
+	sh        *Shell
+	sessionID string
+	parent    string
+	trace     bool
+	command   *RagCommand
 }
 
 func (ctx *RagSessionContext) executeMessage(input string) {
@@ -108,7 +166,7 @@ func (ctx *RagSessionContext) executeMessage(input string) {
 
 	// Check for exit command
 	if input == "exit" {
-		fmt.Println("Exiting RAG session...")
+		ctx.sh.println("Exiting RAG session...")
 		return
 	}
 
@@ -117,96 +175,74 @@ func (ctx *RagSessionContext) executeMessage(input string) {
 		return
 	}
 
-	// Send message and display response
-	history := ctx.command.sendMessage(input, ctx.history, ctx.useLocation)
-
-	if history != nil {
-		ctx.history = history
+	if input == "/trace" {
+		ctx.trace = !ctx.trace
+		if ctx.trace {
+			ctx.sh.println("Trace on.")
+		} else {
+			ctx.sh.println("Trace off.")
+		}
+		return
 	}
+
+	// Send message and display response
+	ctx.command.sendMessage(ctx.sh, input, ctx.sessionID, ctx.parent, ctx.trace)
 }
 
-// sendMessage sends a single message to the RAG agent and displays the response
-func (c *RagCommand) sendMessage(message string, history []map[string]any, useLocation bool) []map[string]any {
+// sendMessage sends a single message to the RAG agent and displays the response.
+// When a session ID is given, the conversation so far is sent along and the session is saved.
+func (c *RagCommand) sendMessage(sh *Shell, message string, sessionID string, parent string, trace bool) {
 	options := make(map[string]any)
 
-	if useLocation {
-		options["parent"] = currentNode.UUID
+	if parent != "" {
+		options["parent"] = parent
 	}
 
-	if history != nil {
-		options["history"] = history
+	var session *Session
+	var previous antbox.ChatHistory
+	if sessionID != "" {
+		session = sh.sessions.GetSession(sessionID)
+		previous = session.GetChatHistory()
+		if len(previous) > 0 {
+			options["history"] = chatHistoryToMaps(previous)
+		}
 	}
 
 	// Show loading animation while waiting for response
 	loadingMessage := "Processing with RAG"
-	if useLocation {
-		loadingMessage = fmt.Sprintf("Processing with RAG (context: %s)", getCurrentFolderName())
+	if parent != "" {
+		loadingMessage = fmt.Sprintf("Processing with RAG (context: %s)", ragLocationName(sh, parent))
 	}
-	animation := StartLoadingAnimationWithStyle(loadingMessage, BarStyle)
-	chatHistory, err := client.RagChat(message, options)
+	animation := sh.startLoadingAnimation(loadingMessage, BarStyle)
+	printer := newStreamPrinter(sh.out, animation, "✓ RAG response:", ragAssistantPrefix(sh), trace)
+	chatHistory, err := sh.client.RagChatStream(message, options, printer.onPart)
 
 	if err != nil {
-		animation.StopWithMessage("✗ Error processing RAG request")
-		fmt.Println("Error:", err)
-		return nil
-	}
-
-	animation.StopWithMessage("✓ RAG response:")
-
-	// Find the last model response from the chat history
-	var responseText string
-	for i := len(chatHistory) - 1; i >= 0; i-- {
-		msg := chatHistory[i]
-		if msg.Role == "model" {
-			for _, part := range msg.Parts {
-				if part.Text != nil {
-					responseText = *part.Text
-					break
-				}
-			}
-			if responseText != "" {
-				break
-			}
-		}
+		printer.fail("✗ Error processing RAG request")
+		sh.eprintln("Error:", err)
+		return
 	}
 
-	if responseText != "" {
-		result := markdown.Render(responseText, 100, 11)
-		fmt.Printf("\033[32mAssistant:\033[0m %s\n", strings.Trim(string(result), " "))
+	merged := mergeChatHistory(previous, chatHistory, message)
+
+	// Tokens are streamed as plain text, the complete answer is rendered as markdown
+	if response := responseText(chatHistory); response != "" {
+		result := markdown.Render(response, 100, 11)
+		printer.finish(ragAssistantPrefix(sh)+strings.Trim(string(result), " "), merged[len(previous):])
 	} else {
-		fmt.Println("\033[32mAssistant:\033[0m (no response)")
+		printer.finish(ragAssistantPrefix(sh)+"(no response)", merged[len(previous):])
 	}
 
-	// Convert ChatHistory to []map[string]any for backward compatibility
-	var convertedHistory []map[string]any
-	for _, msg := range chatHistory {
-		parts := make([]any, len(msg.Parts))
-		for i, part := range msg.Parts {
-			partMap := make(map[string]any)
-			if part.Text != nil {
-				partMap["text"] = *part.Text
-			}
-			if part.ToolCall != nil {
-				partMap["toolCall"] = map[string]any{
-					"name": part.ToolCall.Name,
-					"args": part.ToolCall.Args,
-				}
-			}
-			if part.ToolResponse != nil {
-				partMap["toolResponse"] = map[string]any{
-					"name": part.ToolResponse.Name,
-					"text": part.ToolResponse.Text,
-				}
-			}
-			parts[i] = partMap
+	if session != nil {
+		session.Kind = SessionKindRag
+		if parent != "" {
+			session.Options = map[string]any{"parent": parent}
+		}
+		session.SetChatHistory(merged)
+		if err := sh.sessions.SaveSession(sessionID); err != nil {
+			sh.eprintln("Warning: session not saved:", err)
 		}
-		convertedHistory = append(convertedHistory, map[string]any{
-			"role":  string(msg.Role),
-			"parts": parts,
-		})
 	}
-
-	return convertedHistory
 }
 
 func init() {
@@ -1,8 +1,6 @@
 package cli
 
 import (
-	"fmt"
-
 	"github.com/c-bata/go-prompt"
 )
 
@@ -16,34 +14,35 @@ func (c *ReloadCommand) GetDescription() string {
 	return "Reload cached data from server"
 }
 
-func (c *ReloadCommand) Execute(args []string) {
+func (c *ReloadCommand) Execute(sh *Shell, args []string) {
 	if len(args) > 0 {
-		fmt.Println("Usage: reload")
-		fmt.Println()
-		fmt.Println("Description:")
-		fmt.Println("  Refresh the cached lists of aspects, actions, extensions, and agents")
-		fmt.Println("  from the server. This is useful when new resources have been added")
-		fmt.Println("  or modified on the server since the CLI was started.")
-		fmt.Println()
-		fmt.Println("Example:")
-		fmt.Println("  reload")
+		sh.println("Usage: reload")
+		sh.println()
+		sh.println("Description:")
+		sh.println("  Refresh the cached lists of aspects, actions, extensions, AI tools and agents")
+		sh.println("  from the server and clear the cached nodes and folder listings. This is")
+		sh.println("  useful when resources have been added or modified on the server since")
+		sh.println("  they were loaded.")
+		sh.println()
+		sh.println("Example:")
+		sh.println("  reload")
 		return
 	}
 
-	err := reloadCachedData()
+	err := sh.reloadCachedData()
 	if err != nil {
-		fmt.Printf("Reload completed with warnings: %v\n", err)
+		sh.printf("Reload completed with warnings: %v\n", err)
 		return
 	}
 
-	fmt.Printf("Successfully reloaded all cached data:\n")
-	fmt.Printf("  - %d aspects\n", len(GetCachedAspects()))
-	fmt.Printf("  - %d actions\n", len(GetCachedActions()))
-	fmt.Printf("  - %d extensions\n", len(GetCachedExtensions()))
-	fmt.Printf("  - %d agents\n", len(GetCachedAgents()))
+	sh.printf("Successfully reloaded all cached data:\n")
+	sh.printf("  - %d aspects\n", len(sh.GetCachedAspects()))
+	sh.printf("  - %d actions\n", len(sh.GetCachedActions()))
+	sh.printf("  - %d extensions\n", len(sh.GetCachedExtensions()))
+	sh.printf("  - %d agents\n", len(sh.GetCachedAgents()))
 }
 
-func (c *ReloadCommand) Suggest(d prompt.Document) []prompt.Suggest {
+func (c *ReloadCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
 	return []prompt.Suggest{}
 }
 
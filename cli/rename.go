@@ -1,9 +1,6 @@
 package cli
 
 import (
-	"fmt"
-	"strings"
-
 	"github.com/c-bata/go-prompt"
 )
 
@@ -17,25 +14,25 @@ func (c *RenameCommand) GetDescription() string {
 	return "Change the name of a node"
 }
 
-func (c *RenameCommand) Execute(args []string) {
+func (c *RenameCommand) Execute(sh *Shell, args []string) {
 	if len(args) != 2 {
-		fmt.Println("Usage: rename <uuid> <new-name>")
+		sh.println("Usage: rename <uuid> <new-name>")
 		return
 	}
 
-	err := client.ChangeNodeName(args[0], args[1])
+	err := sh.client.ChangeNodeName(args[0], args[1])
 	if err != nil {
-		fmt.Println("Error:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
 
-	fmt.Printf("Node %s renamed to '%s' successfully\n", args[0], args[1])
+	sh.printf("Node %s renamed to '%s' successfully\n", args[0], args[1])
 }
 
-func (c *RenameCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	args := strings.Split(d.TextBeforeCursor(), " ")
+func (c *RenameCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := argsBeforeCursor(d)
 	if len(args) == 2 {
-		return getNodeSuggestions(d.GetWordBeforeCursor(), nil)
+		return getNodeSuggestions(sh, wordBeforeCursor(d), nil)
 	}
 	return []prompt.Suggest{}
 }
@@ -1,8 +1,6 @@
 package cli
 
 import (
-	"fmt"
-
 	"github.com/c-bata/go-prompt"
 )
 
@@ -16,23 +14,23 @@ func (c *RmCommand) GetDescription() string {
 	return "Remove a node"
 }
 
-func (c *RmCommand) Execute(args []string) {
+func (c *RmCommand) Execute(sh *Shell, args []string) {
 	if len(args) == 0 {
-		fmt.Println("Usage: rm <uuid>")
+		sh.println("Usage: rm <uuid>")
 		return
 	}
 
-	err := client.RemoveNode(args[0])
+	err := sh.client.RemoveNode(args[0])
 	if err != nil {
-		fmt.Println("Error:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
 
-	fmt.Printf("Node %s removed successfully\n", args[0])
+	sh.printf("Node %s removed successfully\n", args[0])
 }
 
-func (c *RmCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	return getNodeSuggestions(d.GetWordBeforeCursor(), nil)
+func (c *RmCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	return getNodeSuggestions(sh, wordBeforeCursor(d), nil)
 }
 
 func init() {
@@ -1,7 +1,11 @@
 package cli
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -16,77 +20,336 @@ func (c *RunCommand) GetName() string {
 }
 
 func (c *RunCommand) GetDescription() string {
-	return "Run an action on a node with optional parameters"
+	return "Run an action on one or more nodes with optional parameters"
 }
 
-func (c *RunCommand) Execute(args []string) {
+// runChunkSize is the maximum number of nodes sent in a single action run request
+const runChunkSize = 50
+
+// findPageSize is the page size used when collecting all the nodes matching a filter
+const findPageSize = 100
+
+// runTarget is a node selected to run an action on
+type runTarget struct {
+	UUID  string
+	Title string
+}
+
+// runOutcome is the result of running an action on a single node
+type runOutcome struct {
+	Target runTarget
+	Result any
+	Err    error
+}
+
+func (c *RunCommand) Execute(sh *Shell, args []string) {
 	if len(args) < 2 {
-		fmt.Println("Usage: run <action_uuid> <node_uuid> [param=value...]")
-		fmt.Println()
-		fmt.Println("Arguments:")
-		fmt.Println("  action_uuid: UUID of the action to run")
-		fmt.Println("  node_uuid: UUID of the node to run the action on")
-		fmt.Println("  param=value: Optional parameters in key=value format")
-		fmt.Println()
-		fmt.Println("Examples:")
-		fmt.Println("  run abc123 def456")
-		fmt.Println("  run abc123 def456 format=pdf quality=high")
+		sh.println("Usage: run <action_uuid> <node_uuid...|--find \"<filter>\"|-> [param=value...]")
+		sh.println()
+		sh.println("Arguments:")
+		sh.println("  action_uuid: UUID of the action to run")
+		sh.println("  node_uuid: One or more UUIDs of the nodes to run the action on")
+		sh.println("  --find \"<filter>\": Run the action on every node matching the filter")
+		sh.println("  -: Run the action on the nodes piped in, or read node UUIDs from stdin")
+		sh.println("     (one or more per line, end with Ctrl+D)")
+		sh.println("  param=value: Parameters in key=value format, converted to the declared type")
+		sh.println()
+		sh.println("Arrays and objects are given as JSON (or a comma separated list for arrays).")
+		sh.println("File parameters accept a local path, which is uploaded first.")
+		sh.println("Missing required parameters are asked for interactively.")
+		sh.println()
+		sh.printf("Nodes that don't match the action filters are reported and skipped.\n")
+		sh.printf("Large selections are sent in chunks of %d nodes.\n", runChunkSize)
+		sh.println()
+		sh.println("Examples:")
+		sh.println("  run abc123 def456")
+		sh.println("  run abc123 def456 ghi789 format=pdf quality=high")
+		sh.println("  run abc123 --find \"mimetype == application/pdf\" format=pdf")
+		sh.println("  find mimetype == application/pdf | where size gt 1000000 | run abc123 -")
+		sh.println("  run abc123 def456 tags=[\"a\",\"b\"] options={\"dpi\":300}")
 		return
 	}
 
 	actionUUID := args[0]
-	nodeUUID := args[1]
-
-	// Parse parameters from remaining arguments
-	parameters := make(map[string]any)
-	for i := 2; i < len(args); i++ {
-		param := args[i]
-		if strings.Contains(param, "=") {
-			parts := strings.SplitN(param, "=", 2)
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-
-			// Try to convert value to appropriate type
-			if value == "true" {
-				parameters[key] = true
-			} else if value == "false" {
-				parameters[key] = false
-			} else {
-				// Try to parse as number, otherwise keep as string
-				if intVal, err := strconv.Atoi(value); err == nil {
-					parameters[key] = intVal
-				} else if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
-					parameters[key] = floatVal
-				} else {
-					parameters[key] = value
-				}
-			}
-		} else {
-			fmt.Printf("Warning: Ignoring invalid parameter format: %s (expected key=value)\n", param)
+
+	uuids, findFilter, fromStdin, paramArgs, err := parseRunArgs(args[1:])
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	// Collect the selected nodes, keeping the metadata when we already have it
+	var targets []runTarget
+	var nodes []antbox.Node
+
+	switch {
+	case fromStdin && sh.input != nil:
+		piped, err := sh.input.nodes()
+		if err != nil {
+			sh.eprintln("Error:", err)
+			return
 		}
+		nodes = append(nodes, piped...)
+	case fromStdin:
+		sh.println("Reading node UUIDs from stdin (Ctrl+D to finish)...")
+		uuids = append(uuids, readUUIDs(sh.in)...)
 	}
 
-	// Create action run request
-	request := antbox.ActionRunRequest{
-		UUIDs:      []string{nodeUUID},
-		Parameters: parameters,
+	if findFilter != "" {
+		found, err := findAllNodes(sh, findFilter)
+		if err != nil {
+			sh.eprintln("Error:", err)
+			return
+		}
+		nodes = append(nodes, found...)
+	}
+
+	for _, uuid := range uuids {
+		targets = append(targets, runTarget{UUID: uuid})
+	}
+	for _, node := range nodes {
+		targets = append(targets, runTarget{UUID: node.UUID, Title: node.Title})
 	}
+	targets = uniqueRunTargets(targets)
 
-	// Execute the action
-	result, err := client.RunAction(actionUUID, request)
+	if len(targets) == 0 {
+		sh.println("No nodes selected.")
+		return
+	}
+
+	// Coerce parameters using the types declared by the action
+	declared := findFeatureParameters(sh, actionUUID, sh.GetCachedActions())
+	parameters, err := resolveParameters(sh, declared, parseParameterArgs(sh, paramArgs))
 	if err != nil {
-		fmt.Println("Error running action:", err)
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	targets, rejected := c.validateRunTargets(sh, actionUUID, targets, nodes)
+	for _, outcome := range rejected {
+		sh.printf("Skipping %s: %v\n", outcome.Target.UUID, outcome.Err)
+	}
+
+	if len(targets) == 0 {
+		sh.println("No nodes left to run the action on.")
+		return
+	}
+
+	// Keep the original output for the common single node case
+	if len(targets) == 1 && len(rejected) == 0 {
+		result, err := sh.client.RunAction(actionUUID, antbox.ActionRunRequest{
+			UUIDs:      []string{targets[0].UUID},
+			Parameters: parameters,
+		})
+		if err != nil {
+			sh.eprintln("Error running action:", err)
+			return
+		}
+
+		sh.println("Action executed successfully:")
+		printResult(sh, result)
 		return
 	}
 
-	// Display the result
-	fmt.Println("Action executed successfully:")
-	printResult(result)
+	outcomes := runActionInChunks(sh, actionUUID, targets, parameters, runChunkSize)
+	printRunOutcomes(sh, outcomes, len(rejected))
+}
+
+// parseRunArgs splits the arguments after the action UUID into node UUIDs, a find filter,
+// the stdin marker and the parameter arguments. Everything from the first key=value on is
+// treated as parameters.
+func parseRunArgs(args []string) (uuids []string, findFilter string, fromStdin bool, paramArgs []string, err error) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--find":
+			if i+1 >= len(args) {
+				return nil, "", false, nil, fmt.Errorf("--find requires a filter")
+			}
+			i++
+			findFilter = args[i]
+		case arg == "-":
+			fromStdin = true
+		case strings.Contains(arg, "="):
+			return uuids, findFilter, fromStdin, args[i:], nil
+		default:
+			uuids = append(uuids, arg)
+		}
+	}
+
+	return uuids, findFilter, fromStdin, nil, nil
+}
+
+// readUUIDs reads whitespace separated UUIDs until EOF, ignoring '#' comment lines
+func readUUIDs(reader *bufio.Reader) []string {
+	var uuids []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		uuids = append(uuids, strings.Fields(line)...)
+	}
+	return uuids
+}
+
+// findAllNodes returns every node matching the filter, walking all the result pages. It stops
+// at a page without new nodes, so a server that ignores the page number can't loop forever.
+func findAllNodes(sh *Shell, filter string) ([]antbox.Node, error) {
+	var nodes []antbox.Node
+	seen := make(map[string]bool)
+	for page := 1; ; page++ {
+		result, err := sh.client.FindNodes(filter, findPageSize, page)
+		if err != nil {
+			return nil, err
+		}
+		added := false
+		for _, node := range result.Nodes {
+			if !seen[node.UUID] {
+				seen[node.UUID] = true
+				nodes = append(nodes, node)
+				added = true
+			}
+		}
+		if !added || len(result.Nodes) < findPageSize {
+			return nodes, nil
+		}
+	}
+}
+
+// uniqueRunTargets removes repeated nodes, keeping the first occurrence and any known title
+func uniqueRunTargets(targets []runTarget) []runTarget {
+	index := make(map[string]int)
+	var unique []runTarget
+	for _, target := range targets {
+		if i, ok := index[target.UUID]; ok {
+			if unique[i].Title == "" {
+				unique[i].Title = target.Title
+			}
+			continue
+		}
+		index[target.UUID] = len(unique)
+		unique = append(unique, target)
+	}
+	return unique
+}
+
+// validateRunTargets checks the selected nodes against the action filters. Nodes without
+// metadata are fetched first. It returns the accepted targets and the rejected ones.
+func (c *RunCommand) validateRunTargets(sh *Shell, actionUUID string, targets []runTarget, known []antbox.Node) ([]runTarget, []runOutcome) {
+	action := findCachedFeature(actionUUID, sh.GetCachedActions())
+	if action == nil {
+		if feature, err := sh.client.GetFeature(actionUUID); err == nil {
+			action = feature
+		}
+	}
+	if action == nil || action.Filters == nil {
+		return targets, nil
+	}
+
+	nodesByUUID := make(map[string]antbox.Node)
+	for _, node := range known {
+		nodesByUUID[node.UUID] = node
+	}
+
+	var accepted []runTarget
+	var rejected []runOutcome
+	for _, target := range targets {
+		node, ok := nodesByUUID[target.UUID]
+		if !ok {
+			fetched, err := sh.client.GetNode(target.UUID)
+			if err != nil {
+				rejected = append(rejected, runOutcome{Target: target, Err: err})
+				continue
+			}
+			node = *fetched
+			target.Title = node.Title
+		}
+
+		if !c.nodeMatchesFilters(node, action.Filters) {
+			rejected = append(rejected, runOutcome{
+				Target: target,
+				Err:    fmt.Errorf("'%s' does not match the filters of action %s", node.Title, action.Name),
+			})
+			continue
+		}
+		accepted = append(accepted, target)
+	}
+
+	return accepted, rejected
+}
+
+// runActionInChunks runs the action on the targets in chunks and returns one outcome per node
+func runActionInChunks(sh *Shell, actionUUID string, targets []runTarget, parameters map[string]any, chunkSize int) []runOutcome {
+	var outcomes []runOutcome
+
+	for start := 0; start < len(targets); start += chunkSize {
+		chunk := targets[start:min(start+chunkSize, len(targets))]
+
+		uuids := make([]string, len(chunk))
+		for i, target := range chunk {
+			uuids[i] = target.UUID
+		}
+
+		if len(targets) > chunkSize {
+			sh.printf("Running on nodes %d-%d of %d...\n", start+1, start+len(chunk), len(targets))
+		}
+
+		result, err := sh.client.RunAction(actionUUID, antbox.ActionRunRequest{
+			UUIDs:      uuids,
+			Parameters: parameters,
+		})
+
+		// Results keyed by node UUID are split per node, anything else applies to the whole chunk
+		keyed := false
+		for _, target := range chunk {
+			outcome := runOutcome{Target: target, Err: err}
+			if value, ok := result[target.UUID]; ok {
+				outcome.Result = value
+				keyed = true
+			}
+			outcomes = append(outcomes, outcome)
+		}
+
+		if err == nil && !keyed && len(result) > 0 {
+			sh.println("Result:")
+			printResult(sh, result)
+		}
+	}
+
+	return outcomes
 }
 
-func (c *RunCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	text := d.TextBeforeCursor()
-	args := strings.Fields(text)
+// printRunOutcomes prints the result for each node followed by a summary
+func printRunOutcomes(sh *Shell, outcomes []runOutcome, skipped int) {
+	succeeded := 0
+	for _, outcome := range outcomes {
+		name := outcome.Target.UUID
+		if outcome.Target.Title != "" {
+			name = fmt.Sprintf("%s (%s)", outcome.Target.UUID, outcome.Target.Title)
+		}
+
+		if outcome.Err != nil {
+			sh.printf(" ✗ %s: %v\n", name, outcome.Err)
+			continue
+		}
+
+		succeeded++
+		if outcome.Result == nil {
+			sh.printf(" ✓ %s\n", name)
+		} else {
+			sh.printf(" ✓ %s: %v\n", name, outcome.Result)
+		}
+	}
+
+	sh.println()
+	sh.printf("%d succeeded, %d failed, %d skipped\n", succeeded, len(outcomes)-succeeded, skipped)
+}
+
+func (c *RunCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := fieldsBeforeCursor(d)
 
 	if len(args) == 0 {
 		return []prompt.Suggest{}
@@ -94,16 +357,16 @@ func (c *RunCommand) Suggest(d prompt.Document) []prompt.Suggest {
 
 	// Count actual arguments (excluding the command name)
 	argCount := len(args) - 1
-	if !strings.HasSuffix(text, " ") && len(args) > 1 {
+	if !cursorAfterBlank(d) && len(args) > 1 {
 		argCount = len(args) - 2 // We're still typing the current argument
 	}
 
 	switch argCount {
 	case 0:
 		// Suggesting action UUID - filter only actions that can be run
-		actions := GetCachedActions()
+		actions := sh.GetCachedActions()
 		var suggests []prompt.Suggest
-		currentWord := d.GetWordBeforeCursor()
+		currentWord := wordBeforeCursor(d)
 		for _, action := range actions {
 			// Only suggest actions that are exposed as actions and can be run manually
 			if !action.ExposeAsAction || !action.RunManually {
@@ -126,21 +389,40 @@ func (c *RunCommand) Suggest(d prompt.Document) []prompt.Suggest {
 	case 1:
 		// Suggesting node UUID - filter based on the selected action's filters
 		actionUUID := args[1] // The action UUID from first argument
-		return c.getFilteredNodeSuggestions(d.GetWordBeforeCursor(), actionUUID)
+		if strings.HasPrefix(wordBeforeCursor(d), "-") {
+			return runFlagSuggestions
+		}
+		return c.getFilteredNodeSuggestions(sh, wordBeforeCursor(d), actionUUID)
 	default:
-		// Suggesting parameters based on the selected action's parameter definitions
-		if len(args) >= 3 {
-			actionUUID := args[1] // The action UUID from first argument
-			return c.getActionParameterSuggestions(d.GetWordBeforeCursor(), actionUUID)
+		actionUUID := args[1] // The action UUID from first argument
+		currentWord := wordBeforeCursor(d)
+
+		// More nodes can be given until the first parameter
+		previous := args[2 : argCount+1]
+		if slices.ContainsFunc(previous, func(arg string) bool { return strings.Contains(arg, "=") }) ||
+			previous[len(previous)-1] == "--find" {
+			return c.getActionParameterSuggestions(sh, currentWord, actionUUID)
 		}
-		return []prompt.Suggest{}
+
+		if strings.HasPrefix(currentWord, "-") {
+			return runFlagSuggestions
+		}
+
+		suggests := c.getActionParameterSuggestions(sh, currentWord, actionUUID)
+		return append(suggests, c.getFilteredNodeSuggestions(sh, currentWord, actionUUID)...)
 	}
 }
 
+// runFlagSuggestions are the node selection flags accepted in place of node UUIDs
+var runFlagSuggestions = []prompt.Suggest{
+	{Text: "--find", Description: "Run on every node matching a filter"},
+	{Text: "-", Description: "Read node UUIDs from stdin"},
+}
+
 // getFilteredNodeSuggestions returns node suggestions filtered by the action's node filters
-func (c *RunCommand) getFilteredNodeSuggestions(word, actionUUID string) []prompt.Suggest {
+func (c *RunCommand) getFilteredNodeSuggestions(sh *Shell, word, actionUUID string) []prompt.Suggest {
 	// Find the action to get its filters
-	actions := GetCachedActions()
+	actions := sh.GetCachedActions()
 	var selectedAction *antbox.Feature
 	for _, action := range actions {
 		if action.UUID == actionUUID {
@@ -151,7 +433,7 @@ func (c *RunCommand) getFilteredNodeSuggestions(word, actionUUID string) []promp
 
 	// If action not found or has no filters, suggest all nodes
 	if selectedAction == nil || selectedAction.Filters == nil {
-		return getNodeSuggestions(word, nil)
+		return getNodeSuggestions(sh, word, nil)
 	}
 
 	// Create a filter function based on the action's filters
@@ -159,118 +441,82 @@ func (c *RunCommand) getFilteredNodeSuggestions(word, actionUUID string) []promp
 		return c.nodeMatchesFilters(node, selectedAction.Filters)
 	}
 
-	return getNodeSuggestions(word, filterFunc)
+	return getNodeSuggestions(sh, word, filterFunc)
 }
 
 // getActionParameterSuggestions returns parameter suggestions based on the action's parameter definitions
-func (c *RunCommand) getActionParameterSuggestions(word, actionUUID string) []prompt.Suggest {
-	currentWord := strings.TrimSpace(word)
-
-	// If we're not typing a parameter (no = sign), suggest parameter names
-	if !strings.Contains(currentWord, "=") {
-		// Find the action to get its parameters
-		actions := GetCachedActions()
-		var selectedAction *antbox.Feature
-		for _, action := range actions {
-			if action.UUID == actionUUID {
-				selectedAction = &action
-				break
-			}
-		}
+func (c *RunCommand) getActionParameterSuggestions(sh *Shell, word, actionUUID string) []prompt.Suggest {
+	action := findCachedFeature(actionUUID, sh.GetCachedActions())
+	if action == nil {
+		return []prompt.Suggest{}
+	}
+	return getParameterSuggestions(word, action.Parameters)
+}
 
-		var suggests []prompt.Suggest
-		if selectedAction != nil && len(selectedAction.Parameters) > 0 {
-			// Suggest actual action parameters
-			for _, param := range selectedAction.Parameters {
-				if strings.HasPrefix(strings.ToLower(param.Name), strings.ToLower(currentWord)) {
-					description := fmt.Sprintf("%s (%s)", param.Description, param.Type)
-					if param.Required {
-						description += " - Required"
-					}
-					if param.DefaultValue != nil {
-						description += fmt.Sprintf(" - Default: %v", param.DefaultValue)
-					}
-					suggests = append(suggests, prompt.Suggest{
-						Text:        param.Name + "=",
-						Description: description,
-					})
-				}
-			}
-		} else {
-			// Fallback to common parameter names if no action found or no parameters defined
-			commonParams := []struct {
-				name        string
-				description string
-			}{
-				{"format", "Output format parameter"},
-				{"quality", "Quality parameter"},
-				{"size", "Size parameter"},
-				{"mode", "Mode parameter"},
-			}
+// nodeMatchesFilters checks if a node matches the given filters. Conditions on fields or
+// with operators that can't be evaluated locally are assumed to match and left for the server
+// to enforce.
+func (c *RunCommand) nodeMatchesFilters(node antbox.Node, filters antbox.NodeFilters) bool {
+	groups, ok := filterGroups(filters)
+	if !ok {
+		// Unknown filter format, allow all nodes
+		return true
+	}
 
-			for _, param := range commonParams {
-				if strings.HasPrefix(strings.ToLower(param.name), strings.ToLower(currentWord)) {
-					suggests = append(suggests, prompt.Suggest{
-						Text:        param.name + "=",
-						Description: param.description,
-					})
-				}
+	// Groups are ORed together, the conditions inside a group are ANDed
+	for _, group := range groups {
+		allMatch := true
+		for _, filter := range group {
+			field, _ := filter[0].(string)
+			operator, _ := filter[1].(string)
+			if !slices.Contains(localFilterFields, strings.ToLower(field)) {
+				continue
+			}
+			if !c.evaluateFilter(node, field, operator, filter[2]) {
+				allMatch = false
+				break
 			}
 		}
-		return suggests
+		if allMatch {
+			return true
+		}
 	}
-
-	// If typing a parameter value (has = sign), could suggest values based on parameter type
-	// For now, return empty suggestions for values
-	return []prompt.Suggest{}
+	return false
 }
 
-// nodeMatchesFilters checks if a node matches the given filters
-func (c *RunCommand) nodeMatchesFilters(node antbox.Node, filters antbox.NodeFilters) bool {
-	// This is a simplified filter matching implementation
-	// In a real implementation, you'd need to properly parse and evaluate the filters
-	// For now, we'll do basic matching on common filter types
-
-	switch f := filters.(type) {
-	case []interface{}:
-		// Handle 1D array of filters (AND logic)
-		for _, filter := range f {
-			if filterArray, ok := filter.([]interface{}); ok && len(filterArray) >= 3 {
-				field, _ := filterArray[0].(string)
-				operator, _ := filterArray[1].(string)
-				value := filterArray[2]
-
-				if !c.evaluateFilter(node, field, operator, value) {
-					return false
-				}
-			}
+// localFilterFields are the node fields evaluateFilter knows how to read
+var localFilterFields = []string{"uuid", "title", "mimetype", "parent", "size", "owner"}
+
+// filterGroups normalizes 1D and 2D node filters into OR groups of [field, operator, value]
+// conditions. It returns false when the filters have an unexpected shape.
+func filterGroups(filters antbox.NodeFilters) ([][][]any, bool) {
+	data, err := json.Marshal(filters)
+	if err != nil {
+		return nil, false
+	}
+
+	// A 2D filter is an array of arrays of conditions
+	var groups [][][]any
+	if err := json.Unmarshal(data, &groups); err != nil {
+		var conditions [][]any
+		if err := json.Unmarshal(data, &conditions); err != nil {
+			return nil, false
 		}
-		return true
-	case [][]interface{}:
-		// Handle 2D array of filters (OR of ANDs)
-		for _, filterGroup := range f {
-			allMatch := true
-			for _, filter := range filterGroup {
-				if filterArray, ok := filter.([]interface{}); ok && len(filterArray) >= 3 {
-					field, _ := filterArray[0].(string)
-					operator, _ := filterArray[1].(string)
-					value := filterArray[2]
-
-					if !c.evaluateFilter(node, field, operator, value) {
-						allMatch = false
-						break
-					}
-				}
-			}
-			if allMatch {
-				return true // At least one group matched completely
+		groups = [][][]any{conditions}
+	}
+	if len(groups) == 0 {
+		return nil, false
+	}
+
+	for _, group := range groups {
+		for _, condition := range group {
+			if len(condition) < 3 {
+				return nil, false
 			}
 		}
-		return false
-	default:
-		// Unknown filter format, allow all nodes
-		return true
 	}
+
+	return groups, true
 }
 
 // evaluateFilter evaluates a single filter condition against a node
@@ -279,8 +525,12 @@ func (c *RunCommand) evaluateFilter(node antbox.Node, field, operator string, va
 
 	// Extract the field value from the node
 	switch strings.ToLower(field) {
+	case "uuid":
+		nodeValue = node.UUID
 	case "title":
 		nodeValue = node.Title
+	case "parent":
+		nodeValue = node.Parent
 	case "mimetype":
 		nodeValue = node.Mimetype
 	case "size":
@@ -298,10 +548,23 @@ func (c *RunCommand) evaluateFilter(node antbox.Node, field, operator string, va
 		return fmt.Sprintf("%v", nodeValue) == fmt.Sprintf("%v", value)
 	case "!=", "ne", "not_equals":
 		return fmt.Sprintf("%v", nodeValue) != fmt.Sprintf("%v", value)
-	case "contains", "match":
+	case "contains":
 		nodeStr := strings.ToLower(fmt.Sprintf("%v", nodeValue))
 		valueStr := strings.ToLower(fmt.Sprintf("%v", value))
 		return strings.Contains(nodeStr, valueStr)
+	case "match", "~=":
+		pattern, err := regexp.Compile("(?i)" + fmt.Sprintf("%v", value))
+		if err != nil {
+			// A pattern Go can't read is left for the server to evaluate
+			return true
+		}
+		return pattern.MatchString(fmt.Sprintf("%v", nodeValue))
+	case "in", "not-in":
+		values, _ := value.([]any)
+		found := slices.ContainsFunc(values, func(v any) bool {
+			return fmt.Sprintf("%v", v) == fmt.Sprintf("%v", nodeValue)
+		})
+		return found == (strings.ToLower(operator) == "in")
 	case "starts_with", "startswith":
 		nodeStr := strings.ToLower(fmt.Sprintf("%v", nodeValue))
 		valueStr := strings.ToLower(fmt.Sprintf("%v", value))
@@ -339,14 +602,14 @@ func (c *RunCommand) evaluateFilter(node antbox.Node, field, operator string, va
 		}
 		return false
 	default:
-		// Unknown operator, assume it doesn't match
-		return false
+		// Operators not evaluated locally, such as contains-any, are left for the server
+		return true
 	}
 }
 
-func printResult(result map[string]any) {
+func printResult(sh *Shell, result map[string]any) {
 	for key, value := range result {
-		fmt.Printf("  %s: %v\n", key, value)
+		sh.printf("  %s: %v\n", key, value)
 	}
 }
 
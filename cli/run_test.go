@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+func TestParseRunArgs(t *testing.T) {
+	args := []string{"node-1", "node-2", "--find", "mimetype == application/pdf", "-", "format=pdf", "extra"}
+
+	uuids, findFilter, fromStdin, paramArgs, err := parseRunArgs(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(uuids, []string{"node-1", "node-2"}) {
+		t.Errorf("Expected two node UUIDs, got %v", uuids)
+	}
+	if findFilter != "mimetype == application/pdf" {
+		t.Errorf("Expected the filter argument, got %q", findFilter)
+	}
+	if !fromStdin {
+		t.Error("Expected '-' to select stdin")
+	}
+	if !reflect.DeepEqual(paramArgs, []string{"format=pdf", "extra"}) {
+		t.Errorf("Expected parameters from the first key=value on, got %v", paramArgs)
+	}
+
+	if _, _, _, _, err := parseRunArgs([]string{"--find"}); err == nil {
+		t.Error("Expected error when --find has no filter")
+	}
+}
+
+func TestUniqueRunTargetsKeepsTitles(t *testing.T) {
+	targets := uniqueRunTargets([]runTarget{
+		{UUID: "a"},
+		{UUID: "b", Title: "B"},
+		{UUID: "a", Title: "A"},
+	})
+
+	expected := []runTarget{{UUID: "a", Title: "A"}, {UUID: "b", Title: "B"}}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("Expected %v, got %v", expected, targets)
+	}
+}
+
+func TestNodeMatchesFilters(t *testing.T) {
+	c := &RunCommand{}
+	pdf := antbox.Node{UUID: "n1", Title: "report.pdf", Mimetype: "application/pdf"}
+
+	testCases := []struct {
+		name     string
+		filters  antbox.NodeFilters
+		expected bool
+	}{
+		{"1D match", []any{[]any{"mimetype", "==", "application/pdf"}}, true},
+		{"1D mismatch", []any{[]any{"mimetype", "==", "text/plain"}}, false},
+		{"typed 1D", antbox.NodeFilters1D{{"title", "contains", "report"}}, true},
+		{"2D any group", []any{
+			[]any{[]any{"mimetype", "==", "text/plain"}},
+			[]any{[]any{"mimetype", "in", []any{"application/pdf", "image/png"}}},
+		}, true},
+		{"unknown field is left to the server", []any{[]any{"aspects", "contains", "invoice"}}, true},
+		{"not-in", []any{[]any{"mimetype", "not-in", []any{"application/pdf"}}}, false},
+		{"match is a regular expression", []any{[]any{"title", "match", `^report\.(pdf|docx)$`}}, true},
+		{"match mismatch", []any{[]any{"title", "~=", `^invoice`}}, false},
+		{"unknown operator is left to the server", []any{[]any{"title", "contains-any", []any{"x"}}}, true},
+	}
+
+	for _, tc := range testCases {
+		if got := c.nodeMatchesFilters(pdf, tc.filters); got != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+// samePageClient returns the same full page whatever page is asked for
+type samePageClient struct {
+	mockClient
+	calls int
+}
+
+func (c *samePageClient) FindNodes(filters string, pageSize, pageToken int) (*antbox.NodeFilterResult, error) {
+	c.calls++
+	nodes := make([]antbox.Node, pageSize)
+	for i := range nodes {
+		nodes[i] = antbox.Node{UUID: fmt.Sprintf("node-%d", i)}
+	}
+	return &antbox.NodeFilterResult{Nodes: nodes}, nil
+}
+
+func TestFindAllNodesStopsWithoutNewNodes(t *testing.T) {
+	client := &samePageClient{}
+	sh := newTestShell(client)
+
+	nodes, err := findAllNodes(sh, "mimetype == application/pdf")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(nodes) != findPageSize || client.calls != 2 {
+		t.Errorf("Expected %d nodes after 2 pages, got %d after %d", findPageSize, len(nodes), client.calls)
+	}
+}
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// validateSchema checks a decoded JSON value against a JSON schema and returns the violations
+// found, each prefixed with the path of the offending value. It supports the keywords agents
+// use for structured answers: type, enum, const, properties, required, additionalProperties,
+// items, min/maxItems, min/maxLength, pattern, minimum, maximum, anyOf, oneOf and allOf.
+func validateSchema(schema map[string]any, value any) []string {
+	var violations []string
+	checkSchema(schema, value, "$", &violations)
+	return violations
+}
+
+func checkSchema(schema map[string]any, value any, path string, violations *[]string) {
+	report := func(format string, args ...any) {
+		*violations = append(*violations, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		actual := jsonType(value)
+		if !slices.Contains(types, actual) && !(actual == "integer" && slices.Contains(types, "number")) {
+			report("expected %s, got %s", strings.Join(types, " or "), actual)
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		if !slices.ContainsFunc(enum, func(allowed any) bool { return reflect.DeepEqual(allowed, value) }) {
+			report("must be one of %s", compactJSON(enum))
+		}
+	}
+	if constant, ok := schema["const"]; ok && !reflect.DeepEqual(constant, value) {
+		report("must be %s", compactJSON(constant))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		checkObject(schema, v, path, violations)
+	case []any:
+		if min, ok := schemaNumber(schema["minItems"]); ok && float64(len(v)) < min {
+			report("must have at least %v items, has %d", min, len(v))
+		}
+		if max, ok := schemaNumber(schema["maxItems"]); ok && float64(len(v)) > max {
+			report("must have at most %v items, has %d", max, len(v))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				checkSchema(items, item, fmt.Sprintf("%s[%d]", path, i), violations)
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if min, ok := schemaNumber(schema["minLength"]); ok && length < min {
+			report("must be at least %v characters long", min)
+		}
+		if max, ok := schemaNumber(schema["maxLength"]); ok && length > max {
+			report("must be at most %v characters long", max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				report("must match %s", pattern)
+			}
+		}
+	case float64:
+		if min, ok := schemaNumber(schema["minimum"]); ok && v < min {
+			report("must be at least %v", min)
+		}
+		if max, ok := schemaNumber(schema["maximum"]); ok && v > max {
+			report("must be at most %v", max)
+		}
+	}
+
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, sub := range all {
+			if subSchema, ok := sub.(map[string]any); ok {
+				checkSchema(subSchema, value, path, violations)
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok && countMatching(anyOf, value) == 0 {
+		report("doesn't match any of the allowed schemas")
+	}
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		if matching := countMatching(oneOf, value); matching != 1 {
+			report("must match exactly one of the allowed schemas, matches %d", matching)
+		}
+	}
+}
+
+func checkObject(schema map[string]any, object map[string]any, path string, violations *[]string) {
+	properties, _ := schema["properties"].(map[string]any)
+
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, present := object[key]; !present {
+					*violations = append(*violations, fmt.Sprintf("%s: missing required property %q", path, key))
+				}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		propertyPath := path + "." + key
+		if propertySchema, ok := properties[key].(map[string]any); ok {
+			checkSchema(propertySchema, object[key], propertyPath, violations)
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*violations = append(*violations, fmt.Sprintf("%s: property is not allowed", propertyPath))
+			}
+		case map[string]any:
+			checkSchema(additional, object[key], propertyPath, violations)
+		}
+	}
+}
+
+// countMatching returns how many of the schemas accept value
+func countMatching(schemas []any, value any) int {
+	matching := 0
+	for _, sub := range schemas {
+		if subSchema, ok := sub.(map[string]any); ok && len(validateSchema(subSchema, value)) == 0 {
+			matching++
+		}
+	}
+	return matching
+}
+
+// schemaTypes returns the types allowed by a "type" keyword, which may be a string or a list
+func schemaTypes(value any) []string {
+	switch t := value.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var types []string
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func schemaNumber(value any) (float64, bool) {
+	number, ok := value.(float64)
+	return number, ok
+}
+
+// jsonType returns the JSON schema type of a decoded JSON value
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func compactJSON(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+type SelectCommand struct{}
+
+func (c *SelectCommand) GetName() string {
+	return "select"
+}
+
+func (c *SelectCommand) GetDescription() string {
+	return "Choose the columns of the piped records"
+}
+
+func (c *SelectCommand) Execute(sh *Shell, args []string) {
+	if len(args) == 0 || sh.input == nil {
+		sh.println("Usage: <command> | select <column>[,<column>...]")
+		sh.println("  Example: ls | select title,size")
+		return
+	}
+
+	recs := sh.input
+	if len(recs.columns) == 0 {
+		return
+	}
+
+	names := splitColumns(strings.Join(args, ","))
+	if _, err := selectColumns(recs.columns, names); err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+	if recs.opts.Format == "json" || recs.opts.Format == "yaml" {
+		sh.eprintf("Error: select can't be used with -o %s\n", recs.opts.Format)
+		return
+	}
+
+	selected := recs.with(recs.items)
+	selected.opts.Columns, selected.opts.Template = names, ""
+	if err := sh.renderRecords(selected); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
+
+func (c *SelectCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	word := wordBeforeCursor(d)
+	// Complete the last of a comma separated list of columns
+	typed, last := "", word
+	if i := strings.LastIndex(word, ","); i >= 0 {
+		typed, last = word[:i+1], word[i+1:]
+	}
+	suggests := columnSuggestions(last)
+	for i := range suggests {
+		suggests[i].Text = typed + suggests[i].Text
+	}
+	return suggests
+}
+
+func init() {
+	RegisterCommand(&SelectCommand{})
+}
@@ -1,7 +1,11 @@
 package cli
 
 import (
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/kindalus/antx/antbox"
 )
 
 // ConversationHistory represents a single message in the conversation
@@ -10,24 +14,37 @@ type ConversationHistory struct {
 	Content any    `json:"content"` // message content
 }
 
+// Session kinds
+const (
+	SessionKindChat = "chat"
+	SessionKindRag  = "rag"
+)
+
 // Session represents a conversation session with history
 type Session struct {
-	ID      string                `json:"id"`
-	History []ConversationHistory `json:"history"`
-	mu      sync.RWMutex          `json:"-"`
+	ID        string                `json:"id"`
+	Kind      string                `json:"kind,omitempty"`
+	AgentUUID string                `json:"agentUuid,omitempty"`
+	Options   map[string]any        `json:"options,omitempty"`
+	History   []ConversationHistory `json:"history"`
+	CreatedAt time.Time             `json:"createdAt"`
+	UpdatedAt time.Time             `json:"updatedAt"`
+	mu        sync.RWMutex          `json:"-"`
 }
 
-// SessionManager manages all active sessions
+// SessionManager manages all active sessions. When a store is set, sessions
+// not in memory are looked up on disk and can be saved with SaveSession.
 type SessionManager struct {
 	sessions map[string]*Session
+	store    *sessionStore
 	mu       sync.RWMutex
 }
 
-var (
-	sessionManager = &SessionManager{
-		sessions: make(map[string]*Session),
-	}
-)
+// newSessionManager returns a manager keeping sessions in memory, and on disk when store
+// isn't nil
+func newSessionManager(store *sessionStore) *SessionManager {
+	return &SessionManager{sessions: make(map[string]*Session), store: store}
+}
 
 // GetSession retrieves or creates a session by ID
 func (sm *SessionManager) GetSession(sessionID string) *Session {
@@ -48,14 +65,53 @@ func (sm *SessionManager) GetSession(sessionID string) *Session {
 		return session
 	}
 
+	if sm.store != nil {
+		if saved, err := sm.store.load(sessionID); err == nil {
+			session = sessionFromSaved(saved)
+			sm.sessions[sessionID] = session
+			return session
+		}
+	}
+
+	now := time.Now()
 	session = &Session{
-		ID:      sessionID,
-		History: []ConversationHistory{},
+		ID:        sessionID,
+		History:   []ConversationHistory{},
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 	sm.sessions[sessionID] = session
 	return session
 }
 
+// ActiveSession returns the session with the given ID when it is in memory, or nil
+func (sm *SessionManager) ActiveSession(sessionID string) *Session {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.sessions[sessionID]
+}
+
+// SaveSession writes the session to disk. It does nothing when the manager has no store.
+func (sm *SessionManager) SaveSession(sessionID string) error {
+	if sm.store == nil {
+		return nil
+	}
+	return sm.store.save(sm.GetSession(sessionID))
+}
+
+// HasSession reports whether a session exists in memory or on disk, without creating it
+func (sm *SessionManager) HasSession(sessionID string) bool {
+	sm.mu.RLock()
+	_, exists := sm.sessions[sessionID]
+	sm.mu.RUnlock()
+
+	if exists || sm.store == nil {
+		return exists
+	}
+	_, err := sm.store.load(sessionID)
+	return err == nil
+}
+
 // AddMessage adds a message to the session history
 func (s *Session) AddMessage(role string, content any) {
 	s.mu.Lock()
@@ -65,6 +121,7 @@ func (s *Session) AddMessage(role string, content any) {
 		Role:    role,
 		Content: content,
 	})
+	s.UpdatedAt = time.Now()
 }
 
 // GetHistory returns a copy of the conversation history
@@ -93,6 +150,43 @@ func (s *Session) GetHistoryAsMap() []map[string]any {
 	return history
 }
 
+// SetChatHistory replaces the session history with the chat history returned by the server.
+// Each message is stored with its role and its parts as content.
+func (s *Session) SetChatHistory(history antbox.ChatHistory) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.History = make([]ConversationHistory, 0, len(history))
+	for _, msg := range history {
+		s.History = append(s.History, ConversationHistory{
+			Role:    string(msg.Role),
+			Content: msg.Parts,
+		})
+	}
+	s.UpdatedAt = time.Now()
+}
+
+// GetChatHistory returns the session history as a chat history. Plain string
+// content is turned into a single text part.
+func (s *Session) GetChatHistory() antbox.ChatHistory {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history := make(antbox.ChatHistory, 0, len(s.History))
+	for _, msg := range s.History {
+		chatMsg := antbox.ChatMessage{Role: antbox.ChatMessageRole(msg.Role)}
+		switch content := msg.Content.(type) {
+		case []antbox.ChatMessagePart:
+			chatMsg.Parts = content
+		case string:
+			text := content
+			chatMsg.Parts = []antbox.ChatMessagePart{{Text: &text}}
+		}
+		history = append(history, chatMsg)
+	}
+	return history
+}
+
 // IsEmpty checks if the session has no history
 func (s *Session) IsEmpty() bool {
 	s.mu.RLock()
@@ -105,13 +199,97 @@ func (s *Session) Clear() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.History = []ConversationHistory{}
+	s.UpdatedAt = time.Now()
+}
+
+// mergeChatHistory returns the full conversation after a chat turn. The server may answer
+// with the whole conversation or with only the messages of the new turn; in the latter case
+// they are appended to the previous history, adding the user message if it's missing.
+func mergeChatHistory(previous, returned antbox.ChatHistory, message string) antbox.ChatHistory {
+	if len(returned) > len(previous) && hasHistoryPrefix(returned, previous) &&
+		returned[len(previous)].Role == antbox.ChatMessageRoleUser {
+		return returned
+	}
+
+	merged := append(antbox.ChatHistory{}, previous...)
+	if len(returned) == 0 || returned[0].Role != antbox.ChatMessageRoleUser {
+		text := message
+		merged = append(merged, antbox.ChatMessage{
+			Role:  antbox.ChatMessageRoleUser,
+			Parts: []antbox.ChatMessagePart{{Text: &text}},
+		})
+	}
+	return append(merged, returned...)
+}
+
+// hasHistoryPrefix reports whether history starts with the messages in prefix
+func hasHistoryPrefix(history, prefix antbox.ChatHistory) bool {
+	if len(history) < len(prefix) {
+		return false
+	}
+	for i, msg := range prefix {
+		if history[i].Role != msg.Role || messageText(history[i]) != messageText(msg) {
+			return false
+		}
+	}
+	return true
+}
+
+// messageText returns the text parts of a message joined together
+func messageText(msg antbox.ChatMessage) string {
+	var texts []string
+	for _, part := range msg.Parts {
+		if part.Text != nil {
+			texts = append(texts, *part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// chatHistoryToMaps converts a chat history to the generic format sent back to the server
+func chatHistoryToMaps(chatHistory antbox.ChatHistory) []map[string]any {
+	var convertedHistory []map[string]any
+	for _, msg := range chatHistory {
+		parts := make([]any, len(msg.Parts))
+		for i, part := range msg.Parts {
+			partMap := make(map[string]any)
+			// Parts the client doesn't know are sent back as they were received
+			for key, raw := range part.Extra {
+				partMap[key] = raw
+			}
+			if part.Text != nil {
+				partMap["text"] = *part.Text
+			}
+			if part.ToolCall != nil {
+				partMap["toolCall"] = map[string]any{
+					"name": part.ToolCall.Name,
+					"args": part.ToolCall.Args,
+				}
+			}
+			if part.ToolResponse != nil {
+				partMap["toolResponse"] = map[string]any{
+					"name": part.ToolResponse.Name,
+					"text": part.ToolResponse.Text,
+				}
+			}
+			parts[i] = partMap
+		}
+		convertedHistory = append(convertedHistory, map[string]any{
+			"role":  string(msg.Role),
+			"parts": parts,
+		})
+	}
+	return convertedHistory
 }
 
-// RemoveSession removes a session from the manager
+// RemoveSession removes a session from the manager and deletes it from disk
 func (sm *SessionManager) RemoveSession(sessionID string) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 	delete(sm.sessions, sessionID)
+	if sm.store != nil {
+		_ = sm.store.delete(sessionID)
+	}
 }
 
 // ListSessions returns all active session IDs
@@ -133,48 +311,28 @@ func (sm *SessionManager) GetSessionCount() int {
 	return len(sm.sessions)
 }
 
-// Global session management functions
-
-// GetOrCreateSession gets or creates a session for the given conversation ID
-func GetOrCreateSession(conversationID string) *Session {
-	return sessionManager.GetSession(conversationID)
+// ClearSession clears the history of a session and saves it
+func (sm *SessionManager) ClearSession(sessionID string) {
+	sm.GetSession(sessionID).Clear()
+	_ = sm.SaveSession(sessionID)
 }
 
-// AddMessageToSession adds a message to the specified session
-func AddMessageToSession(conversationID, role string, content any) {
-	session := sessionManager.GetSession(conversationID)
-	session.AddMessage(role, content)
-}
-
-// GetSessionHistory returns the history for the specified session
-func GetSessionHistory(conversationID string) []map[string]any {
-	session := sessionManager.GetSession(conversationID)
-	return session.GetHistoryAsMap()
-}
-
-// IsSessionEmpty checks if the specified session is empty
-func IsSessionEmpty(conversationID string) bool {
-	session := sessionManager.GetSession(conversationID)
-	return session.IsEmpty()
-}
-
-// ClearSession clears the history for the specified session
-func ClearSession(conversationID string) {
-	session := sessionManager.GetSession(conversationID)
-	session.Clear()
-}
-
-// RemoveSession removes the specified session
-func RemoveSession(conversationID string) {
-	sessionManager.RemoveSession(conversationID)
-}
-
-// GetActiveSessionCount returns the number of active sessions
-func GetActiveSessionCount() int {
-	return sessionManager.GetSessionCount()
+// ListSavedSessions returns the sessions saved on disk, most recently updated first
+func (sm *SessionManager) ListSavedSessions() []*savedSession {
+	if sm.store == nil {
+		return nil
+	}
+	saved, err := sm.store.list()
+	if err != nil {
+		return nil
+	}
+	return saved
 }
 
-// ListActiveSessions returns all active session IDs
-func ListActiveSessions() []string {
-	return sessionManager.ListSessions()
+// ListSavedSessionIDs returns the IDs of the sessions saved on disk without reading them
+func (sm *SessionManager) ListSavedSessionIDs() []string {
+	if sm.store == nil {
+		return nil
+	}
+	return sm.store.ids()
 }
@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+// sessionSearchSnippetRadius is the number of characters shown around a search match
+const sessionSearchSnippetRadius = 40
+
+// renderSession renders a saved session in the given export format (md, json or html)
+func renderSession(saved *savedSession, format string) (string, error) {
+	switch format {
+	case "md", "markdown":
+		return renderSessionMarkdown(saved), nil
+	case "json":
+		data, err := json.MarshalIndent(saved, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	case "html":
+		return renderSessionHTML(saved)
+	default:
+		return "", fmt.Errorf("unknown export format: %s (use md, json or html)", format)
+	}
+}
+
+// renderSessionMarkdown renders a session as a markdown document
+func renderSessionMarkdown(saved *savedSession) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Session %s\n\n", saved.ID)
+	for _, line := range sessionHeaderLines(saved) {
+		fmt.Fprintf(&b, "- %s\n", line)
+	}
+	b.WriteString("\n")
+
+	for _, msg := range saved.History {
+		fmt.Fprintf(&b, "## %s\n\n", strings.ToUpper(string(msg.Role)))
+		for _, part := range msg.Parts {
+			switch {
+			case part.Text != nil:
+				fmt.Fprintf(&b, "%s\n\n", *part.Text)
+			case part.ToolCall != nil || part.ToolResponse != nil:
+				fmt.Fprintf(&b, "```\n%s\n```\n\n", chatPartDetail(part))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+var sessionHTMLTemplate = template.Must(template.New("session").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Session {{.ID}}</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 2em auto; }
+.message { margin: 1em 0; padding: 0.5em 1em; border-left: 4px solid #ccc; }
+.user { border-color: #3b82f6; }
+.model { border-color: #22c55e; }
+.tool { border-color: #f59e0b; }
+.role { font-weight: bold; font-size: 0.8em; }
+pre { white-space: pre-wrap; background: #f5f5f5; padding: 0.5em; }
+</style>
+</head>
+<body>
+<h1>Session {{.ID}}</h1>
+<ul>
+{{range .Header}}<li>{{.}}</li>
+{{end}}</ul>
+{{range .Messages}}<div class="message {{.Role}}">
+<div class="role">{{.Role}}</div>
+{{range .Texts}}<p>{{.}}</p>
+{{end}}{{range .Details}}<pre>{{.}}</pre>
+{{end}}</div>
+{{end}}</body>
+</html>
+`))
+
+// renderSessionHTML renders a session as a standalone HTML page
+func renderSessionHTML(saved *savedSession) (string, error) {
+	type htmlMessage struct {
+		Role    string
+		Texts   []string
+		Details []string
+	}
+
+	data := struct {
+		ID       string
+		Header   []string
+		Messages []htmlMessage
+	}{
+		ID:     saved.ID,
+		Header: sessionHeaderLines(saved),
+	}
+
+	for _, msg := range saved.History {
+		message := htmlMessage{Role: string(msg.Role)}
+		for _, part := range msg.Parts {
+			switch {
+			case part.Text != nil:
+				message.Texts = append(message.Texts, *part.Text)
+			case part.ToolCall != nil || part.ToolResponse != nil:
+				message.Details = append(message.Details, chatPartDetail(part))
+			}
+		}
+		data.Messages = append(data.Messages, message)
+	}
+
+	var b strings.Builder
+	if err := sessionHTMLTemplate.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// sessionHeaderLines describes a session for export headers
+func sessionHeaderLines(saved *savedSession) []string {
+	var lines []string
+	if saved.Kind != "" {
+		lines = append(lines, "Kind: "+saved.Kind)
+	}
+	if saved.AgentUUID != "" {
+		lines = append(lines, "Agent: "+saved.AgentUUID)
+	}
+	for _, key := range slices.Sorted(maps.Keys(saved.Options)) {
+		lines = append(lines, fmt.Sprintf("%s: %v", key, saved.Options[key]))
+	}
+	if !saved.CreatedAt.IsZero() {
+		lines = append(lines, "Created: "+saved.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+	if !saved.UpdatedAt.IsZero() {
+		lines = append(lines, "Updated: "+saved.UpdatedAt.Format("2006-01-02 15:04:05"))
+	}
+	return lines
+}
+
+// chatPartDetail renders a tool call or tool response in full
+func chatPartDetail(part antbox.ChatMessagePart) string {
+	if part.ToolCall != nil {
+		args, _ := json.MarshalIndent(part.ToolCall.Args, "", "  ")
+		return fmt.Sprintf("→ %s(%s)", part.ToolCall.Name, args)
+	}
+	if part.ToolResponse != nil {
+		return fmt.Sprintf("← %s: %s", part.ToolResponse.Name, part.ToolResponse.Text)
+	}
+	return ""
+}
+
+// sessionMatch is a message that matched a session search
+type sessionMatch struct {
+	SessionID string
+	Index     int
+	Role      string
+	Snippet   string
+}
+
+// searchSessionHistory finds the messages containing query (case insensitive),
+// including tool call arguments and tool responses
+func searchSessionHistory(sessions []*savedSession, query string) []sessionMatch {
+	needle := strings.ToLower(query)
+	if needle == "" {
+		return nil
+	}
+
+	var matches []sessionMatch
+	for _, saved := range sessions {
+		for i, msg := range saved.History {
+			for _, part := range msg.Parts {
+				text := chatPartDetail(part)
+				if part.Text != nil {
+					text = *part.Text
+				}
+
+				pos := strings.Index(strings.ToLower(text), needle)
+				if pos < 0 {
+					continue
+				}
+
+				matches = append(matches, sessionMatch{
+					SessionID: saved.ID,
+					Index:     i,
+					Role:      string(msg.Role),
+					Snippet:   searchSnippet(text, pos, len(needle)),
+				})
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// searchSnippet returns the text around a match on a single line
+func searchSnippet(text string, pos, length int) string {
+	start := max(pos-sessionSearchSnippetRadius, 0)
+	end := min(pos+length+sessionSearchSnippetRadius, len(text))
+
+	// Don't cut multi-byte characters in half
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	snippet := strings.Join(strings.Fields(text[start:end]), " ")
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(text) {
+		snippet += "..."
+	}
+	return snippet
+}
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+const (
+	sessionsDirName = ".antx-sessions"
+
+	// Saved sessions not updated within the retention period are pruned at startup,
+	// and only the most recent maxSavedSessions are kept
+	sessionRetention = 30 * 24 * time.Hour
+	maxSavedSessions = 100
+)
+
+// savedSession is the on-disk representation of a session
+type savedSession struct {
+	ID        string             `json:"id"`
+	Kind      string             `json:"kind"`
+	AgentUUID string             `json:"agentUuid,omitempty"`
+	Options   map[string]any     `json:"options,omitempty"`
+	History   antbox.ChatHistory `json:"history"`
+	CreatedAt time.Time          `json:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt"`
+}
+
+// sessionStore persists sessions as one JSON file per session
+type sessionStore struct {
+	dir string
+}
+
+// newSessionStore returns a store in the sessions directory under the config directory
+func newSessionStore() *sessionStore {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return nil
+	}
+	return &sessionStore{dir: filepath.Join(configDir, sessionsDirName)}
+}
+
+// sessionFileName encodes a session ID as a file name. Bytes other than letters, digits,
+// '.', '_' and '-' are written as %XX, so different IDs never share a file.
+func sessionFileName(id string) string {
+	var name strings.Builder
+	for i := 0; i < len(id); i++ {
+		if c := id[i]; isNameChar(c) || c == '.' || c == '-' {
+			name.WriteByte(c)
+		} else {
+			fmt.Fprintf(&name, "%%%02X", c)
+		}
+	}
+	return name.String() + ".json"
+}
+
+// path returns the file used for the given session ID
+func (st *sessionStore) path(id string) string {
+	return filepath.Join(st.dir, sessionFileName(id))
+}
+
+// save writes the session to disk
+func (st *sessionStore) save(session *Session) error {
+	if err := os.MkdirAll(st.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %v", err)
+	}
+
+	saved := session.toSaved()
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated session
+	tmpPath := st.path(saved.ID) + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write session: %v", err)
+	}
+	return os.Rename(tmpPath, st.path(saved.ID))
+}
+
+// load reads a session from disk
+func (st *sessionStore) load(id string) (*savedSession, error) {
+	data, err := os.ReadFile(st.path(id))
+	if err != nil {
+		return nil, err
+	}
+
+	var saved savedSession
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %v", id, err)
+	}
+	return &saved, nil
+}
+
+// list returns every saved session, most recently updated first
+func (st *sessionStore) list() ([]*savedSession, error) {
+	entries, err := os.ReadDir(st.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*savedSession
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(st.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var saved savedSession
+		if err := json.Unmarshal(data, &saved); err != nil {
+			continue
+		}
+		sessions = append(sessions, &saved)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+	return sessions, nil
+}
+
+// ids returns the IDs of the saved sessions, most recently updated first. Only the file names
+// are read, so it is cheap enough for completion.
+func (st *sessionStore) ids() []string {
+	entries, err := os.ReadDir(st.dir)
+	if err != nil {
+		return nil
+	}
+
+	type savedID struct {
+		id      string
+		modTime time.Time
+	}
+	var saved []savedID
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		id, err := url.PathUnescape(name)
+		if err != nil {
+			continue
+		}
+		var modTime time.Time
+		if info, err := entry.Info(); err == nil {
+			modTime = info.ModTime()
+		}
+		saved = append(saved, savedID{id, modTime})
+	}
+
+	sort.SliceStable(saved, func(i, j int) bool {
+		return saved[i].modTime.After(saved[j].modTime)
+	})
+	ids := make([]string, len(saved))
+	for i, s := range saved {
+		ids[i] = s.id
+	}
+	return ids
+}
+
+// delete removes a saved session. Missing sessions are not an error.
+func (st *sessionStore) delete(id string) error {
+	err := os.Remove(st.path(id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// prune removes sessions older than maxAge and all but the newest maxCount sessions.
+// It returns the IDs of the removed sessions.
+func (st *sessionStore) prune(maxAge time.Duration, maxCount int, now time.Time) ([]string, error) {
+	sessions, err := st.list()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for i, saved := range sessions {
+		if i < maxCount && now.Sub(saved.UpdatedAt) <= maxAge {
+			continue
+		}
+		if err := st.delete(saved.ID); err != nil {
+			return removed, err
+		}
+		removed = append(removed, saved.ID)
+	}
+	return removed, nil
+}
+
+// toSaved converts the session to its on-disk representation
+func (s *Session) toSaved() *savedSession {
+	history := s.GetChatHistory()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return &savedSession{
+		ID:        s.ID,
+		Kind:      s.Kind,
+		AgentUUID: s.AgentUUID,
+		Options:   s.Options,
+		History:   history,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+// sessionFromSaved rebuilds a session from its on-disk representation
+func sessionFromSaved(saved *savedSession) *Session {
+	session := &Session{
+		ID:        saved.ID,
+		Kind:      saved.Kind,
+		AgentUUID: saved.AgentUUID,
+		Options:   saved.Options,
+	}
+	session.SetChatHistory(saved.History)
+	session.CreatedAt = saved.CreatedAt
+	session.UpdatedAt = saved.UpdatedAt
+	return session
+}
+
+// pruneSavedSessions applies the retention policy to the saved sessions
+func (sm *SessionManager) pruneSavedSessions() {
+	if sm.store == nil {
+		return
+	}
+	// Pruning is best effort, a failure should never get in the way of starting the CLI
+	_, _ = sm.store.prune(sessionRetention, maxSavedSessions, time.Now())
+}
@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+func textMessage(role antbox.ChatMessageRole, text string) antbox.ChatMessage {
+	return antbox.ChatMessage{Role: role, Parts: []antbox.ChatMessagePart{{Text: &text}}}
+}
+
+func TestSessionStoreRoundTrip(t *testing.T) {
+	store := &sessionStore{dir: t.TempDir()}
+
+	session := &Session{
+		ID:        "chat/with:odd chars",
+		Kind:      SessionKindChat,
+		AgentUUID: "agent-1",
+		Options:   map[string]any{"temperature": 0.5, "maxTokens": 200},
+		CreatedAt: time.Now(),
+	}
+	session.SetChatHistory(antbox.ChatHistory{
+		textMessage(antbox.ChatMessageRoleUser, "Hello"),
+		{
+			Role: antbox.ChatMessageRoleModel,
+			Parts: []antbox.ChatMessagePart{
+				{ToolCall: &antbox.ToolCall{Name: "search", Args: map[string]any{"q": "x"}}},
+			},
+		},
+		textMessage(antbox.ChatMessageRoleModel, "Hi there"),
+	})
+
+	if err := store.save(session); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	saved, err := store.load(session.ID)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	restored := sessionFromSaved(saved)
+	if restored.ID != session.ID || restored.Kind != SessionKindChat || restored.AgentUUID != "agent-1" {
+		t.Errorf("unexpected session metadata: %+v", saved)
+	}
+
+	history := restored.GetChatHistory()
+	if len(history) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(history))
+	}
+	if history[1].Parts[0].ToolCall == nil || history[1].Parts[0].ToolCall.Name != "search" {
+		t.Errorf("tool call not preserved: %+v", history[1])
+	}
+	if messageText(history[2]) != "Hi there" {
+		t.Errorf("expected 'Hi there', got %q", messageText(history[2]))
+	}
+
+	temperature, maxTokens := chatOptionsFromSession(restored.Options)
+	if temperature == nil || *temperature != 0.5 || maxTokens == nil || *maxTokens != 200 {
+		t.Errorf("options not restored: %v", restored.Options)
+	}
+}
+
+func TestSessionStorePrune(t *testing.T) {
+	store := &sessionStore{dir: t.TempDir()}
+	now := time.Now()
+
+	for i, age := range []time.Duration{time.Hour, 2 * time.Hour, 40 * 24 * time.Hour} {
+		session := &Session{ID: string(rune('a' + i))}
+		session.SetChatHistory(antbox.ChatHistory{textMessage(antbox.ChatMessageRoleUser, "hi")})
+		session.UpdatedAt = now.Add(-age)
+		if err := store.save(session); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+	}
+
+	removed, err := store.prune(30*24*time.Hour, 100, now)
+	if err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "c" {
+		t.Errorf("expected [c] to be pruned by age, got %v", removed)
+	}
+
+	removed, err = store.prune(30*24*time.Hour, 1, now)
+	if err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "b" {
+		t.Errorf("expected [b] to be pruned by count, got %v", removed)
+	}
+
+	remaining, _ := store.list()
+	if len(remaining) != 1 || remaining[0].ID != "a" {
+		t.Errorf("expected only 'a' to remain, got %d sessions", len(remaining))
+	}
+}
+
+func TestSessionStoreKeepsSimilarIDsApart(t *testing.T) {
+	store := &sessionStore{dir: t.TempDir()}
+
+	ids := []string{"a b", "a_b", "a%20b", "chat/with:odd chars"}
+	for _, id := range ids {
+		session := &Session{ID: id}
+		session.SetChatHistory(antbox.ChatHistory{textMessage(antbox.ChatMessageRoleUser, id)})
+		if err := store.save(session); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+	}
+
+	for _, id := range ids {
+		saved, err := store.load(id)
+		if err != nil {
+			t.Fatalf("load %q failed: %v", id, err)
+		}
+		if saved.ID != id {
+			t.Errorf("expected session %q, got %q", id, saved.ID)
+		}
+	}
+
+	listed := store.ids()
+	slices.Sort(listed)
+	expected := slices.Clone(ids)
+	slices.Sort(expected)
+	if !slices.Equal(listed, expected) {
+		t.Errorf("expected IDs %v from the file names, got %v", expected, listed)
+	}
+}
+
+func TestSessionManagerLoadsFromStore(t *testing.T) {
+	store := &sessionStore{dir: t.TempDir()}
+
+	writer := &SessionManager{sessions: make(map[string]*Session), store: store}
+	session := writer.GetSession("saved")
+	session.AgentUUID = "agent-1"
+	session.SetChatHistory(antbox.ChatHistory{textMessage(antbox.ChatMessageRoleUser, "remember me")})
+	if err := writer.SaveSession("saved"); err != nil {
+		t.Fatalf("SaveSession failed: %v", err)
+	}
+
+	reader := &SessionManager{sessions: make(map[string]*Session), store: store}
+	if !reader.HasSession("saved") {
+		t.Fatal("expected saved session to be found on disk")
+	}
+	if reader.HasSession("missing") {
+		t.Error("expected missing session not to exist")
+	}
+
+	loaded := reader.GetSession("saved")
+	if loaded.AgentUUID != "agent-1" || len(loaded.GetHistory()) != 1 {
+		t.Errorf("unexpected loaded session: agent %q, %d messages", loaded.AgentUUID, len(loaded.GetHistory()))
+	}
+
+	reader.RemoveSession("saved")
+	if _, err := store.load("saved"); err == nil {
+		t.Error("expected RemoveSession to delete the saved file")
+	}
+}
+
+func TestSearchSessionHistory(t *testing.T) {
+	sessions := []*savedSession{
+		{ID: "one", History: antbox.ChatHistory{
+			textMessage(antbox.ChatMessageRoleUser, "Where is the INVOICE from March?"),
+			textMessage(antbox.ChatMessageRoleModel, "It is in the finance folder."),
+		}},
+		{ID: "two", History: antbox.ChatHistory{
+			{Role: antbox.ChatMessageRoleTool, Parts: []antbox.ChatMessagePart{
+				{ToolResponse: &antbox.ToolResponse{Name: "search", Text: `[{"title":"invoice.pdf"}]`}},
+			}},
+		}},
+	}
+
+	matches := searchSessionHistory(sessions, "invoice")
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d: %+v", len(matches), matches)
+	}
+	if matches[0].SessionID != "one" || matches[0].Index != 0 || !strings.Contains(matches[0].Snippet, "INVOICE") {
+		t.Errorf("unexpected first match: %+v", matches[0])
+	}
+	if matches[1].SessionID != "two" || matches[1].Role != "tool" {
+		t.Errorf("unexpected second match: %+v", matches[1])
+	}
+
+	if len(searchSessionHistory(sessions, "nothing like this")) != 0 {
+		t.Error("expected no matches")
+	}
+}
+
+func TestRenderSession(t *testing.T) {
+	saved := &savedSession{
+		ID:   "export-me",
+		Kind: SessionKindRag,
+		History: antbox.ChatHistory{
+			textMessage(antbox.ChatMessageRoleUser, "<b>question</b>"),
+			textMessage(antbox.ChatMessageRoleModel, "answer"),
+		},
+	}
+
+	md, err := renderSession(saved, "md")
+	if err != nil {
+		t.Fatalf("md export failed: %v", err)
+	}
+	if !strings.Contains(md, "# Session export-me") || !strings.Contains(md, "## MODEL\n\nanswer") {
+		t.Errorf("unexpected markdown:\n%s", md)
+	}
+
+	html, err := renderSession(saved, "html")
+	if err != nil {
+		t.Fatalf("html export failed: %v", err)
+	}
+	if !strings.Contains(html, "&lt;b&gt;question&lt;/b&gt;") {
+		t.Errorf("expected message text to be escaped:\n%s", html)
+	}
+
+	if _, err := renderSession(saved, "pdf"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
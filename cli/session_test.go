@@ -2,6 +2,8 @@ package cli
 
 import (
 	"testing"
+
+	"github.com/kindalus/antx/antbox"
 )
 
 func TestSessionManager(t *testing.T) {
@@ -106,59 +108,56 @@ func TestSessionClear(t *testing.T) {
 }
 
 func TestSessionManagerOperations(t *testing.T) {
-	// Reset session manager for testing
-	originalManager := sessionManager
-	sessionManager = &SessionManager{
-		sessions: make(map[string]*Session),
-	}
-	defer func() {
-		sessionManager = originalManager
-	}()
+	sh := newTestShell(&mockClient{})
 
-	// Test global functions
-	session := GetOrCreateSession("global-test")
+	session := sh.sessions.GetSession("shell-test")
 	if session == nil {
 		t.Fatal("Expected session to be created")
 	}
 
 	// Test adding messages
-	AddMessageToSession("global-test", "user", "Test message")
-	AddMessageToSession("global-test", "assistant", "Test response")
+	session.AddMessage("user", "Test message")
+	session.AddMessage("assistant", "Test response")
 
-	history := GetSessionHistory("global-test")
+	history := sh.sessions.GetSession("shell-test").GetHistoryAsMap()
 	if len(history) != 2 {
 		t.Errorf("Expected 2 messages, got %d", len(history))
 	}
 
 	// Test session count
-	if GetActiveSessionCount() != 1 {
-		t.Errorf("Expected 1 active session, got %d", GetActiveSessionCount())
+	if sh.sessions.GetSessionCount() != 1 {
+		t.Errorf("Expected 1 active session, got %d", sh.sessions.GetSessionCount())
 	}
 
 	// Test list sessions
-	sessions := ListActiveSessions()
-	if len(sessions) != 1 || sessions[0] != "global-test" {
-		t.Errorf("Expected ['global-test'], got %v", sessions)
+	sessions := sh.sessions.ListSessions()
+	if len(sessions) != 1 || sessions[0] != "shell-test" {
+		t.Errorf("Expected ['shell-test'], got %v", sessions)
+	}
+
+	// Sessions belong to their shell
+	if other := newTestShell(&mockClient{}); other.sessions.HasSession("shell-test") {
+		t.Error("Expected another shell not to see the session")
 	}
 
 	// Test clear session
-	if IsSessionEmpty("global-test") {
+	if session.IsEmpty() {
 		t.Error("Expected session to not be empty")
 	}
-	ClearSession("global-test")
-	if !IsSessionEmpty("global-test") {
+	sh.sessions.ClearSession("shell-test")
+	if !session.IsEmpty() {
 		t.Error("Expected session to be empty after clear")
 	}
 
 	// Session should still exist after clear
-	if GetActiveSessionCount() != 1 {
-		t.Errorf("Expected 1 active session after clear, got %d", GetActiveSessionCount())
+	if sh.sessions.GetSessionCount() != 1 {
+		t.Errorf("Expected 1 active session after clear, got %d", sh.sessions.GetSessionCount())
 	}
 
 	// Test remove session
-	RemoveSession("global-test")
-	if GetActiveSessionCount() != 0 {
-		t.Errorf("Expected 0 active sessions after remove, got %d", GetActiveSessionCount())
+	sh.sessions.RemoveSession("shell-test")
+	if sh.sessions.GetSessionCount() != 0 {
+		t.Errorf("Expected 0 active sessions after remove, got %d", sh.sessions.GetSessionCount())
 	}
 }
 
@@ -293,3 +292,66 @@ func TestComplexContentTypes(t *testing.T) {
 		t.Error("Array content length not preserved")
 	}
 }
+
+func TestSessionChatHistoryRoundTrip(t *testing.T) {
+	session := &Session{ID: "chat"}
+
+	question := "Hello"
+	answer := "Hi there!"
+	history := antbox.ChatHistory{
+		{Role: antbox.ChatMessageRoleUser, Parts: []antbox.ChatMessagePart{{Text: &question}}},
+		{Role: antbox.ChatMessageRoleModel, Parts: []antbox.ChatMessagePart{{Text: &answer}}},
+	}
+
+	session.SetChatHistory(history)
+	if len(session.GetHistory()) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(session.GetHistory()))
+	}
+
+	restored := session.GetChatHistory()
+	if len(restored) != 2 || restored[1].Role != antbox.ChatMessageRoleModel || messageText(restored[1]) != answer {
+		t.Errorf("Chat history not restored correctly: %+v", restored)
+	}
+
+	// Plain string content is turned into a text part
+	session.AddMessage("user", "Another question")
+	restored = session.GetChatHistory()
+	if messageText(restored[2]) != "Another question" {
+		t.Errorf("Expected string content as a text part, got %+v", restored[2])
+	}
+}
+
+func TestMergeChatHistory(t *testing.T) {
+	text := func(role antbox.ChatMessageRole, s string) antbox.ChatMessage {
+		return antbox.ChatMessage{Role: role, Parts: []antbox.ChatMessagePart{{Text: &s}}}
+	}
+
+	previous := antbox.ChatHistory{
+		text(antbox.ChatMessageRoleUser, "first"),
+		text(antbox.ChatMessageRoleModel, "first answer"),
+	}
+
+	// Server returns the whole conversation
+	full := append(append(antbox.ChatHistory{}, previous...),
+		text(antbox.ChatMessageRoleUser, "second"),
+		text(antbox.ChatMessageRoleModel, "second answer"))
+	if merged := mergeChatHistory(previous, full, "second"); len(merged) != 4 {
+		t.Errorf("Expected the full history to be used as is, got %d messages", len(merged))
+	}
+
+	// Server returns only the new turn
+	turn := antbox.ChatHistory{
+		text(antbox.ChatMessageRoleUser, "second"),
+		text(antbox.ChatMessageRoleModel, "second answer"),
+	}
+	if merged := mergeChatHistory(previous, turn, "second"); len(merged) != 4 || messageText(merged[2]) != "second" {
+		t.Errorf("Expected the new turn to be appended, got %+v", merged)
+	}
+
+	// Server returns only the answer
+	answer := antbox.ChatHistory{text(antbox.ChatMessageRoleModel, "second answer")}
+	merged := mergeChatHistory(previous, answer, "second")
+	if len(merged) != 4 || merged[2].Role != antbox.ChatMessageRoleUser || messageText(merged[2]) != "second" {
+		t.Errorf("Expected the user message to be added, got %+v", merged)
+	}
+}
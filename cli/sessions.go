@@ -2,9 +2,15 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
 )
 
 type SessionsCommand struct{}
@@ -17,188 +23,367 @@ func (c *SessionsCommand) GetDescription() string {
 	return "Manage conversation sessions"
 }
 
-func (c *SessionsCommand) Execute(args []string) {
+func (c *SessionsCommand) Execute(sh *Shell, args []string) {
 	if len(args) == 0 {
-		c.showUsage()
+		c.showUsage(sh)
 		return
 	}
 
 	subcommand := args[0]
 	switch subcommand {
 	case "list":
-		c.listSessions()
+		c.listSessions(sh)
 	case "clear":
 		if len(args) < 2 {
-			fmt.Println("Usage: sessions clear <session_id>")
-			fmt.Println("       sessions clear all")
+			sh.println("Usage: sessions clear <session_id>")
+			sh.println("       sessions clear all")
 			return
 		}
 		sessionID := args[1]
 		if sessionID == "all" {
-			c.clearAllSessions()
+			c.clearAllSessions(sh)
 		} else {
-			c.clearSession(sessionID)
+			c.clearSession(sh, sessionID)
 		}
 	case "show":
 		if len(args) < 2 {
-			fmt.Println("Usage: sessions show <session_id>")
+			sh.println("Usage: sessions show <session_id>")
 			return
 		}
-		c.showSession(args[1])
+		c.showSession(sh, args[1])
 	case "remove":
 		if len(args) < 2 {
-			fmt.Println("Usage: sessions remove <session_id>")
-			fmt.Println("       sessions remove all")
+			sh.println("Usage: sessions remove <session_id>")
+			sh.println("       sessions remove all")
 			return
 		}
 		sessionID := args[1]
 		if sessionID == "all" {
-			c.removeAllSessions()
+			c.removeAllSessions(sh)
 		} else {
-			c.removeSession(sessionID)
+			c.removeSession(sh, sessionID)
 		}
+	case "resume":
+		if len(args) < 2 {
+			sh.println("Usage: sessions resume <session_id>")
+			return
+		}
+		c.resumeSession(sh, args[1])
+	case "export":
+		c.exportSession(sh, args[1:])
+	case "search":
+		if len(args) < 2 {
+			sh.println("Usage: sessions search <text>")
+			return
+		}
+		c.searchSessions(sh, strings.Join(args[1:], " "))
+	case "prune":
+		c.pruneSessions(sh, args[1:])
 	default:
-		fmt.Printf("Unknown subcommand: %s\n", subcommand)
-		c.showUsage()
+		sh.printf("Unknown subcommand: %s\n", subcommand)
+		c.showUsage(sh)
 	}
 }
 
-func (c *SessionsCommand) showUsage() {
-	fmt.Println("Usage: sessions <subcommand> [args]")
-	fmt.Println()
-	fmt.Println("Subcommands:")
-	fmt.Println("  list                    List all active sessions")
-	fmt.Println("  show <session_id>       Show conversation history for a session")
-	fmt.Println("  clear <session_id>      Clear history for a session (keeps session active)")
-	fmt.Println("  clear all               Clear history for all sessions")
-	fmt.Println("  remove <session_id>     Remove a session completely")
-	fmt.Println("  remove all              Remove all sessions")
-	fmt.Println()
-	fmt.Println("Examples:")
-	fmt.Println("  sessions list")
-	fmt.Println("  sessions show my-chat")
-	fmt.Println("  sessions clear my-chat")
-	fmt.Println("  sessions remove old-session")
+func (c *SessionsCommand) showUsage(sh *Shell) {
+	sh.println("Usage: sessions <subcommand> [args]")
+	sh.println()
+	sh.println("Subcommands:")
+	sh.println("  list                                        List active and saved sessions")
+	sh.println("  show <session_id>                           Show conversation history for a session")
+	sh.println("  resume <session_id>                         Continue a chat or RAG session")
+	sh.println("  export <session_id> [--format f] [file]     Export a session as md, json or html")
+	sh.println("  search <text>                               Search all saved conversations")
+	sh.println("  prune [--days N] [--keep N]                 Remove old saved sessions")
+	sh.println("  clear <session_id>                          Clear history for a session (keeps session active)")
+	sh.println("  clear all                                   Clear history for all sessions")
+	sh.println("  remove <session_id>                         Remove a session completely")
+	sh.println("  remove all                                  Remove all sessions")
+	sh.println()
+	sh.printf("Sessions are saved in ~/%s. Sessions not updated for %d days are pruned\n", sessionsDirName, int(sessionRetention.Hours()/24))
+	sh.printf("at startup, and only the %d most recent are kept.\n", maxSavedSessions)
+	sh.println()
+	sh.println("Examples:")
+	sh.println("  sessions list")
+	sh.println("  sessions show my-chat")
+	sh.println("  sessions resume my-chat")
+	sh.println("  sessions export my-chat --format md my-chat.md")
+	sh.println("  sessions search invoice")
+	sh.println("  sessions prune --days 7")
+	sh.println("  sessions clear my-chat")
+	sh.println("  sessions remove old-session")
 }
 
-func (c *SessionsCommand) listSessions() {
-	sessions := ListActiveSessions()
-	count := GetActiveSessionCount()
+func (c *SessionsCommand) listSessions(sh *Shell) {
+	sessionIDs := allSessionIDs(sh)
 
-	if count == 0 {
-		fmt.Println("No active sessions.")
+	if len(sessionIDs) == 0 {
+		sh.println("No sessions.")
 		return
 	}
 
-	fmt.Printf("Active sessions (%d):\n", count)
-	for _, sessionID := range sessions {
-		session := GetOrCreateSession(sessionID)
+	sh.printf("Sessions (%d):\n", len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		session := sh.sessions.GetSession(sessionID)
 		historyCount := len(session.GetHistory())
-		fmt.Printf("  %s (%d messages)\n", sessionID, historyCount)
+		sh.printf("  %s (%d messages)%s\n", sessionID, historyCount, sessionSummary(session))
 	}
 }
 
-func (c *SessionsCommand) showSession(sessionID string) {
-	session := GetOrCreateSession(sessionID)
+// sessionSummary describes the kind, agent and last update of a session
+func sessionSummary(session *Session) string {
+	var details []string
+	if session.Kind != "" {
+		details = append(details, session.Kind)
+	}
+	if session.AgentUUID != "" {
+		details = append(details, "agent "+session.AgentUUID)
+	}
+	if !session.UpdatedAt.IsZero() {
+		details = append(details, "updated "+session.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return " - " + strings.Join(details, ", ")
+}
+
+// allSessionIDs returns the active sessions followed by the saved sessions not yet loaded
+func allSessionIDs(sh *Shell) []string {
+	sessionIDs := sh.sessions.ListSessions()
+	sort.Strings(sessionIDs)
+	for _, id := range sh.sessions.ListSavedSessionIDs() {
+		if !slices.Contains(sessionIDs, id) {
+			sessionIDs = append(sessionIDs, id)
+		}
+	}
+	return sessionIDs
+}
+
+func (c *SessionsCommand) showSession(sh *Shell, sessionID string) {
+	session := sh.sessions.GetSession(sessionID)
 	history := session.GetHistory()
 
 	if len(history) == 0 {
-		fmt.Printf("Session '%s' has no conversation history.\n", sessionID)
+		sh.printf("Session '%s' has no conversation history.\n", sessionID)
 		return
 	}
 
-	fmt.Printf("Conversation history for session '%s':\n", sessionID)
-	fmt.Println(strings.Repeat("-", 50))
+	sh.printf("Conversation history for session '%s'%s:\n", sessionID, sessionSummary(session))
+	sh.println(strings.Repeat("-", 50))
 
 	for i, msg := range history {
-		fmt.Printf("[%d] %s: ", i+1, strings.ToUpper(msg.Role))
+		sh.printf("[%d] %s: ", i+1, strings.ToUpper(msg.Role))
 
 		switch content := msg.Content.(type) {
 		case string:
 			// Truncate long messages for display
 			if len(content) > 200 {
-				fmt.Printf("%s...\n", content[:200])
+				sh.printf("%s...\n", content[:200])
 			} else {
-				fmt.Println(content)
+				sh.println(content)
+			}
+		case []antbox.ChatMessagePart:
+			for j, part := range content {
+				if j > 0 {
+					sh.print("    ")
+				}
+				sh.println(formatChatPart(part))
 			}
 		default:
-			fmt.Printf("%v\n", content)
+			sh.printf("%v\n", content)
 		}
 
 		if i < len(history)-1 {
-			fmt.Println()
+			sh.println()
 		}
 	}
 }
 
-func (c *SessionsCommand) clearSession(sessionID string) {
+func (c *SessionsCommand) clearSession(sh *Shell, sessionID string) {
 	// Check if session exists and has content
-	session := GetOrCreateSession(sessionID)
+	session := sh.sessions.GetSession(sessionID)
 	if session.IsEmpty() {
-		fmt.Printf("Session '%s' is already empty.\n", sessionID)
+		sh.printf("Session '%s' is already empty.\n", sessionID)
 		return
 	}
 
-	ClearSession(sessionID)
-	fmt.Printf("Cleared conversation history for session '%s'.\n", sessionID)
+	sh.sessions.ClearSession(sessionID)
+	sh.printf("Cleared conversation history for session '%s'.\n", sessionID)
 }
 
-func (c *SessionsCommand) clearAllSessions() {
-	sessions := ListActiveSessions()
+func (c *SessionsCommand) clearAllSessions(sh *Shell) {
+	sessions := allSessionIDs(sh)
 	if len(sessions) == 0 {
-		fmt.Println("No active sessions to clear.")
+		sh.println("No active sessions to clear.")
 		return
 	}
 
 	clearedCount := 0
 	for _, sessionID := range sessions {
-		session := GetOrCreateSession(sessionID)
+		session := sh.sessions.GetSession(sessionID)
 		if !session.IsEmpty() {
-			ClearSession(sessionID)
+			sh.sessions.ClearSession(sessionID)
 			clearedCount++
 		}
 	}
 
-	fmt.Printf("Cleared conversation history for %d session(s).\n", clearedCount)
+	sh.printf("Cleared conversation history for %d session(s).\n", clearedCount)
 }
 
-func (c *SessionsCommand) removeSession(sessionID string) {
-	sessions := ListActiveSessions()
-	found := false
-	for _, id := range sessions {
-		if id == sessionID {
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		fmt.Printf("Session '%s' not found.\n", sessionID)
+func (c *SessionsCommand) removeSession(sh *Shell, sessionID string) {
+	if !sh.sessions.HasSession(sessionID) {
+		sh.printf("Session '%s' not found.\n", sessionID)
 		return
 	}
 
-	RemoveSession(sessionID)
-	fmt.Printf("Removed session '%s'.\n", sessionID)
+	sh.sessions.RemoveSession(sessionID)
+	sh.printf("Removed session '%s'.\n", sessionID)
 }
 
-func (c *SessionsCommand) removeAllSessions() {
-	sessions := ListActiveSessions()
+func (c *SessionsCommand) removeAllSessions(sh *Shell) {
+	sessions := allSessionIDs(sh)
 	if len(sessions) == 0 {
-		fmt.Println("No active sessions to remove.")
+		sh.println("No active sessions to remove.")
 		return
 	}
 
 	count := len(sessions)
 	for _, sessionID := range sessions {
-		RemoveSession(sessionID)
+		sh.sessions.RemoveSession(sessionID)
+	}
+
+	sh.printf("Removed %d session(s).\n", count)
+}
+
+func (c *SessionsCommand) resumeSession(sh *Shell, sessionID string) {
+	if !sh.sessions.HasSession(sessionID) {
+		sh.printf("Session '%s' not found.\n", sessionID)
+		return
 	}
 
-	fmt.Printf("Removed %d session(s).\n", count)
+	session := sh.sessions.GetSession(sessionID)
+	switch session.Kind {
+	case SessionKindRag:
+		parent, _ := session.Options["parent"].(string)
+		(&RagCommand{}).startInteractiveSession(sh, sessionID, parent, false)
+	default:
+		if session.AgentUUID == "" {
+			sh.eprintf("Error: session '%s' has no agent, use 'chat <agent_uuid> -c %s'\n", sessionID, sessionID)
+			return
+		}
+		temperature, maxTokens := chatOptionsFromSession(session.Options)
+		(&ChatCommand{}).startInteractiveSession(sh, session.AgentUUID, sessionID, "", temperature, maxTokens, nil, false)
+	}
 }
 
-func (c *SessionsCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	text := d.TextBeforeCursor()
-	args := strings.Fields(text)
+func (c *SessionsCommand) exportSession(sh *Shell, args []string) {
+	format := "md"
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--format" {
+			if i+1 >= len(args) {
+				sh.eprintln("Error: --format requires md, json or html")
+				return
+			}
+			format = args[i+1]
+			i++
+			continue
+		}
+		positional = append(positional, args[i])
+	}
+
+	if len(positional) == 0 {
+		sh.println("Usage: sessions export <session_id> [--format md|json|html] [file]")
+		return
+	}
+
+	sessionID := positional[0]
+	if !sh.sessions.HasSession(sessionID) {
+		sh.printf("Session '%s' not found.\n", sessionID)
+		return
+	}
+
+	output, err := renderSession(sh.sessions.GetSession(sessionID).toSaved(), format)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	if len(positional) < 2 {
+		sh.print(output)
+		return
+	}
+
+	path := positional[1]
+	if err := os.WriteFile(path, []byte(output), 0644); err != nil {
+		sh.eprintln("Error writing file:", err)
+		return
+	}
+	sh.printf("Session '%s' exported to %s\n", sessionID, path)
+}
+
+func (c *SessionsCommand) searchSessions(sh *Shell, query string) {
+	var sessions []*savedSession
+	for _, sessionID := range allSessionIDs(sh) {
+		sessions = append(sessions, sh.sessions.GetSession(sessionID).toSaved())
+	}
+
+	matches := searchSessionHistory(sessions, query)
+	if len(matches) == 0 {
+		sh.printf("No messages matching '%s'.\n", query)
+		return
+	}
+
+	sh.printf("Found %d matching message(s):\n", len(matches))
+	for _, match := range matches {
+		sh.printf("  %s [%d] %s: %s\n", match.SessionID, match.Index+1, strings.ToUpper(match.Role), match.Snippet)
+	}
+}
+
+func (c *SessionsCommand) pruneSessions(sh *Shell, args []string) {
+	if sh.sessions.store == nil {
+		sh.eprintln("Error: sessions are not being saved")
+		return
+	}
+
+	maxAge := sessionRetention
+	maxCount := maxSavedSessions
+	for i := 0; i < len(args); i++ {
+		if i+1 >= len(args) {
+			sh.eprintf("Error: %s requires a number\n", args[i])
+			return
+		}
+		value, err := strconv.Atoi(args[i+1])
+		if err != nil || value < 0 {
+			sh.eprintf("Error: invalid value for %s: %s\n", args[i], args[i+1])
+			return
+		}
+		switch args[i] {
+		case "--days":
+			maxAge = time.Duration(value) * 24 * time.Hour
+		case "--keep":
+			maxCount = value
+		default:
+			sh.eprintf("Error: unknown option %s\n", args[i])
+			return
+		}
+		i++
+	}
+
+	removed, err := sh.sessions.store.prune(maxAge, maxCount, time.Now())
+	for _, sessionID := range removed {
+		sh.sessions.RemoveSession(sessionID)
+	}
+	if err != nil {
+		sh.eprintln("Error:", err)
+	}
+
+	sh.printf("Removed %d saved session(s).\n", len(removed))
+}
+
+func (c *SessionsCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := fieldsBeforeCursor(d)
 
 	if len(args) == 0 {
 		return []prompt.Suggest{}
@@ -206,17 +391,21 @@ func (c *SessionsCommand) Suggest(d prompt.Document) []prompt.Suggest {
 
 	// Count actual arguments (excluding the command name)
 	argCount := len(args) - 1
-	if !strings.HasSuffix(text, " ") && len(args) > 1 {
+	if !cursorAfterBlank(d) && len(args) > 1 {
 		argCount = len(args) - 2 // We're still typing the current argument
 	}
 
 	switch argCount {
 	case 0:
 		// Suggesting subcommands
-		currentWord := d.GetWordBeforeCursor()
+		currentWord := wordBeforeCursor(d)
 		subcommands := []prompt.Suggest{
-			{Text: "list", Description: "List all active sessions"},
+			{Text: "list", Description: "List active and saved sessions"},
 			{Text: "show", Description: "Show conversation history for a session"},
+			{Text: "resume", Description: "Continue a chat or RAG session"},
+			{Text: "export", Description: "Export a session as md, json or html"},
+			{Text: "search", Description: "Search all saved conversations"},
+			{Text: "prune", Description: "Remove old saved sessions"},
 			{Text: "clear", Description: "Clear history for a session"},
 			{Text: "remove", Description: "Remove a session completely"},
 		}
@@ -233,8 +422,8 @@ func (c *SessionsCommand) Suggest(d prompt.Document) []prompt.Suggest {
 		// Suggesting session IDs or 'all' for applicable commands
 		if len(args) >= 2 {
 			subcommand := args[1]
-			if subcommand == "show" || subcommand == "clear" || subcommand == "remove" {
-				currentWord := d.GetWordBeforeCursor()
+			if slices.Contains([]string{"show", "resume", "export", "clear", "remove"}, subcommand) {
+				currentWord := wordBeforeCursor(d)
 				var suggests []prompt.Suggest
 
 				// Add 'all' option for clear and remove commands
@@ -247,15 +436,16 @@ func (c *SessionsCommand) Suggest(d prompt.Document) []prompt.Suggest {
 					}
 				}
 
-				// Add active session IDs
-				sessions := ListActiveSessions()
-				for _, sessionID := range sessions {
+				// Add active and saved session IDs, without loading the saved ones
+				for _, sessionID := range allSessionIDs(sh) {
 					if strings.HasPrefix(strings.ToLower(sessionID), strings.ToLower(currentWord)) {
-						session := GetOrCreateSession(sessionID)
-						historyCount := len(session.GetHistory())
+						description := "Saved session"
+						if session := sh.sessions.ActiveSession(sessionID); session != nil {
+							description = fmt.Sprintf("%d messages", len(session.GetHistory()))
+						}
 						suggests = append(suggests, prompt.Suggest{
 							Text:        sessionID,
-							Description: fmt.Sprintf("%d messages", historyCount),
+							Description: description,
 						})
 					}
 				}
@@ -264,6 +454,28 @@ func (c *SessionsCommand) Suggest(d prompt.Document) []prompt.Suggest {
 		}
 	}
 
+	// Flags for export and prune
+	if len(args) >= 2 && strings.HasPrefix(wordBeforeCursor(d), "-") {
+		switch args[1] {
+		case "export":
+			return prompt.FilterHasPrefix([]prompt.Suggest{
+				{Text: "--format", Description: "md, json or html"},
+			}, wordBeforeCursor(d), true)
+		case "prune":
+			return prompt.FilterHasPrefix([]prompt.Suggest{
+				{Text: "--days", Description: "Remove sessions not updated for N days"},
+				{Text: "--keep", Description: "Keep only the N most recent sessions"},
+			}, wordBeforeCursor(d), true)
+		}
+	}
+	if len(args) >= 3 && args[1] == "export" && args[len(args)-1] == "--format" && cursorAfterBlank(d) {
+		return []prompt.Suggest{
+			{Text: "md", Description: "Markdown"},
+			{Text: "json", Description: "JSON"},
+			{Text: "html", Description: "HTML"},
+		}
+	}
+
 	return []prompt.Suggest{}
 }
 
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+type SetCommand struct{}
+
+func (c *SetCommand) GetName() string {
+	return "set"
+}
+
+func (c *SetCommand) GetDescription() string {
+	return "Show or set shell variables"
+}
+
+// variableName matches the names of the variables that can be set
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (c *SetCommand) Execute(sh *Shell, args []string) {
+	if len(args) == 0 {
+		c.showVariables(sh)
+		return
+	}
+
+	if args[0] == "-d" {
+		if len(args) != 2 {
+			sh.println("Usage: set -d <name>")
+			return
+		}
+		delete(sh.variables, args[1])
+		return
+	}
+
+	name, value, ok := strings.Cut(strings.Join(args, " "), "=")
+	if !ok {
+		c.showUsage(sh)
+		return
+	}
+	if !variableName.MatchString(name) || name == "_" {
+		sh.eprintf("Error: invalid variable name: %s\n", name)
+		return
+	}
+	sh.variables[name] = value
+}
+
+func (c *SetCommand) showUsage(sh *Shell) {
+	sh.println("Usage: set [name=value|-d name]")
+	sh.println("  Variables are used as $name or ${name}, also inside double quotes.")
+	sh.println("  $_ is the node last created or selected (mkdir, mksmart, upload, cp, duplicate, stat)")
+	sh.println("  and $1 to $9 are the rows of the last listing (${10} for the tenth). $(command) is")
+	sh.println("  replaced by the UUIDs the command lists, or by what it prints. References that")
+	sh.println("  aren't set, and numbers such as $100, are kept as written.")
+	sh.println("  Example: set reports=$(find mimetype == application/pdf)")
+	sh.println("  Example: mkdir \"Q3 Report\", then cd $_")
+}
+
+func (c *SetCommand) showVariables(sh *Shell) {
+	for _, name := range slices.Sorted(maps.Keys(sh.variables)) {
+		sh.printf("%s=%s\n", name, sh.variables[name])
+	}
+	if sh.lastNode != "" {
+		sh.printf("_=%s\n", sh.lastNode)
+	}
+	if len(sh.rows) > 0 {
+		sh.printf("$1..$%d: rows of the last listing\n", len(sh.rows))
+	}
+	if len(sh.variables) == 0 && sh.lastNode == "" && len(sh.rows) == 0 {
+		sh.println("No variables set. Use set name=value to set one.")
+	}
+}
+
+func (c *SetCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := argsBeforeCursor(d)
+	if len(args) != 2 {
+		return []prompt.Suggest{}
+	}
+	var suggests []prompt.Suggest
+	for _, name := range slices.Sorted(maps.Keys(sh.variables)) {
+		suggests = append(suggests, prompt.Suggest{Text: name + "=", Description: sh.variables[name]})
+	}
+	return prompt.FilterHasPrefix(suggests, args[1], false)
+}
+
+func init() {
+	RegisterCommand(&SetCommand{})
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetVariables(t *testing.T) {
+	client := &folderRecorder{}
+	var out strings.Builder
+	sh := NewShell(client, strings.NewReader(""), &out, &out)
+
+	sh.Execute(`set name="Q3  Report"`)
+	sh.Execute(`mkdir "$name"`)
+	sh.Execute(`mkdir $name`)
+	if strings.Join(client.titles, "|") != "Q3  Report|Q3 Report" {
+		t.Errorf("unexpected titles: %q", client.titles)
+	}
+
+	// mkdir makes the new folder $_
+	sh.Execute("set last=$_")
+	if sh.variables["last"] != "new-uuid" {
+		t.Errorf("Expected $_ to be the created folder, got %q", sh.variables["last"])
+	}
+
+	// Undefined variables are kept as written
+	sh.Execute("set -d name")
+	sh.Execute(`mkdir "$name costs $100"`)
+	if len(client.titles) != 3 || client.titles[2] != "$name costs $100" {
+		t.Errorf("Expected the undefined variable to be kept, got %q", client.titles)
+	}
+
+	out.Reset()
+	sh.Execute("set 1x=a")
+	if !strings.Contains(out.String(), "Error: invalid variable name: 1x") {
+		t.Errorf("Expected an invalid name error, got %q", out.String())
+	}
+}
+
+func TestListingReferences(t *testing.T) {
+	var out strings.Builder
+	sh := NewShell(&listingClient{}, strings.NewReader(""), &out, &out)
+
+	// $1, $2... are the rows as listed, after the filters
+	sh.Execute("ls | sort size")
+	sh.Execute("set smallest=$1")
+	sh.Execute("set largest=$3")
+	if sh.variables["smallest"] != "uuid-notes" || sh.variables["largest"] != "uuid-scan" {
+		t.Errorf("unexpected rows: %v", sh.variables)
+	}
+
+	sh.Execute("set missing=$4")
+	if sh.variables["missing"] != "$4" {
+		t.Errorf("Expected a missing row to be kept as written, got %q", sh.variables["missing"])
+	}
+
+	// $(...) is replaced by the UUIDs listed
+	sh.Execute(`set pdfs="$(ls | where mimetype ~= pdf | sort title)"`)
+	if sh.variables["pdfs"] != "uuid-report\nuuid-scan" {
+		t.Errorf("unexpected substitution: %q", sh.variables["pdfs"])
+	}
+	sh.Execute("set where=$(pwd)")
+	if sh.variables["where"] == "" {
+		t.Error("Expected the output of pwd")
+	}
+}
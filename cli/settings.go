@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// setting is a user preference kept in the config file
+type setting struct {
+	Description string
+	Values      []string // allowed values, any value when empty
+	Validate    func(value string) error
+	Apply       func(sh *Shell) // called after the setting changes
+}
+
+// knownSettings are the settings that can be changed with 'config set'
+var knownSettings = map[string]setting{
+	"output":        {Description: "Default output format of listings", Values: outputFormats},
+	"columns":       {Description: "Default columns of ls and find, e.g. uuid,title,owner", Validate: validateNodeColumns},
+	"full-uuid":     {Description: "Show full UUIDs in tables", Values: []string{"true", "false"}},
+	"cache-ttl":     {Description: "How long nodes and listings are cached, e.g. 30s (0 disables the cache)", Validate: validateCacheTTL, Apply: (*Shell).configureCache},
+	"cache-persist": {Description: "Keep the cache between runs", Values: []string{"true", "false"}, Apply: (*Shell).configureCache},
+}
+
+// getSetting returns the value of a setting, or "" when it isn't set
+func (sh *Shell) getSetting(name string) string {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.settings[name]
+}
+
+// setSetting changes a setting, or resets it to its default when value is ""
+func (sh *Shell) setSetting(name, value string) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if value == "" {
+		delete(sh.settings, name)
+		return
+	}
+	sh.settings[name] = value
+}
+
+// settingsSnapshot returns a copy of the settings, for saving them
+func (sh *Shell) settingsSnapshot() map[string]string {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return maps.Clone(sh.settings)
+}
+
+// restoreSettings replaces the settings with the ones loaded from the config file,
+// returning the errors of the unknown settings and invalid values it ignored
+func (sh *Shell) restoreSettings(settings map[string]string) []error {
+	valid := make(map[string]string, len(settings))
+	var ignored []error
+	for name, value := range settings {
+		if err := validateSetting(name, value); err != nil {
+			ignored = append(ignored, err)
+			continue
+		}
+		valid[name] = value
+	}
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.settings = valid
+	return ignored
+}
+
+func validateSetting(name, value string) error {
+	known, ok := knownSettings[name]
+	if !ok {
+		return fmt.Errorf("unknown setting: %s", name)
+	}
+	if len(known.Values) > 0 && !slices.Contains(known.Values, value) {
+		return fmt.Errorf("invalid value for %s: %s (use %s)", name, value, strings.Join(known.Values, ", "))
+	}
+	if known.Validate != nil {
+		if err := known.Validate(value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// applySetting makes a changed setting take effect
+func applySetting(sh *Shell, name string) {
+	if known, ok := knownSettings[name]; ok && known.Apply != nil {
+		known.Apply(sh)
+	}
+}
+
+type ConfigCommand struct{}
+
+func (c *ConfigCommand) GetName() string {
+	return "config"
+}
+
+func (c *ConfigCommand) GetDescription() string {
+	return "Show or change settings"
+}
+
+func (c *ConfigCommand) Execute(sh *Shell, args []string) {
+	if len(args) == 0 {
+		c.showSettings(sh)
+		return
+	}
+
+	switch args[0] {
+	case "set":
+		if len(args) < 3 {
+			sh.println("Usage: config set <name> <value>")
+			return
+		}
+		name, value := args[1], strings.Join(args[2:], " ")
+		if err := validateSetting(name, value); err != nil {
+			sh.eprintln("Error:", err)
+			return
+		}
+		sh.setSetting(name, value)
+		applySetting(sh, name)
+		sh.saveCurrentState()
+		sh.printf("%s set to %s\n", name, value)
+	case "unset":
+		if len(args) != 2 {
+			sh.println("Usage: config unset <name>")
+			return
+		}
+		sh.setSetting(args[1], "")
+		applySetting(sh, args[1])
+		sh.saveCurrentState()
+		sh.printf("%s reset to its default\n", args[1])
+	case "help", "-h":
+		c.showUsage(sh)
+	default:
+		sh.printf("Unknown subcommand: %s\n", args[0])
+		c.showUsage(sh)
+	}
+}
+
+func (c *ConfigCommand) showUsage(sh *Shell) {
+	sh.println("Usage: config [set <name> <value>|unset <name>]")
+	sh.println()
+	sh.println("Settings:")
+	for _, name := range slices.Sorted(maps.Keys(knownSettings)) {
+		known := knownSettings[name]
+		values := ""
+		if len(known.Values) > 0 {
+			values = fmt.Sprintf(" (%s)", strings.Join(known.Values, ", "))
+		}
+		sh.printf("  %-14s %s%s\n", name, known.Description, values)
+	}
+}
+
+func (c *ConfigCommand) showSettings(sh *Shell) {
+	for _, name := range slices.Sorted(maps.Keys(knownSettings)) {
+		value := sh.getSetting(name)
+		if value == "" {
+			value = "(default)"
+		}
+		sh.printf("%-14s %s\n", name, value)
+	}
+}
+
+func (c *ConfigCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := argsBeforeCursor(d)
+	word := wordBeforeCursor(d)
+
+	switch len(args) {
+	case 2:
+		return prompt.FilterHasPrefix([]prompt.Suggest{
+			{Text: "set", Description: "Change a setting"},
+			{Text: "unset", Description: "Reset a setting to its default"},
+		}, word, true)
+	case 3:
+		if args[1] != "set" && args[1] != "unset" {
+			return []prompt.Suggest{}
+		}
+		var suggests []prompt.Suggest
+		for _, name := range slices.Sorted(maps.Keys(knownSettings)) {
+			suggests = append(suggests, prompt.Suggest{Text: name, Description: knownSettings[name].Description})
+		}
+		return prompt.FilterHasPrefix(suggests, word, true)
+	case 4:
+		if args[1] != "set" {
+			return []prompt.Suggest{}
+		}
+		var suggests []prompt.Suggest
+		for _, value := range knownSettings[args[2]].Values {
+			suggests = append(suggests, prompt.Suggest{Text: value})
+		}
+		return prompt.FilterHasPrefix(suggests, word, true)
+	}
+	return []prompt.Suggest{}
+}
+
+func init() {
+	RegisterCommand(&ConfigCommand{})
+}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+// Shell is an antx session: the server it talks to, the folder it is in, the resources loaded
+// from the server and the streams it reads from and writes to. Commands run in a shell, so
+// several independent shells can live in one program.
+//
+//	sh := cli.NewShell(antbox.NewClient(url, apiKey, "", "", false), os.Stdin, &out, os.Stderr)
+//	sh.Execute("ls -o json")
+type Shell struct {
+	client antbox.Antbox
+	// cache is client when it caches nodes and listings, nil otherwise
+	cache *antbox.CachedClient
+	// server is the server the cached nodes come from, and identity who fetched them
+	server   string
+	identity string
+	// persistent shells keep their location, settings and history in the config file
+	persistent bool
+
+	in  *bufio.Reader
+	out io.Writer
+	// errOut gets errors and warnings, so they aren't piped or redirected with the output
+	errOut io.Writer
+
+	prefetcher prefetcher
+
+	// sessions are the chat and RAG conversations, saved on disk by persistent shells
+	sessions *SessionManager
+
+	// Pipelines: the records piped into the command running, and whether the records it lists
+	// go to the next command (into piped) instead of being printed
+	input  *records
+	piping bool
+	piped  *records
+
+	// Variables: the ones set with set, the last node created or selected ($_) and the UUIDs
+	// of the rows of the last listing ($1, $2...)
+	variables map[string]string
+	lastNode  string
+	rows      []string
+
+	// mu guards the fields below, which background work such as saving the state reads
+	// while commands change them
+	mu           sync.RWMutex
+	currentNode  antbox.Node
+	currentNodes []antbox.Node
+	history      []string
+	settings     map[string]string
+
+	// Resources loaded from the server at startup and on reload
+	aspects    []antbox.Aspect
+	actions    []antbox.Feature
+	extensions []antbox.Feature
+	tools      []antbox.Feature
+	agents     []antbox.Agent
+}
+
+// NewShell returns a shell at the root folder that talks to client, reads answers from in,
+// writes to out and reports errors to errOut. Nodes and listings are cached when client is an
+// *antbox.CachedClient.
+func NewShell(client antbox.Antbox, in io.Reader, out, errOut io.Writer) *Shell {
+	sh := &Shell{
+		client:      client,
+		variables:   map[string]string{},
+		settings:    map[string]string{},
+		sessions:    newSessionManager(nil),
+		in:          bufio.NewReader(in),
+		out:         out,
+		errOut:      errOut,
+		currentNode: rootNode(),
+	}
+	if cache, ok := client.(*antbox.CachedClient); ok {
+		sh.cache = cache
+	}
+	return sh
+}
+
+// rootNode returns the root folder
+func rootNode() antbox.Node {
+	return antbox.Node{
+		UUID:     "--root--",
+		Title:    "root",
+		Mimetype: "application/vnd.antbox.folder",
+	}
+}
+
+func (sh *Shell) print(a ...any) {
+	fmt.Fprint(sh.out, a...)
+}
+
+func (sh *Shell) println(a ...any) {
+	fmt.Fprintln(sh.out, a...)
+}
+
+func (sh *Shell) printf(format string, a ...any) {
+	fmt.Fprintf(sh.out, format, a...)
+}
+
+// eprintln prints an error or a warning
+func (sh *Shell) eprintln(a ...any) {
+	fmt.Fprintln(sh.errOut, a...)
+}
+
+// eprintf prints an error or a warning
+func (sh *Shell) eprintf(format string, a ...any) {
+	fmt.Fprintf(sh.errOut, format, a...)
+}
+
+// getCurrentNode returns the folder the shell is in
+func (sh *Shell) getCurrentNode() antbox.Node {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.currentNode
+}
+
+// setCurrentNode changes the folder the shell is in. Prefetching for the folder left behind
+// is cancelled.
+func (sh *Shell) setCurrentNode(node antbox.Node) {
+	sh.mu.Lock()
+	sh.currentNode = node
+	sh.mu.Unlock()
+	sh.prefetcher.stop()
+}
+
+// getCurrentNodes returns the nodes of the last listing, used for completion
+func (sh *Shell) getCurrentNodes() []antbox.Node {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.currentNodes
+}
+
+// setCurrentNodes changes the nodes of the last listing
+func (sh *Shell) setCurrentNodes(nodes []antbox.Node) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.currentNodes = nodes
+}
+
+// setLastNode makes a node created or selected by a command the value of $_
+func (sh *Shell) setLastNode(uuid string) {
+	sh.lastNode = uuid
+}
+
+// GetCachedAspects returns the aspects loaded from the server
+func (sh *Shell) GetCachedAspects() []antbox.Aspect {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.aspects
+}
+
+// GetCachedActions returns the actions loaded from the server
+func (sh *Shell) GetCachedActions() []antbox.Feature {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.actions
+}
+
+// GetCachedExtensions returns the extensions loaded from the server
+func (sh *Shell) GetCachedExtensions() []antbox.Feature {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.extensions
+}
+
+// GetCachedTools returns the AI tools loaded from the server
+func (sh *Shell) GetCachedTools() []antbox.Feature {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.tools
+}
+
+// GetCachedAgents returns the agents loaded from the server
+func (sh *Shell) GetCachedAgents() []antbox.Agent {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.agents
+}
+
+// updateResources changes the loaded resources while holding the lock
+func (sh *Shell) updateResources(update func()) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	update()
+}
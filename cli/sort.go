@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"slices"
+
+	"github.com/c-bata/go-prompt"
+)
+
+type SortCommand struct{}
+
+func (c *SortCommand) GetName() string {
+	return "sort"
+}
+
+func (c *SortCommand) GetDescription() string {
+	return "Sort the piped records by a column"
+}
+
+func (c *SortCommand) Execute(sh *Shell, args []string) {
+	reverse := len(args) > 0 && args[0] == "-r"
+	if reverse {
+		args = args[1:]
+	}
+	if len(args) != 1 || sh.input == nil {
+		sh.println("Usage: <command> | sort [-r] <column>")
+		sh.println("  Numbers are sorted as numbers, everything else as text; -r reverses the order.")
+		sh.println("  Example: ls | sort -r size | head 5")
+		return
+	}
+
+	recs := sh.input
+	if len(recs.columns) == 0 {
+		return
+	}
+
+	column, err := recs.column(args[0])
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	sorted := slices.Clone(recs.items)
+	slices.SortStableFunc(sorted, func(a, b any) int {
+		if reverse {
+			a, b = b, a
+		}
+		return compareValues(column.Value(a), column.Value(b))
+	})
+
+	if err := sh.renderRecords(recs.with(sorted)); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
+
+func (c *SortCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	word := wordBeforeCursor(d)
+	if word == "-" {
+		return []prompt.Suggest{{Text: "-r", Description: "Reverse the order"}}
+	}
+	return columnSuggestions(word)
+}
+
+func init() {
+	RegisterCommand(&SortCommand{})
+}
@@ -1,10 +1,10 @@
 package cli
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
 )
 
 type StatCommand struct{}
@@ -17,51 +17,65 @@ func (c *StatCommand) GetDescription() string {
 	return "Show node properties"
 }
 
-func (c *StatCommand) Execute(args []string) {
+func (c *StatCommand) Execute(sh *Shell, args []string) {
+	format, args, err := parseOutputFlag(sh, args)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
 	if len(args) == 0 {
-		fmt.Println("Usage: stat <uuid>")
+		sh.println("Usage: stat [-o table|json|yaml|csv] <uuid>")
 		return
 	}
 
-	node, err := client.GetNode(args[0])
+	node, err := sh.client.GetNode(args[0])
 	if err != nil {
-		fmt.Println("Error:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
+	sh.setLastNode(node.UUID)
+
+	if err := renderValue(sh, format, node, func(bool) { printNodeProperties(sh, node) }); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
 
+// printNodeProperties prints the properties of a node, one per line
+func printNodeProperties(sh *Shell, node *antbox.Node) {
 	template := "%-11s: %s\n"
 
-	fmt.Printf(template, "UUID", node.UUID)
-	fmt.Printf(template, "Title", node.Title)
-	fmt.Printf(template, "Mimetype", node.Mimetype)
-	fmt.Printf(template, "Parent", node.Parent)
-	fmt.Printf(template, "Owner", node.Owner)
+	sh.printf(template, "UUID", node.UUID)
+	sh.printf(template, "Title", node.Title)
+	sh.printf(template, "Mimetype", node.Mimetype)
+	sh.printf(template, "Parent", node.Parent)
+	sh.printf(template, "Owner", node.Owner)
 	if node.Group != "" {
-		fmt.Printf(template, "Group", node.Group)
+		sh.printf(template, "Group", node.Group)
 	}
 
 	// Show permissions if not a folder
 	if strings.HasSuffix(node.Mimetype, "folder") {
 
-		fmt.Printf(template, "Permissions", "")
+		sh.printf(template, "Permissions", "")
 		if len(node.Permissions.Group) > 0 {
-			fmt.Printf("  %-9s: %s\n", "Group", strings.Join(node.Permissions.Group, ", "))
+			sh.printf("  %-9s: %s\n", "Group", strings.Join(node.Permissions.Group, ", "))
 		}
 		if len(node.Permissions.Authenticated) > 0 {
-			fmt.Printf("  %-9s: %s\n", "Auth", strings.Join(node.Permissions.Authenticated, ", "))
+			sh.printf("  %-9s: %s\n", "Auth", strings.Join(node.Permissions.Authenticated, ", "))
 		}
 		if len(node.Permissions.Anonymous) > 0 {
-			fmt.Printf("  %-9s: %s\n", "Anonymous", strings.Join(node.Permissions.Anonymous, ", "))
+			sh.printf("  %-9s: %s\n", "Anonymous", strings.Join(node.Permissions.Anonymous, ", "))
 		}
 	}
 
-	fmt.Printf(template, "Size", node.HumanReadableSize())
-	fmt.Printf(template, "Created at", node.CreatedAt)
-	fmt.Printf(template, "Modified at", node.ModifiedAt)
+	sh.printf(template, "Size", node.HumanReadableSize())
+	sh.printf(template, "Created at", node.CreatedAt)
+	sh.printf(template, "Modified at", node.ModifiedAt)
 }
 
-func (c *StatCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	return getNodeSuggestions(d.GetWordBeforeCursor(), nil)
+func (c *StatCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	return getNodeSuggestions(sh, wordBeforeCursor(d), nil)
 }
 
 func init() {
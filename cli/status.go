@@ -1,9 +1,11 @@
 package cli
 
 import (
-	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
 )
 
 type StatusCommand struct{}
@@ -16,110 +18,231 @@ func (c *StatusCommand) GetDescription() string {
 	return "Show cached data statistics"
 }
 
-func (c *StatusCommand) Execute(args []string) {
+func (c *StatusCommand) Execute(sh *Shell, args []string) {
+	format, args, err := parseOutputFlag(sh, args)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
 	if len(args) > 0 {
-		fmt.Println("Usage: status")
-		fmt.Println()
-		fmt.Println("Description:")
-		fmt.Println("  Display statistics about cached resources loaded at startup.")
-		fmt.Println("  Shows the number of aspects, actions, extensions, and agents")
-		fmt.Println("  currently available for auto-completion suggestions.")
-		fmt.Println()
-		fmt.Println("Example:")
-		fmt.Println("  status")
+		sh.println("Usage: status [-o table|json|yaml|csv]")
+		sh.println()
+		sh.println("Description:")
+		sh.println("  Display statistics about cached resources loaded at startup.")
+		sh.println("  Shows the number of aspects, actions, extensions, AI tools and agents")
+		sh.println("  currently available for auto-completion suggestions, and how well the")
+		sh.println("  cache of nodes and folder listings is working.")
+		sh.println()
+		sh.println("Example:")
+		sh.println("  status")
 		return
 	}
 
-	aspects := GetCachedAspects()
-	actions := GetCachedActions()
-	extensions := GetCachedExtensions()
-	agents := GetCachedAgents()
+	report := collectStatus(sh)
+	if err := renderValue(sh, format, report, func(bool) { printStatus(sh, report) }); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
+
+// statusReport is the information shown by the status command
+type statusReport struct {
+	Location struct {
+		UUID   string `json:"uuid"`
+		Title  string `json:"title"`
+		Parent string `json:"parent,omitempty"`
+		Nodes  int    `json:"nodes"`
+	} `json:"location"`
+	Cache struct {
+		Aspects    int `json:"aspects"`
+		Actions    int `json:"actions"`
+		Extensions int `json:"extensions"`
+		Tools      int `json:"tools"`
+		Agents     int `json:"agents"`
+	} `json:"cache"`
+	NodeCache *statusNodeCache `json:"sh.cache,omitempty"`
+	Config    struct {
+		File       string            `json:"file"`
+		Exists     bool              `json:"exists"`
+		Error      string            `json:"error,omitempty"`
+		History    int               `json:"history"`
+		MaxHistory int               `json:"maxHistory"`
+		Settings   map[string]string `json:"settings,omitempty"`
+	} `json:"config"`
+	Sessions struct {
+		Active   int             `json:"active"`
+		Saved    int             `json:"saved"`
+		Messages int             `json:"messages"`
+		Recent   []statusSession `json:"recent,omitempty"`
+	} `json:"sessions"`
+}
+
+// statusNodeCache is the state of the cache of nodes and folder listings
+type statusNodeCache struct {
+	TTL       string `json:"ttl"`
+	Persisted bool   `json:"persisted"`
+	antbox.CacheStats
+}
+
+// statusSession is one of the recent sessions of a status report
+type statusSession struct {
+	ID       string `json:"id"`
+	Messages int    `json:"messages"`
+}
+
+func (r statusReport) totalCached() int {
+	return r.Cache.Aspects + r.Cache.Actions + r.Cache.Extensions + r.Cache.Tools + r.Cache.Agents
+}
 
-	fmt.Println("Current Location:")
-	fmt.Println("========================================")
-	fmt.Printf("  Current node: %s (%s)\n", getCurrentFolderName(), currentNode.UUID)
-	if currentNode.Parent != "" {
-		fmt.Printf("  Parent node:  %s\n", currentNode.Parent)
+// collectStatus gathers the location, cache, configuration and session statistics
+func collectStatus(sh *Shell) statusReport {
+	var report statusReport
+
+	current := sh.getCurrentNode()
+	report.Location.UUID = current.UUID
+	report.Location.Title = sh.getCurrentFolderName()
+	report.Location.Parent = current.Parent
+	report.Location.Nodes = len(sh.getCurrentNodes())
+
+	report.Cache.Aspects = len(sh.GetCachedAspects())
+	report.Cache.Actions = len(sh.GetCachedActions())
+	report.Cache.Extensions = len(sh.GetCachedExtensions())
+	report.Cache.Tools = len(sh.GetCachedTools())
+	report.Cache.Agents = len(sh.GetCachedAgents())
+
+	if sh.cache != nil {
+		report.NodeCache = &statusNodeCache{
+			TTL:        sh.cacheTTL().String(),
+			Persisted:  sh.getSetting("cache-persist") == "true",
+			CacheStats: sh.cache.Stats(),
+		}
 	}
-	fmt.Printf("  Nodes here:   %d\n", len(currentNodes))
-	fmt.Println()
 
-	fmt.Println("Cached Resource Statistics:")
-	fmt.Println("========================================")
-	fmt.Printf("  Aspects:    %d\n", len(aspects))
-	fmt.Printf("  Actions:    %d\n", len(actions))
-	fmt.Printf("  Extensions: %d\n", len(extensions))
-	fmt.Printf("  Agents:     %d\n", len(agents))
-	fmt.Println()
+	if configPath, exists, err := getConfigInfo(); err == nil {
+		report.Config.File = configPath
+		report.Config.Exists = exists
+	} else {
+		report.Config.Error = err.Error()
+	}
+	report.Config.History = len(sh.getHistory())
+	report.Config.MaxHistory = maxHistorySize
+	report.Config.Settings = sh.settingsSnapshot()
+
+	sessions := sh.sessions.ListSessions()
+	report.Sessions.Active = len(sessions)
+	report.Sessions.Saved = len(sh.sessions.ListSavedSessions())
+	for i, sessionID := range sessions {
+		messages := len(sh.sessions.GetSession(sessionID).GetHistory())
+		report.Sessions.Messages += messages
+		if i < 5 {
+			report.Sessions.Recent = append(report.Sessions.Recent, statusSession{ID: sessionID, Messages: messages})
+		}
+	}
 
-	total := len(aspects) + len(actions) + len(extensions) + len(agents)
-	fmt.Printf("Total resources: %d\n", total)
+	return report
+}
+
+// printStatus prints a status report for people
+func printStatus(sh *Shell, report statusReport) {
+	sh.println("Current Location:")
+	sh.println("========================================")
+	sh.printf("  Current node: %s (%s)\n", report.Location.Title, report.Location.UUID)
+	if report.Location.Parent != "" {
+		sh.printf("  Parent node:  %s\n", report.Location.Parent)
+	}
+	sh.printf("  Nodes here:   %d\n", report.Location.Nodes)
+	sh.println()
+
+	sh.println("Cached Resource Statistics:")
+	sh.println("========================================")
+	sh.printf("  Aspects:    %d\n", report.Cache.Aspects)
+	sh.printf("  Actions:    %d\n", report.Cache.Actions)
+	sh.printf("  Extensions: %d\n", report.Cache.Extensions)
+	sh.printf("  AI Tools:   %d\n", report.Cache.Tools)
+	sh.printf("  Agents:     %d\n", report.Cache.Agents)
+	sh.println()
+
+	total := report.totalCached()
+	sh.printf("Total resources: %d\n", total)
+
+	if cache := report.NodeCache; cache != nil {
+		sh.println()
+		sh.println("Node Cache:")
+		sh.println("========================================")
+		if cache.TTL == "0s" {
+			sh.println("  Disabled (cache-ttl is 0)")
+		} else {
+			persisted := ""
+			if cache.Persisted {
+				persisted = " (kept between runs)"
+			}
+			sh.printf("  TTL:         %s%s\n", cache.TTL, persisted)
+			sh.printf("  Cached:      %d nodes, %d listings\n", cache.Nodes, cache.Listings)
+		}
+		lookups := cache.Hits + cache.Misses
+		if lookups > 0 {
+			sh.printf("  Hits:        %d of %d lookups (%d%%)\n", cache.Hits, lookups, cache.Hits*100/lookups)
+		} else {
+			sh.println("  Hits:        no lookups yet")
+		}
+	}
 
 	// Show configuration information
-	fmt.Println()
-	fmt.Println("Configuration:")
-	fmt.Println("========================================")
-	if configPath, exists, err := getConfigInfo(); err == nil {
-		fmt.Printf("  Config file: %s\n", configPath)
-		if exists {
-			fmt.Printf("  Status:      EXISTS\n")
+	sh.println()
+	sh.println("Configuration:")
+	sh.println("========================================")
+	if report.Config.Error == "" {
+		sh.printf("  Config file: %s\n", report.Config.File)
+		if report.Config.Exists {
+			sh.printf("  Status:      EXISTS\n")
 		} else {
-			fmt.Printf("  Status:      Will be created on first command\n")
+			sh.printf("  Status:      Will be created on first command\n")
 		}
 	} else {
-		fmt.Printf("  Config file: Error getting path (%v)\n", err)
+		sh.printf("  Config file: Error getting path (%s)\n", report.Config.Error)
+	}
+	sh.printf("  History:     %d commands saved\n", report.Config.History)
+	sh.printf("  Max history: %d commands\n", report.Config.MaxHistory)
+	for _, name := range slices.Sorted(maps.Keys(report.Config.Settings)) {
+		sh.printf("  %-12s %s\n", name+":", report.Config.Settings[name])
 	}
-	fmt.Printf("  History:     %d commands saved\n", len(cliHistory))
-	fmt.Printf("  Max history: %d commands\n", maxHistorySize)
 
 	// Show conversation session statistics
-	sessionCount := GetActiveSessionCount()
-	fmt.Println()
-	fmt.Println("Conversation Sessions:")
-	fmt.Println("========================================")
-	fmt.Printf("  Active sessions: %d\n", sessionCount)
+	sessionCount := report.Sessions.Active
+	sh.println()
+	sh.println("Conversation Sessions:")
+	sh.println("========================================")
+	sh.printf("  Active sessions: %d\n", sessionCount)
+	sh.printf("  Saved sessions:  %d\n", report.Sessions.Saved)
 
 	if sessionCount > 0 {
-		sessions := ListActiveSessions()
-		totalMessages := 0
-		for _, sessionID := range sessions {
-			session := GetOrCreateSession(sessionID)
-			totalMessages += len(session.GetHistory())
-		}
-		fmt.Printf("  Total messages:  %d\n", totalMessages)
+		sh.printf("  Total messages:  %d\n", report.Sessions.Messages)
 
-		fmt.Println()
-		fmt.Println("  Recent sessions:")
-		displayCount := sessionCount
-		if displayCount > 5 {
-			displayCount = 5
-		}
-		for i := 0; i < displayCount; i++ {
-			sessionID := sessions[i]
-			session := GetOrCreateSession(sessionID)
-			messageCount := len(session.GetHistory())
-			fmt.Printf("    %s (%d messages)\n", sessionID, messageCount)
+		sh.println()
+		sh.println("  Recent sessions:")
+		for _, session := range report.Sessions.Recent {
+			sh.printf("    %s (%d messages)\n", session.ID, session.Messages)
 		}
-		if sessionCount > 5 {
-			fmt.Printf("    ... and %d more (use 'sessions list' to see all)\n", sessionCount-5)
+		if sessionCount > len(report.Sessions.Recent) {
+			sh.printf("    ... and %d more (use 'sessions list' to see all)\n", sessionCount-len(report.Sessions.Recent))
 		}
 	} else {
-		fmt.Println("  No active conversation sessions")
-		fmt.Println("  Start a conversation using 'chat' or 'rag' with -c <session_id>")
+		sh.println("  No active conversation sessions")
+		sh.println("  Start a conversation using 'chat' or 'rag' with -c <session_id>")
 	}
 
 	if total == 0 {
-		fmt.Println()
-		fmt.Println("Note: No resources loaded. This might indicate:")
-		fmt.Println("  - Connection issues during startup")
-		fmt.Println("  - No resources available on the server")
-		fmt.Println("  - Authentication problems")
-		fmt.Println()
-		fmt.Println("Try running 'reload' to refresh the cache.")
+		sh.println()
+		sh.println("Note: No resources loaded. This might indicate:")
+		sh.println("  - Connection issues during startup")
+		sh.println("  - No resources available on the server")
+		sh.println("  - Authentication problems")
+		sh.println()
+		sh.println("Try running 'reload' to refresh the cache.")
 	}
 }
 
-func (c *StatusCommand) Suggest(d prompt.Document) []prompt.Suggest {
+func (c *StatusCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
 	return []prompt.Suggest{}
 }
 
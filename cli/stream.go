@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kindalus/antx/antbox"
+	"github.com/mattn/go-runewidth"
+)
+
+// streamPrinter prints the text of a streaming response as it arrives. The loading
+// animation keeps running until the first output. In trace mode, tool calls, tool
+// responses and reasoning are printed too, with the time they arrived.
+type streamPrinter struct {
+	out         io.Writer
+	animation   *LoadingAnimation
+	doneMessage string
+	prefix      string
+	trace       bool
+	start       time.Time
+	printed     strings.Builder
+	started     bool
+	inText      bool
+	inReasoning bool
+	traced      int
+}
+
+// newStreamPrinter returns a printer writing to out that stops animation with doneMessage and
+// prints prefix before the text it receives
+func newStreamPrinter(out io.Writer, animation *LoadingAnimation, doneMessage, prefix string, trace bool) *streamPrinter {
+	return &streamPrinter{
+		out:         out,
+		animation:   animation,
+		doneMessage: doneMessage,
+		prefix:      prefix,
+		trace:       trace,
+		start:       time.Now(),
+	}
+}
+
+// begin stops the loading animation before the first output
+func (p *streamPrinter) begin() {
+	if !p.started {
+		p.animation.StopWithMessage(p.doneMessage)
+		p.started = true
+	}
+}
+
+// onPart prints a part as it arrives, it's used as the stream handler of the client
+func (p *streamPrinter) onPart(part antbox.ChatMessagePart) {
+	switch {
+	case part.Text != nil:
+		if *part.Text == "" {
+			return
+		}
+		p.begin()
+		if !p.inText {
+			p.endLine()
+			p.inText = true
+			p.printed.Reset()
+			p.write(p.prefix)
+		}
+		p.write(*part.Text)
+
+	case !p.trace:
+		return
+
+	case part.Reasoning != nil:
+		if *part.Reasoning == "" {
+			return
+		}
+		p.begin()
+		if !p.inReasoning {
+			p.endLine()
+			p.inReasoning = true
+			fmt.Fprintf(p.out, "  %s ", p.elapsed())
+		}
+		fmt.Fprint(p.out, *part.Reasoning)
+
+	case part.ToolCall != nil || part.ToolResponse != nil:
+		p.begin()
+		p.endLine()
+		fmt.Fprintf(p.out, "  %s %s\n", p.elapsed(), formatTraceStep(part))
+		p.traced++
+	}
+}
+
+// endLine ends the text or reasoning being streamed, so the next output starts on its own line
+func (p *streamPrinter) endLine() {
+	if p.inText || p.inReasoning {
+		fmt.Fprintln(p.out)
+	}
+	if p.inReasoning {
+		p.traced++
+	}
+	p.inText = false
+	p.inReasoning = false
+}
+
+// elapsed returns the time since the request was sent, as shown in traces
+func (p *streamPrinter) elapsed() string {
+	return fmt.Sprintf("[+%.1fs]", time.Since(p.start).Seconds())
+}
+
+func (p *streamPrinter) write(text string) {
+	fmt.Fprint(p.out, text)
+	p.printed.WriteString(text)
+}
+
+// finish shows the final response. When text was streamed to a terminal it's replaced
+// by final, so the response can be re-rendered (e.g. as markdown) once complete.
+// In trace mode, the steps of turn that weren't streamed are printed before it.
+func (p *streamPrinter) finish(final string, turn antbox.ChatHistory) {
+	p.begin()
+
+	if p.inReasoning {
+		p.endLine()
+	}
+
+	if p.trace && p.traced == 0 && !p.inText {
+		// Nothing was streamed, the whole turn is only known now
+		for _, step := range traceSteps(turn) {
+			fmt.Fprintf(p.out, "  %s\n", formatTraceStep(step))
+		}
+	}
+
+	p.printFinal(final)
+
+	if p.trace {
+		fmt.Fprintf(p.out, "  (%.1fs)\n", time.Since(p.start).Seconds())
+	}
+}
+
+func (p *streamPrinter) printFinal(final string) {
+	if !p.inText {
+		fmt.Fprintln(p.out, final)
+		return
+	}
+
+	streamed := p.printed.String()
+	if final == streamed {
+		fmt.Fprintln(p.out)
+		return
+	}
+
+	rows := 0
+	if isTerminal(p.out) {
+		rows = terminalRows(streamed, terminalWidth())
+	}
+	if rows == 0 {
+		// Not a terminal, the streamed text can't be replaced
+		fmt.Fprintln(p.out)
+		return
+	}
+
+	fmt.Fprint(p.out, "\r")
+	if rows > 1 {
+		fmt.Fprintf(p.out, "\033[%dA", rows-1)
+	}
+	fmt.Fprint(p.out, "\033[J")
+	fmt.Fprintln(p.out, final)
+}
+
+// fail reports an error, ending the streamed output first if any was printed
+func (p *streamPrinter) fail(message string) {
+	if p.started {
+		p.endLine()
+		fmt.Fprintln(p.out, message)
+		return
+	}
+	p.animation.StopWithMessage(message)
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// terminalRows returns the number of terminal rows text takes at the given width,
+// or 0 when the width is unknown
+func terminalRows(text string, width int) int {
+	if width <= 0 {
+		return 0
+	}
+
+	rows := 0
+	for _, line := range strings.Split(ansiEscape.ReplaceAllString(text, ""), "\n") {
+		columns := runewidth.StringWidth(line)
+		rows += max(1, (columns+width-1)/width)
+	}
+	return rows
+}
+
+// responseText returns the text of the last model message that has any
+func responseText(history antbox.ChatHistory) string {
+	for i := len(history) - 1; i >= 0; i-- {
+		msg := history[i]
+		if msg.Role != antbox.ChatMessageRoleModel {
+			continue
+		}
+		for _, part := range msg.Parts {
+			if part.Text != nil {
+				return *part.Text
+			}
+		}
+	}
+	return ""
+}
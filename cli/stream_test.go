@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+func TestTerminalRows(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  int
+	}{
+		{"hello", 0, 0},
+		{"hello", 80, 1},
+		{"hello\nworld", 80, 2},
+		{"1234567890", 10, 1},
+		{"12345678901", 10, 2},
+		{"\033[32mAssistant:\033[0m hi", 14, 1},
+		{"line\n", 80, 2},
+		// Wide characters take two columns
+		{"日本語テキスト", 10, 2},
+	}
+
+	for _, tt := range tests {
+		if got := terminalRows(tt.text, tt.width); got != tt.want {
+			t.Errorf("terminalRows(%q, %d) = %d, want %d", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestResponseText(t *testing.T) {
+	first, last := "first", "last"
+	history := antbox.ChatHistory{
+		{Role: antbox.ChatMessageRoleModel, Parts: []antbox.ChatMessagePart{{Text: &first}}},
+		{Role: antbox.ChatMessageRoleModel, Parts: []antbox.ChatMessagePart{{ToolCall: &antbox.ToolCall{Name: "t"}}, {Text: &last}}},
+		{Role: antbox.ChatMessageRoleTool, Parts: []antbox.ChatMessagePart{{ToolResponse: &antbox.ToolResponse{Name: "t"}}}},
+	}
+
+	if got := responseText(history); got != "last" {
+		t.Errorf("Expected 'last', got %q", got)
+	}
+	if got := responseText(nil); got != "" {
+		t.Errorf("Expected empty text, got %q", got)
+	}
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// parseStructuredReply decodes an agent reply as JSON. Models often wrap JSON in a
+// markdown code block, so a surrounding ``` fence is removed first.
+func parseStructuredReply(text string) (any, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```")
+		if newline := strings.Index(text, "\n"); newline >= 0 {
+			// Drop the language tag, e.g. ```json
+			text = text[newline+1:]
+		}
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return nil, fmt.Errorf("reply is not valid JSON: %v", err)
+	}
+	return value, nil
+}
+
+// parseStructuredSchema decodes an agent's structured answer schema
+func parseStructuredSchema(schema string) (map[string]any, error) {
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(schema), &decoded); err != nil {
+		return nil, fmt.Errorf("structured answer schema is not a JSON object: %v", err)
+	}
+	return decoded, nil
+}
+
+// extractField returns the value at a dot separated path, e.g. items.0.name.
+// Numeric segments index arrays.
+func extractField(value any, path string) (any, error) {
+	current := value
+	for _, segment := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case map[string]any:
+			next, ok := v[segment]
+			if !ok {
+				return nil, fmt.Errorf("field %q not found in %s", segment, path)
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not an index of the array in %s", segment, path)
+			}
+			if index < 0 {
+				index += len(v)
+			}
+			if index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("index %s out of range (%d items) in %s", segment, len(v), path)
+			}
+			current = v[index]
+		default:
+			return nil, fmt.Errorf("can't get %q of a %s in %s", segment, jsonType(current), path)
+		}
+	}
+	return current, nil
+}
+
+// printStructured prints a structured value as indented JSON or as a table.
+// Strings and numbers are printed bare so they can be used directly by scripts.
+func printStructured(sh *Shell, value any, format string) error {
+	switch v := value.(type) {
+	case string:
+		sh.println(v)
+		return nil
+	case float64, bool, nil:
+		sh.println(compactJSON(v))
+		return nil
+	}
+
+	switch format {
+	case "", "json":
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		sh.println(string(data))
+	case "table":
+		printStructuredTable(sh, value)
+	default:
+		return fmt.Errorf("unknown output format: %s (use json or table)", format)
+	}
+	return nil
+}
+
+// printStructuredTable prints a list of objects with one column per property, an object
+// as property/value rows, and any other list one item per row
+func printStructuredTable(sh *Shell, value any) {
+	w := tabwriter.NewWriter(sh.out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, key := range structuredColumns([]any{v}) {
+			fmt.Fprintf(w, "%s\t%s\n", key, tableCell(v[key]))
+		}
+	case []any:
+		columns := structuredColumns(v)
+		if len(columns) == 0 {
+			for _, item := range v {
+				fmt.Fprintln(w, tableCell(item))
+			}
+			return
+		}
+
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = strings.ToUpper(column)
+		}
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+		for _, item := range v {
+			object, _ := item.(map[string]any)
+			cells := make([]string, len(columns))
+			for i, column := range columns {
+				cells[i] = tableCell(object[column])
+			}
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+	}
+}
+
+// structuredColumns returns the keys of the objects in items, in order of first appearance
+func structuredColumns(items []any) []string {
+	var columns []string
+	for _, item := range items {
+		object, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			if !slices.Contains(columns, key) {
+				columns = append(columns, key)
+			}
+		}
+	}
+	return columns
+}
+
+// tableCell formats a value for a table cell on a single line
+func tableCell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.ReplaceAll(v, "\n", " ")
+	default:
+		return compactJSON(v)
+	}
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+const invoiceSchema = `{
+	"type": "object",
+	"required": ["total", "items"],
+	"additionalProperties": false,
+	"properties": {
+		"total": {"type": "number", "minimum": 0},
+		"currency": {"type": "string", "enum": ["EUR", "USD"]},
+		"items": {
+			"type": "array",
+			"minItems": 1,
+			"items": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {
+					"name": {"type": "string", "minLength": 1},
+					"quantity": {"type": "integer"}
+				}
+			}
+		}
+	}
+}`
+
+func TestValidateSchema(t *testing.T) {
+	schema, err := parseStructuredSchema(invoiceSchema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		reply string
+		want  []string
+	}{
+		{
+			name:  "valid",
+			reply: `{"total": 10.5, "currency": "EUR", "items": [{"name": "Pen", "quantity": 2}]}`,
+		},
+		{
+			name:  "missing required and wrong types",
+			reply: `{"items": [{"quantity": 1.5}], "currency": "AOA"}`,
+			want: []string{
+				`$: missing required property "total"`,
+				`$.currency: must be one of ["EUR","USD"]`,
+				`$.items[0]: missing required property "name"`,
+				`$.items[0].quantity: expected integer, got number`,
+			},
+		},
+		{
+			name:  "extra property and constraints",
+			reply: `{"total": -1, "items": [], "notes": "x"}`,
+			want: []string{
+				`$.items: must have at least 1 items, has 0`,
+				`$.notes: property is not allowed`,
+				`$.total: must be at least 0`,
+			},
+		},
+		{
+			name:  "wrong root type",
+			reply: `[1, 2]`,
+			want:  []string{`$: expected object, got array`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := parseStructuredReply(tt.reply)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := validateSchema(schema, value)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestValidateSchemaCombinators(t *testing.T) {
+	schema, _ := parseStructuredSchema(`{"oneOf": [{"type": "string"}, {"type": "integer"}], "anyOf": [{"type": "string"}, {"type": "number"}]}`)
+
+	if got := validateSchema(schema, "text"); len(got) != 0 {
+		t.Errorf("expected string to be valid, got %v", got)
+	}
+	if got := validateSchema(schema, true); len(got) != 2 {
+		t.Errorf("expected two violations for a boolean, got %v", got)
+	}
+}
+
+func TestParseStructuredReply(t *testing.T) {
+	value, err := parseStructuredReply("```json\n{\"answer\": 42}\n```")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value.(map[string]any)["answer"] != float64(42) {
+		t.Errorf("unexpected value: %v", value)
+	}
+
+	if _, err := parseStructuredReply("The answer is 42"); err == nil {
+		t.Error("expected an error for a reply that isn't JSON")
+	}
+}
+
+func TestExtractField(t *testing.T) {
+	value, _ := parseStructuredReply(`{"items": [{"name": "Pen"}, {"name": "Book"}], "total": 3}`)
+
+	tests := []struct {
+		path    string
+		want    any
+		wantErr bool
+	}{
+		{path: "total", want: float64(3)},
+		{path: "items.0.name", want: "Pen"},
+		{path: "items.-1.name", want: "Book"},
+		{path: "items.2.name", wantErr: true},
+		{path: "items.first", wantErr: true},
+		{path: "total.value", wantErr: true},
+		{path: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := extractField(value, tt.path)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("extractField(%q): expected an error, got %v", tt.path, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("extractField(%q) = %v, %v; want %v", tt.path, got, err, tt.want)
+		}
+	}
+}
@@ -10,11 +10,12 @@ import (
 	"github.com/kindalus/antx/antbox"
 )
 
-func getNodeSuggestions(word string, filter func(node antbox.Node) bool) []prompt.Suggest {
+func getNodeSuggestions(sh *Shell, word string, filter func(node antbox.Node) bool) []prompt.Suggest {
 	var nodeSuggestions []prompt.Suggest
 	addedUUIDs := make(map[string]bool) // Track added UUIDs to avoid duplicates
 
-	for _, node := range currentNodes {
+	nodes := sh.getCurrentNodes()
+	for _, node := range nodes {
 		if filter != nil && !filter(node) {
 			continue
 		}
@@ -23,20 +24,39 @@ func getNodeSuggestions(word string, filter func(node antbox.Node) bool) []promp
 			strings.HasPrefix(strings.ToLower(node.UUID), strings.ToLower(word))) &&
 			!addedUUIDs[node.UUID] {
 
-			var description string
-			if node.Mimetype == "application/vnd.antbox.folder" {
-				description = node.Title
-			} else {
-				description = node.Title
-			}
-
 			nodeSuggestions = append(nodeSuggestions, prompt.Suggest{
 				Text:        node.UUID,
-				Description: description,
+				Description: node.Title,
 			})
 			addedUUIDs[node.UUID] = true
 		}
 	}
+
+	// Paths into subfolders whose listings are cached, such as Docs/Report
+	folderTitle, rest, isPath := strings.Cut(word, "/")
+	if !isPath || sh.cache == nil {
+		return nodeSuggestions
+	}
+	for _, folder := range nodes {
+		if !folderFilter(folder) || !strings.EqualFold(folder.Title, folderTitle) {
+			continue
+		}
+		children, ok := sh.cache.CachedListing(folder.UUID)
+		if !ok {
+			continue
+		}
+		for _, child := range children {
+			if (filter != nil && !filter(child)) || addedUUIDs[child.UUID] ||
+				!strings.HasPrefix(strings.ToLower(child.Title), strings.ToLower(rest)) {
+				continue
+			}
+			nodeSuggestions = append(nodeSuggestions, prompt.Suggest{
+				Text:        child.UUID,
+				Description: folder.Title + "/" + child.Title,
+			})
+			addedUUIDs[child.UUID] = true
+		}
+	}
 	return nodeSuggestions
 }
 
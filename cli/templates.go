@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
 )
 
 type TemplatesCommand struct{}
@@ -19,27 +21,28 @@ func (c *TemplatesCommand) GetDescription() string {
 	return "List all available templates or download a specific template"
 }
 
-func (c *TemplatesCommand) Execute(args []string) {
+func (c *TemplatesCommand) Execute(sh *Shell, args []string) {
+	opts, args, err := parseListFlags(sh, args)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
 	if len(args) == 0 {
 		// List all templates
-		templates, err := client.ListTemplates()
+		templates, err := sh.client.ListTemplates()
 		if err != nil {
-			fmt.Println("Error listing templates:", err)
+			sh.eprintln("Error listing templates:", err)
 			return
 		}
 
-		if len(templates) == 0 {
-			fmt.Println("No templates available.")
+		if len(templates) == 0 && opts.forPeople() && !sh.piping {
+			sh.println("No templates available.")
 			return
 		}
 
-		fmt.Println("Available templates:")
-		fmt.Println()
-		for _, template := range templates {
-			fmt.Printf("UUID: %s\n", template.UUID)
-			fmt.Printf("  Mimetype: %s\n", template.Mimetype)
-			fmt.Printf("  Size: %d bytes\n", template.Size)
-			fmt.Println()
+		if err := renderList(sh, opts, templates, templateColumns); err != nil {
+			sh.eprintln("Error:", err)
 		}
 		return
 	}
@@ -48,16 +51,16 @@ func (c *TemplatesCommand) Execute(args []string) {
 	templateUUID := args[0]
 
 	// Get template data
-	templateData, err := client.GetTemplate(templateUUID)
+	templateData, err := sh.client.GetTemplate(templateUUID)
 	if err != nil {
-		fmt.Println("Error getting template:", err)
+		sh.eprintln("Error getting template:", err)
 		return
 	}
 
 	// Get user's Downloads directory
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		fmt.Println("Error getting home directory:", err)
+		sh.eprintln("Error getting home directory:", err)
 		return
 	}
 
@@ -68,29 +71,40 @@ func (c *TemplatesCommand) Execute(args []string) {
 	// Ensure Downloads directory exists
 	downloadsDir := filepath.Join(homeDir, "Downloads")
 	if err := os.MkdirAll(downloadsDir, 0755); err != nil {
-		fmt.Println("Error creating Downloads directory:", err)
+		sh.eprintln("Error creating Downloads directory:", err)
 		return
 	}
 
 	// Write template data to file
 	err = os.WriteFile(downloadPath, templateData, 0644)
 	if err != nil {
-		fmt.Println("Error writing template file:", err)
+		sh.eprintln("Error writing template file:", err)
 		return
 	}
 
-	fmt.Printf("Template downloaded to %s\n", downloadPath)
+	sh.printf("Template downloaded to %s\n", downloadPath)
+}
+
+// templateColumns are the columns of template listings
+var templateColumns = []listColumn[antbox.Template]{
+	{Name: "uuid", Header: "UUID", Value: func(t antbox.Template) string { return t.UUID }},
+	{Name: "mimetype", Header: "MIMETYPE", Value: func(t antbox.Template) string { return t.Mimetype }},
+	{
+		Name: "size", Header: "SIZE",
+		Value: func(t antbox.Template) string { return strconv.Itoa(t.Size) },
+		Cell:  func(t antbox.Template, opts listOptions) string { return fmt.Sprintf("%d bytes", t.Size) },
+	},
 }
 
-func (c *TemplatesCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	args := strings.Split(d.TextBeforeCursor(), " ")
+func (c *TemplatesCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := argsBeforeCursor(d)
 
 	if len(args) <= 2 {
 		// First argument - suggest template UUIDs
-		word := d.GetWordBeforeCursor()
+		word := wordBeforeCursor(d)
 
 		// Get cached templates or fetch them
-		templates, err := client.ListTemplates()
+		templates, err := sh.client.ListTemplates()
 		if err != nil {
 			return []prompt.Suggest{}
 		}
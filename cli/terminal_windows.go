@@ -0,0 +1,26 @@
+//go:build windows
+
+package cli
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// terminalWidth returns the number of columns of the terminal attached to stdout.
+// It's not detected on Windows, so streamed output is never redrawn there.
+func terminalWidth() int {
+	return 0
+}
+
+// isTerminal reports whether w is a console
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(f.Fd()), &mode) == nil
+}
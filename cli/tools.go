@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
+)
+
+type ToolsCommand struct{}
+
+func (c *ToolsCommand) GetName() string {
+	return "tools"
+}
+
+func (c *ToolsCommand) GetDescription() string {
+	return "List, inspect and call AI tools"
+}
+
+func (c *ToolsCommand) Execute(sh *Shell, args []string) {
+	if len(args) == 0 {
+		c.listTools(sh)
+		return
+	}
+
+	subcommand := args[0]
+	switch subcommand {
+	case "list":
+		c.listTools(sh)
+	case "show":
+		if len(args) < 2 {
+			sh.println("Usage: tools show <uuid>")
+			return
+		}
+		c.showTool(sh, args[1])
+	case "call":
+		asAgent := false
+		var callArgs []string
+		for _, arg := range args[1:] {
+			if arg == "--as-agent" {
+				asAgent = true
+				continue
+			}
+			callArgs = append(callArgs, arg)
+		}
+		if len(callArgs) == 0 {
+			sh.println("Usage: tools call [--as-agent] <uuid> [param=value...]")
+			return
+		}
+		c.callTool(sh, callArgs[0], callArgs[1:], asAgent)
+	case "help", "-h":
+		c.showUsage(sh)
+	default:
+		sh.printf("Unknown subcommand: %s\n", subcommand)
+		c.showUsage(sh)
+	}
+}
+
+func (c *ToolsCommand) showUsage(sh *Shell) {
+	sh.println("Usage: tools <subcommand> [args]")
+	sh.println()
+	sh.println("Subcommands:")
+	sh.println("  list                                          List all AI tools (default)")
+	sh.println("  show <uuid>                                   Show tool details and parameters")
+	sh.println("  call [--as-agent] <uuid> [param=value...]     Call a tool and print its result")
+	sh.println()
+	sh.println("Options:")
+	sh.println("  --as-agent: Print the call as the tool call and tool response messages")
+	sh.println("              an agent would see in its chat history")
+	sh.println()
+	sh.println("Examples:")
+	sh.println("  tools")
+	sh.println("  tools show search_nodes")
+	sh.println("  tools call search_nodes query=invoice")
+	sh.println("  tools call --as-agent search_nodes query=invoice")
+}
+
+func (c *ToolsCommand) listTools(sh *Shell) {
+	tools, err := sh.client.ListAITools()
+	if err != nil {
+		sh.eprintln("Error listing AI tools:", err)
+		return
+	}
+
+	// Keep the completion cache in sync with what we just fetched
+	sh.updateResources(func() { sh.tools = tools })
+
+	if len(tools) == 0 {
+		sh.println("No AI tools available.")
+		return
+	}
+
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+
+	sh.printf("Available AI tools (%d):\n", len(tools))
+	sh.println()
+
+	for _, tool := range tools {
+		sh.printf("UUID: %s\n", tool.UUID)
+		sh.printf("  Name: %s\n", tool.Name)
+		if tool.Description != "" {
+			sh.printf("  Description: %s\n", tool.Description)
+		}
+		if len(tool.Parameters) > 0 {
+			names := make([]string, len(tool.Parameters))
+			for i, param := range tool.Parameters {
+				names[i] = param.Name
+			}
+			sh.printf("  Parameters: %s\n", strings.Join(names, ", "))
+		}
+		sh.println()
+	}
+}
+
+func (c *ToolsCommand) showTool(sh *Shell, uuid string) {
+	tool := findCachedFeature(uuid, sh.GetCachedTools())
+	if tool == nil {
+		feature, err := sh.client.GetFeature(uuid)
+		if err != nil {
+			sh.eprintln("Error:", err)
+			return
+		}
+		tool = feature
+	}
+
+	printFeature(sh, tool)
+}
+
+func (c *ToolsCommand) callTool(sh *Shell, uuid string, paramArgs []string, asAgent bool) {
+	declared := findFeatureParameters(sh, uuid, sh.GetCachedTools())
+	parameters, err := resolveParameters(sh, declared, parseParameterArgs(sh, paramArgs))
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	result, err := sh.client.RunAITool(uuid, parameters)
+	if err != nil {
+		sh.eprintln("Error running AI tool:", err)
+		return
+	}
+
+	var output any = result
+	if asAgent {
+		output, err = toolCallHistory(uuid, parameters, result)
+		if err != nil {
+			sh.eprintln("Error:", err)
+			return
+		}
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		sh.eprintln("Error formatting result:", err)
+		return
+	}
+
+	sh.println(string(data))
+}
+
+// toolCallHistory renders a tool invocation as the ToolCall/ToolResponse message pair
+// that appears in an agent's chat history
+func toolCallHistory(name string, args map[string]any, result map[string]any) (antbox.ChatHistory, error) {
+	// Tool responses carry the result as text, the same way the server hands it to the model
+	text, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return antbox.ChatHistory{
+		{
+			Role: antbox.ChatMessageRoleModel,
+			Parts: []antbox.ChatMessagePart{
+				{ToolCall: &antbox.ToolCall{Name: name, Args: args}},
+			},
+		},
+		{
+			Role: antbox.ChatMessageRoleTool,
+			Parts: []antbox.ChatMessagePart{
+				{ToolResponse: &antbox.ToolResponse{Name: name, Text: string(text)}},
+			},
+		},
+	}, nil
+}
+
+func (c *ToolsCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := fieldsBeforeCursor(d)
+
+	if len(args) == 0 {
+		return []prompt.Suggest{}
+	}
+
+	// Count actual arguments (excluding the command name)
+	argCount := len(args) - 1
+	if !cursorAfterBlank(d) && len(args) > 1 {
+		argCount = len(args) - 2 // We're still typing the current argument
+	}
+
+	currentWord := wordBeforeCursor(d)
+
+	if argCount == 0 {
+		subcommands := []prompt.Suggest{
+			{Text: "list", Description: "List all AI tools"},
+			{Text: "show", Description: "Show tool details and parameters"},
+			{Text: "call", Description: "Call a tool and print its result"},
+		}
+
+		var filtered []prompt.Suggest
+		for _, cmd := range subcommands {
+			if strings.HasPrefix(strings.ToLower(cmd.Text), strings.ToLower(currentWord)) {
+				filtered = append(filtered, cmd)
+			}
+		}
+		return filtered
+	}
+
+	subcommand := args[1]
+	if subcommand != "show" && subcommand != "call" {
+		return []prompt.Suggest{}
+	}
+
+	// Skip the --as-agent flag when looking for the tool UUID
+	var positional []string
+	for _, arg := range args[2 : argCount+1] {
+		if arg != "--as-agent" {
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) == 0 {
+		if subcommand == "call" && strings.HasPrefix(currentWord, "-") {
+			return []prompt.Suggest{{Text: "--as-agent", Description: "Render as agent chat history"}}
+		}
+
+		var suggests []prompt.Suggest
+		for _, tool := range sh.GetCachedTools() {
+			if strings.HasPrefix(strings.ToLower(tool.UUID), strings.ToLower(currentWord)) ||
+				strings.HasPrefix(strings.ToLower(tool.Name), strings.ToLower(currentWord)) {
+				suggests = append(suggests, prompt.Suggest{
+					Text:        tool.UUID,
+					Description: tool.Name,
+				})
+			}
+		}
+		return suggests
+	}
+
+	if subcommand == "call" {
+		if tool := findCachedFeature(positional[0], sh.GetCachedTools()); tool != nil {
+			return getParameterSuggestions(currentWord, tool.Parameters)
+		}
+	}
+
+	return []prompt.Suggest{}
+}
+
+func init() {
+	RegisterCommand(&ToolsCommand{})
+}
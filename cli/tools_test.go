@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+func TestToolCallHistory(t *testing.T) {
+	args := map[string]any{"query": "invoice"}
+	result := map[string]any{"count": float64(2)}
+
+	history, err := toolCallHistory("search_nodes", args, result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(history) != 2 {
+		t.Fatalf("Expected a tool call and a tool response, got %d messages", len(history))
+	}
+
+	call := history[0]
+	if call.Role != antbox.ChatMessageRoleModel || call.Parts[0].ToolCall == nil {
+		t.Errorf("Expected a model message with a tool call, got %+v", call)
+	} else if call.Parts[0].ToolCall.Name != "search_nodes" || call.Parts[0].ToolCall.Args["query"] != "invoice" {
+		t.Errorf("Unexpected tool call %+v", call.Parts[0].ToolCall)
+	}
+
+	response := history[1]
+	if response.Role != antbox.ChatMessageRoleTool || response.Parts[0].ToolResponse == nil {
+		t.Fatalf("Expected a tool message with a tool response, got %+v", response)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(response.Parts[0].ToolResponse.Text), &decoded); err != nil {
+		t.Fatalf("Expected the tool response text to be JSON: %v", err)
+	}
+	if decoded["count"] != float64(2) {
+		t.Errorf("Expected the result in the tool response, got %v", decoded)
+	}
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+// traceCollapseLimit is the number of characters of a tool response shown in traces
+const traceCollapseLimit = 300
+
+// traceSteps returns the parts of a turn shown in traces: everything the agent did
+// except the user's message and the final answer, which is printed on its own
+func traceSteps(turn antbox.ChatHistory) []antbox.ChatMessagePart {
+	answer := responseText(turn)
+
+	var steps []antbox.ChatMessagePart
+	for i, msg := range turn {
+		if msg.Role == antbox.ChatMessageRoleUser {
+			continue
+		}
+		for _, part := range msg.Parts {
+			if part.Text != nil && (*part.Text == "" || (*part.Text == answer && isLastModelMessage(turn, i))) {
+				continue
+			}
+			steps = append(steps, part)
+		}
+	}
+	return steps
+}
+
+// isLastModelMessage reports whether the message at index is the last model message with text
+func isLastModelMessage(history antbox.ChatHistory, index int) bool {
+	for i := len(history) - 1; i > index; i-- {
+		if history[i].Role == antbox.ChatMessageRoleModel && messageText(history[i]) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// formatTraceStep returns a one-line description of a step of a turn. Tool calls
+// show their arguments in full, long tool responses are collapsed.
+func formatTraceStep(part antbox.ChatMessagePart) string {
+	switch {
+	case part.ToolCall != nil:
+		args, _ := json.Marshal(part.ToolCall.Args)
+		return fmt.Sprintf("→ %s %s", part.ToolCall.Name, string(args))
+	case part.ToolResponse != nil:
+		text := part.ToolResponse.Text
+		if runes := []rune(text); len(runes) > traceCollapseLimit {
+			text = fmt.Sprintf("%s... (%d more characters)", string(runes[:traceCollapseLimit]), len(runes)-traceCollapseLimit)
+		}
+		return fmt.Sprintf("← %s %s", part.ToolResponse.Name, text)
+	case part.Reasoning != nil:
+		return "Reasoning: " + *part.Reasoning
+	default:
+		return formatChatPart(part)
+	}
+}
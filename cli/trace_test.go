@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+func TestTraceSteps(t *testing.T) {
+	question, thinking, interim, answer := "q", "Looking for invoices", "Let me search", "Found 2 invoices"
+	turn := antbox.ChatHistory{
+		{Role: antbox.ChatMessageRoleUser, Parts: []antbox.ChatMessagePart{{Text: &question}}},
+		{Role: antbox.ChatMessageRoleModel, Parts: []antbox.ChatMessagePart{
+			{Reasoning: &thinking},
+			{Text: &interim},
+			{ToolCall: &antbox.ToolCall{Name: "find", Args: map[string]any{"q": "invoice"}}},
+		}},
+		{Role: antbox.ChatMessageRoleTool, Parts: []antbox.ChatMessagePart{
+			{ToolResponse: &antbox.ToolResponse{Name: "find", Text: "[1,2]"}},
+		}},
+		{Role: antbox.ChatMessageRoleModel, Parts: []antbox.ChatMessagePart{{Text: &answer}}},
+	}
+
+	var got []string
+	for _, step := range traceSteps(turn) {
+		got = append(got, formatTraceStep(step))
+	}
+
+	want := []string{
+		"Reasoning: Looking for invoices",
+		"Let me search",
+		`→ find {"q":"invoice"}`,
+		"← find [1,2]",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected trace steps:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestFormatTraceStepCollapsesLongResponses(t *testing.T) {
+	part := antbox.ChatMessagePart{ToolResponse: &antbox.ToolResponse{
+		Name: "read",
+		Text: strings.Repeat("x", traceCollapseLimit+50),
+	}}
+
+	got := formatTraceStep(part)
+	if !strings.HasSuffix(got, "... (50 more characters)") {
+		t.Errorf("expected collapsed response, got %q", got[len(got)-40:])
+	}
+}
+
+func TestFormatTraceStepCollapsesByCharacter(t *testing.T) {
+	part := antbox.ChatMessagePart{ToolResponse: &antbox.ToolResponse{
+		Name: "read",
+		Text: strings.Repeat("é", traceCollapseLimit+5),
+	}}
+
+	got := formatTraceStep(part)
+	if !utf8.ValidString(got) || !strings.HasSuffix(got, "é... (5 more characters)") {
+		t.Errorf("expected collapse at a character boundary, got %q", got[len(got)-40:])
+	}
+}
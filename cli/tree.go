@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/kindalus/antx/antbox"
+)
+
+// defaultTreeDepth is the number of levels shown by tree when no depth is given
+const defaultTreeDepth = 3
+
+type TreeCommand struct{}
+
+func (c *TreeCommand) GetName() string {
+	return "tree"
+}
+
+func (c *TreeCommand) GetDescription() string {
+	return "Show the folder hierarchy"
+}
+
+func (c *TreeCommand) Execute(sh *Shell, args []string) {
+	depth := defaultTreeDepth
+	root := sh.getCurrentNode()
+
+	for _, arg := range args {
+		if n, err := strconv.Atoi(arg); err == nil {
+			if n < 1 {
+				sh.eprintln("Error: depth must be at least 1")
+				return
+			}
+			depth = n
+			continue
+		}
+
+		if arg == "--root--" {
+			root = antbox.Node{UUID: "--root--", Title: "root", Mimetype: "application/vnd.antbox.folder"}
+			continue
+		}
+		node, err := sh.client.GetNode(arg)
+		if err != nil {
+			sh.eprintln("Error:", err)
+			return
+		}
+		if !folderFilter(*node) {
+			sh.eprintf("Error: %s is not a folder\n", node.Title)
+			return
+		}
+		root = *node
+	}
+
+	var stats treeStats
+	lines, err := treeLines(sh, root.UUID, "", depth, &stats)
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+
+	sh.println(colorize(sh.out, root.Title, folderColor))
+	for _, line := range lines {
+		sh.println(line)
+	}
+	sh.printf("\n%d folders, %d files, %s\n", stats.folders, stats.files, humanSize(stats.size))
+}
+
+// treeStats counts the nodes shown by tree
+type treeStats struct {
+	folders int
+	files   int
+	size    int
+}
+
+// treeLines lists a folder and its subfolders down to depth levels, one line per node drawn
+// with box-drawing characters. Smart folders aren't expanded, as their content lives
+// elsewhere. Subfolders that can't be listed show the error in place of their content.
+func treeLines(sh *Shell, uuid, prefix string, depth int, stats *treeStats) ([]string, error) {
+	nodes, err := sh.client.ListNodes(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for i, node := range sortNodesForListing(nodes) {
+		branch, indent := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		if !folderFilter(node) {
+			stats.files++
+			stats.size += node.Size
+			lines = append(lines, fmt.Sprintf("%s%s%s (%s)", prefix, branch, node.Title, node.HumanReadableSize()))
+			continue
+		}
+
+		stats.folders++
+		lines = append(lines, prefix+branch+colorize(sh.out, node.Title, folderColor))
+		if node.Mimetype != "application/vnd.antbox.folder" || depth <= 1 {
+			continue
+		}
+
+		children, err := treeLines(sh, node.UUID, prefix+indent, depth-1, stats)
+		if err != nil {
+			lines = append(lines, prefix+indent+"└── Error: "+err.Error())
+			continue
+		}
+		lines = append(lines, children...)
+	}
+	return lines, nil
+}
+
+func (c *TreeCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	return getNodeSuggestions(sh, wordBeforeCursor(d), folderFilter)
+}
+
+func init() {
+	RegisterCommand(&TreeCommand{})
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kindalus/antx/antbox"
+)
+
+// folderMockClient lists the children of each folder from a map
+type folderMockClient struct {
+	mockClient
+	children map[string][]antbox.Node
+}
+
+func (c *folderMockClient) ListNodes(parent string) ([]antbox.Node, error) {
+	children, ok := c.children[parent]
+	if !ok {
+		return nil, errors.New("access denied")
+	}
+	return children, nil
+}
+
+func TestTreeLines(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	sh := newTestShell(&folderMockClient{children: map[string][]antbox.Node{
+		"root": {
+			{UUID: "notes", Title: "notes.txt", Mimetype: "text/plain", Size: 1024},
+			{UUID: "docs", Title: "Docs", Mimetype: "application/vnd.antbox.folder"},
+			{UUID: "locked", Title: "Locked", Mimetype: "application/vnd.antbox.folder"},
+			{UUID: "search", Title: "Search", Mimetype: "application/vnd.antbox.smartfolder"},
+		},
+		"docs": {
+			{UUID: "q1", Title: "q1.pdf", Mimetype: "application/pdf", Size: 2048},
+			{UUID: "old", Title: "Old", Mimetype: "application/vnd.antbox.folder"},
+		},
+		"old": {
+			{UUID: "q0", Title: "q0.pdf", Mimetype: "application/pdf", Size: 512},
+		},
+	}})
+
+	var stats treeStats
+	lines, err := treeLines(sh, "root", "", 2, &stats)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"├── Docs",
+		"│   ├── Old",
+		"│   └── q1.pdf (2.0K)",
+		"├── Locked",
+		"│   └── Error: access denied",
+		"├── Search",
+		"└── notes.txt (1.0K)",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	if stats.folders != 4 || stats.files != 2 || stats.size != 3072 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	if _, err := treeLines(sh, "missing", "", 1, &stats); err == nil {
+		t.Error("Expected an error when the folder can't be listed")
+	}
+}
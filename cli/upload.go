@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,13 +19,13 @@ func (c *UploadCommand) GetDescription() string {
 	return "Upload a file to a folder, feature, or aspect"
 }
 
-func (c *UploadCommand) Execute(args []string) {
+func (c *UploadCommand) Execute(sh *Shell, args []string) {
 	if len(args) == 0 {
-		fmt.Println("Usage: upload [-f|-a|-i|-u <uuid>] <file-path>")
-		fmt.Println("  -f: Upload as feature")
-		fmt.Println("  -a: Upload as aspect")
-		fmt.Println("  -i: Upload as AI agent")
-		fmt.Println("  -u <uuid>: Upload with given uuid existing file")
+		sh.println("Usage: upload [-f|-a|-i|-u <uuid>] <file-path>")
+		sh.println("  -f: Upload as feature")
+		sh.println("  -a: Upload as aspect")
+		sh.println("  -i: Upload as AI agent")
+		sh.println("  -u <uuid>: Upload with given uuid existing file")
 		return
 	}
 
@@ -50,7 +49,7 @@ func (c *UploadCommand) Execute(args []string) {
 			argIndex++
 		case "-u":
 			if argIndex+1 >= len(args) {
-				fmt.Println("Usage: upload -u <uuid> <file-path>")
+				sh.println("Usage: upload -u <uuid> <file-path>")
 				return
 			}
 			uploadType = "with_metadata"
@@ -64,70 +63,71 @@ func (c *UploadCommand) Execute(args []string) {
 	}
 
 	if filePath == "" {
-		fmt.Println("Error: file path is required")
+		sh.eprintln("Error: file path is required")
 		return
 	}
 
-	if strings.HasPrefix(filePath, `"`) && strings.HasSuffix(filePath, `"`) {
-		filePath = filePath[1 : len(filePath)-1]
-	}
-
-	fmt.Println("filePath:", filePath)
+	sh.println("filePath:", filePath)
 
 	switch uploadType {
 	case "feature":
-		feature, err := client.UploadFeature(filePath)
+		feature, err := sh.client.UploadFeature(filePath)
 		if err != nil {
-			fmt.Println("Error:", err)
+			sh.eprintln("Error:", err)
 			return
 		}
-		fmt.Printf("Feature %s uploaded successfully with UUID %s\n", filePath, feature.UUID)
+		sh.setLastNode(feature.UUID)
+		sh.printf("Feature %s uploaded successfully with UUID %s\n", filePath, feature.UUID)
 
 	case "aspect":
-		aspect, err := client.UploadAspect(filePath)
+		aspect, err := sh.client.UploadAspect(filePath)
 		if err != nil {
-			fmt.Println("Error:", err)
+			sh.eprintln("Error:", err)
 			return
 		}
-		fmt.Printf("Aspect %s uploaded successfully with UUID %s\n", filePath, aspect.UUID)
+		sh.setLastNode(aspect.UUID)
+		sh.printf("Aspect %s uploaded successfully with UUID %s\n", filePath, aspect.UUID)
 
 	case "agent":
-		agent, err := client.UploadAgent(filePath)
+		agent, err := sh.client.UploadAgent(filePath)
 		if err != nil {
-			fmt.Println("Error:", err)
+			sh.eprintln("Error:", err)
 			return
 		}
-		fmt.Printf("AI Agent %s uploaded successfully with UUID %s\n", filePath, agent.UUID)
+		sh.setLastNode(agent.UUID)
+		sh.printf("AI Agent %s uploaded successfully with UUID %s\n", filePath, agent.UUID)
 
 	case "with_metadata":
-		node, err := client.UpdateFile(updateUUID, filePath)
+		node, err := sh.client.UpdateFile(updateUUID, filePath)
 		if err != nil {
-			fmt.Println("Error:", err)
+			sh.eprintln("Error:", err)
 			return
 		}
-		fmt.Printf("File %s updated successfully for node %s\n", filePath, node.UUID)
+		sh.setLastNode(node.UUID)
+		sh.printf("File %s updated successfully for node %s\n", filePath, node.UUID)
 
 	default: // Regular file upload
 		metadata := antbox.NodeCreate{
 			Title:    filepath.Base(filePath),
 			Mimetype: "application/octet-stream",
-			Parent:   currentNode.UUID,
+			Parent:   sh.getCurrentNode().UUID,
 		}
-		node, err := client.CreateFile(filePath, metadata)
+		node, err := sh.client.CreateFile(filePath, metadata)
 		if err != nil {
-			fmt.Println("Error:", err)
+			sh.eprintln("Error:", err)
 			return
 		}
-		fmt.Printf("File %s uploaded successfully to node %s\n", filePath, node.UUID)
+		sh.setLastNode(node.UUID)
+		sh.printf("File %s uploaded successfully to node %s\n", filePath, node.UUID)
 	}
 }
 
-func (c *UploadCommand) Suggest(d prompt.Document) []prompt.Suggest {
-	args := strings.Split(d.TextBeforeCursor(), " ")
+func (c *UploadCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := argsBeforeCursor(d)
 
 	if len(args) == 2 {
 		// First argument - suggest flags
-		word := d.GetWordBeforeCursor()
+		word := wordBeforeCursor(d)
 		if strings.HasPrefix(word, "-") {
 			return []prompt.Suggest{
 				{Text: "-f", Description: "Upload as feature"},
@@ -151,14 +151,14 @@ func (c *UploadCommand) Suggest(d prompt.Document) []prompt.Suggest {
 		for i, arg := range args[1:] {
 			if arg == "-u" && i+2 == len(args)-1 {
 				// Next argument should be UUID
-				return getNodeSuggestions(d.GetWordBeforeCursor(), func(node antbox.Node) bool {
+				return getNodeSuggestions(sh, wordBeforeCursor(d), func(node antbox.Node) bool {
 					return node.Mimetype != "application/vnd.antbox.folder"
 				})
 			}
 		}
 
 		// Otherwise suggest file path
-		word := d.GetWordBeforeCursor()
+		word := wordBeforeCursor(d)
 		if word == "" {
 			homeDir, err := os.UserHomeDir()
 			if err == nil {
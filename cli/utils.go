@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"cmp"
 	"slices"
 	"sort"
 	"strconv"
@@ -144,23 +145,23 @@ func min(a, b int) int {
 }
 
 // mksmart creates a smart folder with the given arguments
-func mksmart(args []string) {
+func mksmart(sh *Shell, args []string) {
 	if cmd, ok := commands["mksmart"]; ok {
-		cmd.Execute(args)
+		cmd.Execute(sh, args)
 	}
 }
 
 // cd executes the cd command with the given arguments
-func cd(args []string) {
+func cd(sh *Shell, args []string) {
 	if cmd, ok := commands["cd"]; ok {
-		cmd.Execute(args)
+		cmd.Execute(sh, args)
 	}
 }
 
 // ls executes the ls command with the given arguments
-func ls(args []string) {
+func ls(sh *Shell, args []string) {
 	if cmd, ok := commands["ls"]; ok {
-		cmd.Execute(args)
+		cmd.Execute(sh, args)
 	}
 }
 
@@ -203,6 +204,48 @@ func sortNodesForListing(nodes []antbox.Node) []antbox.Node {
 	return result
 }
 
+// sortNodes sorts nodes by title with folders first, by modification time with the newest
+// first, or by size with the largest first. reverse inverts the order, keeping folders
+// first when sorting by title. Ties are ordered by title.
+func sortNodes(nodes []antbox.Node, by string, reverse bool) []antbox.Node {
+	sorted := slices.Clone(nodes)
+	slices.SortStableFunc(sorted, func(a, b antbox.Node) int {
+		if by == "title" && folderFilter(a) != folderFilter(b) {
+			if folderFilter(a) {
+				return -1
+			}
+			return 1
+		}
+
+		c := 0
+		switch by {
+		case "modified":
+			c = nodeTime(b.ModifiedAt).Compare(nodeTime(a.ModifiedAt))
+		case "size":
+			c = cmp.Compare(b.Size, a.Size)
+		}
+		if c == 0 {
+			c = cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		}
+		if reverse {
+			return -c
+		}
+		return c
+	})
+	return sorted
+}
+
+// nodeTime parses a node date, returning the zero time when it's missing or invalid
+func nodeTime(date string) time.Time {
+	t, _ := time.Parse(time.RFC3339, date)
+	return t
+}
+
+// humanSize formats a size in bytes as node sizes are shown
+func humanSize(size int) string {
+	return (&antbox.Node{Size: size}).HumanReadableSize()
+}
+
 // formatModifiedDate formats a date string to local time with conditional year display
 // Format: "mmm dd HH:mm" for current year, "mmm dd  yyyy" for other years
 func formatModifiedDate(dateStr string) string {
@@ -227,3 +270,34 @@ func formatModifiedDate(dateStr string) string {
 		return localTime.Format("Jan 02  2006")
 	}
 }
+
+// readInput prints a label and reads a line from the shell input, returning defaultValue for empty input
+func (sh *Shell) readInput(label string, defaultValue string) string {
+	if defaultValue != "" {
+		sh.printf("%s [%s]: ", label, defaultValue)
+	} else {
+		sh.printf("%s: ", label)
+	}
+
+	line, err := sh.in.ReadString('\n')
+	if err != nil && line == "" {
+		return defaultValue
+	}
+
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return defaultValue
+	}
+	return line
+}
+
+// confirm asks a yes/no question on the shell input, defaulting to no
+func (sh *Shell) confirm(question string) bool {
+	sh.printf("%s [y/N] ", question)
+	answer, err := sh.in.ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
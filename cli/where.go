@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+type WhereCommand struct{}
+
+func (c *WhereCommand) GetName() string {
+	return "where"
+}
+
+func (c *WhereCommand) GetDescription() string {
+	return "Keep the piped records matching a condition"
+}
+
+// whereOperators are the operators of where conditions. > and < start a redirection unless
+// quoted, so gt and lt are also accepted.
+var whereOperators = []prompt.Suggest{
+	{Text: "==", Description: "Equal"},
+	{Text: "!=", Description: "Not equal"},
+	{Text: "~=", Description: "Contains, ignoring case"},
+	{Text: "gt", Description: "Greater than"},
+	{Text: "lt", Description: "Less than"},
+	{Text: ">=", Description: "Greater than or equal"},
+	{Text: "<=", Description: "Less than or equal"},
+}
+
+func (c *WhereCommand) Execute(sh *Shell, args []string) {
+	if len(args) < 2 || sh.input == nil {
+		sh.println("Usage: <command> | where <column> <operator> [value]")
+		sh.println("  Operators: == != ~= (contains) gt lt >= <=")
+		sh.println("  Numbers are compared as numbers, everything else as text.")
+		sh.println("  Example: ls | where mimetype ~= pdf")
+		sh.println("  Example: find report | where size gt 1000000 | count")
+		return
+	}
+
+	recs := sh.input
+	if len(recs.columns) == 0 {
+		return
+	}
+
+	column, err := recs.column(args[0])
+	if err != nil {
+		sh.eprintln("Error:", err)
+		return
+	}
+	operator, value := args[1], strings.Join(args[2:], " ")
+
+	var kept []any
+	for _, item := range recs.items {
+		match, err := matchCondition(column.Value(item), operator, value)
+		if err != nil {
+			sh.eprintln("Error:", err)
+			return
+		}
+		if match {
+			kept = append(kept, item)
+		}
+	}
+
+	if err := sh.renderRecords(recs.with(kept)); err != nil {
+		sh.eprintln("Error:", err)
+	}
+}
+
+// matchCondition reports whether a column value satisfies a where condition
+func matchCondition(actual, operator, value string) (bool, error) {
+	switch operator {
+	case "==", "=":
+		return compareValues(actual, value) == 0, nil
+	case "!=":
+		return compareValues(actual, value) != 0, nil
+	case "~=", "contains":
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(value)), nil
+	case ">", "gt":
+		return compareValues(actual, value) > 0, nil
+	case "<", "lt":
+		return compareValues(actual, value) < 0, nil
+	case ">=", "gte":
+		return compareValues(actual, value) >= 0, nil
+	case "<=", "lte":
+		return compareValues(actual, value) <= 0, nil
+	default:
+		return false, fmt.Errorf("unknown operator: %s (use == != ~= gt lt >= <=)", operator)
+	}
+}
+
+func (c *WhereCommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
+	args := argsBeforeCursor(d)
+	switch len(args) {
+	case 2:
+		return columnSuggestions(args[1])
+	case 3:
+		return prompt.FilterHasPrefix(whereOperators, args[2], false)
+	}
+	return []prompt.Suggest{}
+}
+
+func init() {
+	RegisterCommand(&WhereCommand{})
+}
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/c-bata/go-prompt"
@@ -17,29 +16,29 @@ func (c *WhoAmICommand) GetDescription() string {
 	return "Show the current authenticated user"
 }
 
-func (c *WhoAmICommand) Execute(args []string) {
+func (c *WhoAmICommand) Execute(sh *Shell, args []string) {
 	if len(args) > 0 {
-		fmt.Println("Usage: whoami")
+		sh.println("Usage: whoami")
 		return
 	}
 
-	user, err := client.GetCurrentUser()
+	user, err := sh.client.GetCurrentUser()
 	if err != nil {
-		fmt.Println("Error:", err)
+		sh.eprintln("Error:", err)
 		return
 	}
 
-	fmt.Println("Current user:")
-	fmt.Printf("  Email : %s\n", user.Email)
+	sh.println("Current user:")
+	sh.printf("  Email : %s\n", user.Email)
 	if user.Name != "" {
-		fmt.Printf("  Name  : %s\n", user.Name)
+		sh.printf("  Name  : %s\n", user.Name)
 	}
 	if len(user.Groups) > 0 {
-		fmt.Printf("  Groups: %s\n", strings.Join(user.Groups, ", "))
+		sh.printf("  Groups: %s\n", strings.Join(user.Groups, ", "))
 	}
 }
 
-func (c *WhoAmICommand) Suggest(d prompt.Document) []prompt.Suggest {
+func (c *WhoAmICommand) Suggest(sh *Shell, d prompt.Document) []prompt.Suggest {
 	return []prompt.Suggest{}
 }
 
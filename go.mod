@@ -5,8 +5,11 @@ go 1.25.0
 require (
 	github.com/c-bata/go-prompt v0.2.6
 	github.com/gabriel-vasile/mimetype v1.4.10
+	github.com/mattn/go-runewidth v0.0.13
 	github.com/spf13/cobra v1.10.1
 	go.xrstf.de/go-term-markdown v0.0.0-20231119170546-73a1852b91cc
+	golang.org/x/sys v0.29.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -16,10 +19,8 @@ require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	github.com/mattn/go-runewidth v0.0.13 // indirect
 	github.com/mattn/go-tty v0.0.3 // indirect
 	github.com/pkg/term v1.2.0-beta.2 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
 	github.com/spf13/pflag v1.0.10 // indirect
-	golang.org/x/sys v0.29.0 // indirect
 )